@@ -0,0 +1,405 @@
+package documentParser
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"strings"
+)
+
+const (
+	// defaultArchiveMaxDepth はアーカイブの入れ子の最大深さのデフォルト値
+	defaultArchiveMaxDepth = 3
+	// defaultArchiveMaxTotalSize は展開後の合計サイズ上限のデフォルト値（512MB）
+	defaultArchiveMaxTotalSize = 512 * 1024 * 1024
+	// defaultArchiveMaxEntries はアーカイブ内のエントリ数上限のデフォルト値
+	defaultArchiveMaxEntries = 10000
+)
+
+// ArchiveLimits はアーカイブ展開時の制限（zip bomb対策）
+// ゼロ値の項目にはデフォルト値が使われる
+type ArchiveLimits struct {
+	// MaxDepth はアーカイブの入れ子の最大深さ
+	MaxDepth int
+	// MaxTotalSize は展開後の合計サイズの上限（バイト）
+	MaxTotalSize int64
+	// MaxEntries はアーカイブ内のエントリ数の上限
+	MaxEntries int
+}
+
+func (l ArchiveLimits) maxDepth() int {
+	if l.MaxDepth > 0 {
+		return l.MaxDepth
+	}
+	return defaultArchiveMaxDepth
+}
+
+func (l ArchiveLimits) maxTotalSize() int64 {
+	if l.MaxTotalSize > 0 {
+		return l.MaxTotalSize
+	}
+	return defaultArchiveMaxTotalSize
+}
+
+func (l ArchiveLimits) maxEntries() int {
+	if l.MaxEntries > 0 {
+		return l.MaxEntries
+	}
+	return defaultArchiveMaxEntries
+}
+
+// archiveBudget は入れ子のアーカイブ全体で共有する展開サイズの残量
+type archiveBudget struct {
+	remaining int64
+}
+
+// archiveState はアーカイブパーサーの入れ子状態
+type archiveState struct {
+	depth  int
+	budget *archiveBudget
+}
+
+// archiveParser は入れ子のアーカイブを展開できるパーサー
+type archiveParser interface {
+	DocumentParser
+	// nested は入れ子の状態を引き継いだパーサーを返す
+	nested(state archiveState) DocumentParser
+}
+
+// archiveEntry はアーカイブ内の1つのファイル
+type archiveEntry struct {
+	name string
+	data []byte
+}
+
+// archiveDispatcher はアーカイブ内のエントリをファクトリー経由でパースする
+type archiveDispatcher struct {
+	factory *DocumentParserFactory
+	limits  ArchiveLimits
+	state   archiveState
+}
+
+func newArchiveDispatcher(factory *DocumentParserFactory, limits ArchiveLimits, state archiveState) *archiveDispatcher {
+	if factory == nil {
+		factory = NewDocumentParserFactory()
+	}
+	if state.budget == nil {
+		state.budget = &archiveBudget{remaining: limits.maxTotalSize()}
+	}
+	return &archiveDispatcher{factory: factory, limits: limits, state: state}
+}
+
+// read はエントリの内容を残りの予算内で読み込む
+func (d *archiveDispatcher) read(name string, r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, d.state.budget.remaining+1))
+	if err != nil {
+		return nil, fmt.Errorf("error reading entry %s: %w", name, err)
+	}
+	if int64(len(data)) > d.state.budget.remaining {
+		return nil, fmt.Errorf("archive exceeds maximum uncompressed size of %d bytes", d.limits.maxTotalSize())
+	}
+	d.state.budget.remaining -= int64(len(data))
+	return data, nil
+}
+
+// supports はエントリ名に対応するパーサーが存在するかを返す
+// 対応していないエントリはスキップすることをログに出力する
+func (d *archiveDispatcher) supports(name string) bool {
+	if _, err := d.factory.GetParser(d.factory.fileExtension(path.Base(name))); err != nil {
+		log.Printf("Skipping unsupported entry %s", name)
+		return false
+	}
+	return true
+}
+
+// parser はエントリ名に対応するパーサーを返す
+// 対応していないエントリ（supportsで確認済み）や入れ子が深すぎるアーカイブの場合はnilを返す
+func (d *archiveDispatcher) parser(name string) DocumentParser {
+	parser, err := d.factory.GetParser(d.factory.fileExtension(path.Base(name)))
+	if err != nil {
+		return nil
+	}
+
+	if ap, ok := parser.(archiveParser); ok {
+		state := archiveState{depth: d.state.depth, budget: d.state.budget}
+		// 圧縮された単一のファイル（.log.gz など）は展開サイズの残量のみを共有し、入れ子の深さには数えない
+		if containsArchive(parser) {
+			state.depth++
+			if state.depth >= d.limits.maxDepth() {
+				log.Printf("Skipping nested archive %s: maximum depth %d exceeded", name, d.limits.maxDepth())
+				return nil
+			}
+		}
+		return ap.nested(state)
+	}
+	return parser
+}
+
+// containsArchive はパーサーがアーカイブ（圧縮されたアーカイブを含む）をパースするかどうかを返す
+func containsArchive(parser DocumentParser) bool {
+	if dp, ok := parser.(*DecompressingParser); ok {
+		parser = dp.Inner
+	}
+	_, ok := parser.(archiveParser)
+	return ok
+}
+
+// parse はエントリをパースする
+// 対応していないエントリやパースに失敗したエントリの場合はfalseを返す
+func (d *archiveDispatcher) parse(name string, data []byte) (archiveEntry, bool) {
+	parser := d.parser(name)
+	if parser == nil {
+		return archiveEntry{}, false
+	}
+
+	content, err := parser.ParseFromReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		log.Printf("Error parsing entry %s: %s", name, err)
+		return archiveEntry{}, false
+	}
+	return archiveEntry{name: name, data: []byte(content)}, true
+}
+
+// joinArchiveEntries はパース結果をエントリごとの見出し付きで連結する
+func joinArchiveEntries(entries []archiveEntry) string {
+	var result strings.Builder
+	for _, entry := range entries {
+		result.WriteString(fmt.Sprintf("===== %s =====\n", entry.name))
+		result.Write(entry.data)
+		result.WriteString("\n\n")
+	}
+	return result.String()
+}
+
+// ZIPParser はzipアーカイブ内のドキュメントをパースするパーサー
+// 各エントリは拡張子に応じてファクトリーのパーサーに振り分けられる
+type ZIPParser struct {
+	BaseParser
+
+	// Factory はエントリのパースに使うファクトリー（nilの場合はデフォルトのファクトリー）
+	Factory *DocumentParserFactory
+
+	// Limits は展開時の制限
+	Limits ArchiveLimits
+
+	state archiveState
+}
+
+// SupportedExtensions はサポートする拡張子を返す
+func (p *ZIPParser) SupportedExtensions() []string {
+	return []string{".zip"}
+}
+
+// Capabilities はパーサーが抽出できる構造の種類を返す
+func (p *ZIPParser) Capabilities() ParserCapabilities {
+	return ParserCapabilities{Pages: true}
+}
+
+// ParseFromFile はファイルパスからzipアーカイブをパース
+func (p *ZIPParser) ParseFromFile(filePath string) (string, error) {
+	return parseFromFileCommon(p, filePath)
+}
+
+// ParseFromBytes はバイト配列からzipアーカイブをパース
+func (p *ZIPParser) ParseFromBytes(data []byte) (string, error) {
+	return parseFromBytesCommon(p, data)
+}
+
+// ParseFromReader はio.ReaderAtからzipアーカイブをパース
+func (p *ZIPParser) ParseFromReader(reader io.ReaderAt, size int64) (string, error) {
+	entries, err := p.parseEntries(reader, size)
+	if err != nil {
+		return "", err
+	}
+	return truncateOutput(joinArchiveEntries(entries), p.MaxOutputBytes), nil
+}
+
+// ParseWithPages はエントリのパスごとに内容を分けてマップ形式で返す
+func (p *ZIPParser) ParseWithPages(reader io.ReaderAt, size int64) (map[string]string, error) {
+	entries, err := p.parseEntries(reader, size)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]string)
+	for _, entry := range entries {
+		result[entry.name] = string(entry.data)
+	}
+	return result, nil
+}
+
+func (p *ZIPParser) nested(state archiveState) DocumentParser {
+	return &ZIPParser{BaseParser: p.BaseParser, Factory: p.Factory, Limits: p.Limits, state: state}
+}
+
+// parseEntries はzip内の全エントリを読み込んでパースする
+func (p *ZIPParser) parseEntries(reader io.ReaderAt, size int64) ([]archiveEntry, error) {
+	r, err := zip.NewReader(reader, size)
+	if err != nil {
+		return nil, fmt.Errorf("error reading zip archive: %w", err)
+	}
+
+	if len(r.File) > p.Limits.maxEntries() {
+		return nil, fmt.Errorf("zip archive has %d entries, exceeds maximum of %d", len(r.File), p.Limits.maxEntries())
+	}
+
+	dispatcher := newArchiveDispatcher(p.Factory, p.Limits, p.state)
+
+	var entries []archiveEntry
+	total := 0
+	for _, f := range r.File {
+		if p.outputLimitReached(total) {
+			break
+		}
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		// 対応していないエントリは展開せずにスキップ
+		if !dispatcher.supports(f.Name) {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			log.Printf("Error opening entry %s: %s", f.Name, err)
+			continue
+		}
+		data, err := dispatcher.read(f.Name, rc)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		if entry, ok := dispatcher.parse(f.Name, data); ok {
+			entries = append(entries, entry)
+			total += len(entry.data)
+		}
+	}
+
+	return entries, nil
+}
+
+// TarParser はtarアーカイブ（gzip圧縮を含む）内のドキュメントをパースするパーサー
+// 各メンバーは拡張子に応じてファクトリーのパーサーに振り分けられる
+type TarParser struct {
+	BaseParser
+
+	// Factory はメンバーのパースに使うファクトリー（nilの場合はデフォルトのファクトリー）
+	Factory *DocumentParserFactory
+
+	// Limits は展開時の制限
+	Limits ArchiveLimits
+
+	state archiveState
+}
+
+// SupportedExtensions はサポートする拡張子を返す
+func (p *TarParser) SupportedExtensions() []string {
+	return []string{".tar", ".tar.gz", ".tgz"}
+}
+
+// Capabilities はパーサーが抽出できる構造の種類を返す
+func (p *TarParser) Capabilities() ParserCapabilities {
+	return ParserCapabilities{Pages: true}
+}
+
+// ParseFromFile はファイルパスからtarアーカイブをパース
+func (p *TarParser) ParseFromFile(filePath string) (string, error) {
+	return parseFromFileCommon(p, filePath)
+}
+
+// ParseFromBytes はバイト配列からtarアーカイブをパース
+func (p *TarParser) ParseFromBytes(data []byte) (string, error) {
+	return parseFromBytesCommon(p, data)
+}
+
+// ParseFromReader はio.ReaderAtからtarアーカイブをパース
+func (p *TarParser) ParseFromReader(reader io.ReaderAt, size int64) (string, error) {
+	entries, err := p.parseEntries(reader, size)
+	if err != nil {
+		return "", err
+	}
+	return truncateOutput(joinArchiveEntries(entries), p.MaxOutputBytes), nil
+}
+
+// ParseWithPages はメンバーのパスごとに内容を分けてマップ形式で返す
+func (p *TarParser) ParseWithPages(reader io.ReaderAt, size int64) (map[string]string, error) {
+	entries, err := p.parseEntries(reader, size)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]string)
+	for _, entry := range entries {
+		result[entry.name] = string(entry.data)
+	}
+	return result, nil
+}
+
+func (p *TarParser) nested(state archiveState) DocumentParser {
+	return &TarParser{BaseParser: p.BaseParser, Factory: p.Factory, Limits: p.Limits, state: state}
+}
+
+// parseEntries はtar内の全メンバーを読み込んでパースする
+// gzip圧縮は拡張子ではなく先頭のマジックナンバーで判定する
+func (p *TarParser) parseEntries(reader io.ReaderAt, size int64) ([]archiveEntry, error) {
+	var r io.Reader = io.NewSectionReader(reader, 0, size)
+
+	magic := make([]byte, 2)
+	if n, _ := reader.ReadAt(magic, 0); n == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("error reading gzip stream: %w", err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	dispatcher := newArchiveDispatcher(p.Factory, p.Limits, p.state)
+	tr := tar.NewReader(r)
+
+	var entries []archiveEntry
+	count, total := 0, 0
+	for {
+		if p.outputLimitReached(total) {
+			break
+		}
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading tar archive: %w", err)
+		}
+
+		count++
+		if count > p.Limits.maxEntries() {
+			return nil, fmt.Errorf("tar archive exceeds maximum of %d entries", p.Limits.maxEntries())
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		if !dispatcher.supports(header.Name) {
+			continue
+		}
+
+		// 多くのパーサーはReaderAtを必要とするため、メンバーをバッファリングする
+		data, err := dispatcher.read(header.Name, tr)
+		if err != nil {
+			return nil, err
+		}
+
+		if entry, ok := dispatcher.parse(header.Name, data); ok {
+			entries = append(entries, entry)
+			total += len(entry.data)
+		}
+	}
+
+	return entries, nil
+}
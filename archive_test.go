@@ -0,0 +1,52 @@
+package documentParser
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"testing"
+)
+
+func TestArchiveNestedKeepsBaseParser(t *testing.T) {
+	base := BaseParser{MaxOutputBytes: 100, PageSeparator: "\f", MarkerStyle: MarkerSentinel}
+	state := archiveState{depth: 1, budget: &archiveBudget{remaining: 10}}
+
+	zipParser := (&ZIPParser{BaseParser: base}).nested(state).(*ZIPParser)
+	tarParser := (&TarParser{BaseParser: base}).nested(state).(*TarParser)
+	for _, got := range []BaseParser{zipParser.BaseParser, tarParser.BaseParser} {
+		if got.MaxOutputBytes != base.MaxOutputBytes || got.PageSeparator != base.PageSeparator || got.MarkerStyle != base.MarkerStyle {
+			t.Errorf("nested() BaseParser = %+v, want %+v", got, base)
+		}
+	}
+}
+
+func TestZIPParserCompressedEntriesShareBudget(t *testing.T) {
+	gzipped := func(data []byte) []byte {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		gz.Write(data)
+		gz.Close()
+		return buf.Bytes()
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range []string{"a.txt.gz", "b.txt.gz"} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("Create(%s) error = %v", name, err)
+		}
+		w.Write(gzipped(bytes.Repeat([]byte(name[:1]), 600)))
+	}
+	zw.Close()
+
+	// 圧縮されたエントリは小さいが、展開後の合計（1200バイト）はMaxTotalSizeを超える
+	parser := &ZIPParser{Limits: ArchiveLimits{MaxTotalSize: 1000}}
+	pages, err := parser.ParseWithPages(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("ParseWithPages() error = %v", err)
+	}
+	if _, ok := pages["a.txt.gz"]; !ok || len(pages) != 1 {
+		t.Errorf("ParseWithPages() = %v, want only a.txt.gz within the budget", pages)
+	}
+}
@@ -0,0 +1,214 @@
+package documentParser
+
+import (
+	"io"
+	"regexp"
+	"strings"
+)
+
+// AsciiDocParser はAsciiDocファイルのパーサー
+// 属性定義や装飾を除去し、見出しは "#"、[source] ブロックはコードブロックとしてMarkdown形式で出力する
+type AsciiDocParser struct {
+	TextParser
+
+	// Raw が true の場合、記法を除去せずに内容をそのまま返す
+	Raw bool
+}
+
+// SupportedExtensions はサポートする拡張子を返す
+func (p *AsciiDocParser) SupportedExtensions() []string {
+	return []string{".adoc", ".asciidoc"}
+}
+
+// ParseFromFile はファイルパスからAsciiDocをパース
+func (p *AsciiDocParser) ParseFromFile(filePath string) (string, error) {
+	return parseFromFileCommon(p, filePath)
+}
+
+// ParseFromBytes はバイト配列からAsciiDocをパース
+func (p *AsciiDocParser) ParseFromBytes(data []byte) (string, error) {
+	text, err := p.bytesText(data)
+	if err != nil {
+		return "", err
+	}
+	return truncateOutput(p.render(text), p.MaxOutputBytes), nil
+}
+
+// ParseFromReader はio.ReaderAtからAsciiDocをパース
+func (p *AsciiDocParser) ParseFromReader(reader io.ReaderAt, size int64) (string, error) {
+	text, err := p.readerText(reader, size)
+	if err != nil {
+		return "", err
+	}
+	return truncateOutput(p.render(text), p.MaxOutputBytes), nil
+}
+
+func (p *AsciiDocParser) render(text string) string {
+	if p.Raw {
+		return text
+	}
+	return stripAsciiDoc(text)
+}
+
+var (
+	adocHeadingPattern   = regexp.MustCompile(`^(={1,6})\s+(.*)$`)
+	adocAttributePattern = regexp.MustCompile(`^:(!?[\w-]+!?):\s*(.*)$`)
+	adocBlockAttrPattern = regexp.MustCompile(`^\[.*\]\s*$`)
+	adocSourcePattern    = regexp.MustCompile(`^\[(?:source|listing)?(?:,\s*([\w+#-]+))?.*\]\s*$`)
+	adocMacroPattern     = regexp.MustCompile(`^(image|include|video|audio|toc)::`)
+	adocLinkPattern      = regexp.MustCompile(`(?:link:([^\s\[]+)|((?:https?|ftp|mailto):[^\s\[]+))\[([^\]]*)\]`)
+	adocListPattern      = regexp.MustCompile(`^(\s*)\*+\s+`)
+	adocXrefPattern      = regexp.MustCompile(`<<[^,>]+,\s*([^>]+)>>`)
+	adocXrefBarePattern  = regexp.MustCompile(`<<([^,>]+)>>`)
+	adocStrongPattern    = regexp.MustCompile(`\*{1,2}([^*\s][^*]*?)\*{1,2}`)
+	adocEmphasisPattern  = regexp.MustCompile(`(^|[^\w])_{1,2}([^_\s][^_]*?)_{1,2}`)
+	adocMonoPattern      = regexp.MustCompile("`+([^`]+)`+")
+	adocAttrRefPattern   = regexp.MustCompile(`\{([\w-]+)\}`)
+)
+
+// adocDelimiter は区切りブロックの種類
+type adocDelimiter int
+
+const (
+	adocDelimiterNone adocDelimiter = iota
+	adocDelimiterCode
+	adocDelimiterComment
+	adocDelimiterContent
+)
+
+// stripAsciiDoc はAsciiDocの記法を除去してMarkdown形式のテキストに変換する
+func stripAsciiDoc(text string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	attributes := make(map[string]string)
+
+	var result []string
+	sourceLang := ""
+	delimiter := ""
+	delimiterKind := adocDelimiterNone
+
+	for _, line := range lines {
+		trimmed := strings.TrimRight(line, " \t")
+
+		// 区切りブロックの内部
+		if delimiter != "" {
+			if trimmed == delimiter {
+				if delimiterKind == adocDelimiterCode {
+					result = append(result, "```")
+				}
+				delimiter = ""
+				delimiterKind = adocDelimiterNone
+				continue
+			}
+			switch delimiterKind {
+			case adocDelimiterCode:
+				result = append(result, line)
+			case adocDelimiterContent:
+				result = append(result, stripAsciiDocInline(line, attributes))
+			}
+			continue
+		}
+
+		// 区切りブロックの開始
+		if kind, ok := asciiDocDelimiterKind(trimmed); ok {
+			delimiter = trimmed
+			delimiterKind = kind
+			if kind == adocDelimiterCode {
+				result = append(result, "```"+sourceLang)
+			}
+			sourceLang = ""
+			continue
+		}
+
+		// 行コメント
+		if strings.HasPrefix(trimmed, "//") {
+			continue
+		}
+
+		// 属性定義は後続の {name} 参照の置換に使い、出力しない
+		if m := adocAttributePattern.FindStringSubmatch(trimmed); m != nil {
+			attributes[m[1]] = m[2]
+			continue
+		}
+
+		// ブロック属性（[source,python]、[NOTE]、[[anchor]] など）
+		if adocBlockAttrPattern.MatchString(trimmed) {
+			if m := adocSourcePattern.FindStringSubmatch(trimmed); m != nil {
+				sourceLang = m[1]
+			}
+			continue
+		}
+
+		if adocMacroPattern.MatchString(trimmed) {
+			continue
+		}
+
+		// テーブルの区切り
+		if trimmed == "|===" {
+			continue
+		}
+
+		if m := adocHeadingPattern.FindStringSubmatch(trimmed); m != nil {
+			result = append(result, strings.Repeat("#", len(m[1]))+" "+stripAsciiDocInline(m[2], attributes))
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "|") {
+			cells := strings.Split(strings.TrimPrefix(trimmed, "|"), "|")
+			for i, cell := range cells {
+				cells[i] = strings.TrimSpace(cell)
+			}
+			result = append(result, stripAsciiDocInline(strings.Join(cells, " | "), attributes))
+			continue
+		}
+
+		// "+" のみの行は継続行の記号
+		if trimmed == "+" {
+			continue
+		}
+
+		line = adocListPattern.ReplaceAllString(line, "$1- ")
+		result = append(result, stripAsciiDocInline(line, attributes))
+	}
+
+	return strings.Join(result, "\n")
+}
+
+// asciiDocDelimiterKind は区切りブロックの開始行であればその種類を返す
+// リスティング（----）とリテラル（....）はコードブロックとして扱う
+func asciiDocDelimiterKind(line string) (adocDelimiter, bool) {
+	if len(line) < 4 || strings.Count(line, line[:1]) != len(line) {
+		return adocDelimiterNone, false
+	}
+	switch line[0] {
+	case '-', '.':
+		return adocDelimiterCode, true
+	case '/':
+		return adocDelimiterComment, true
+	case '=', '*', '_':
+		return adocDelimiterContent, true
+	}
+	return adocDelimiterNone, false
+}
+
+// stripAsciiDocInline は行内のリンク、相互参照、強調記法、属性参照を変換する
+func stripAsciiDocInline(line string, attributes map[string]string) string {
+	line = adocAttrRefPattern.ReplaceAllStringFunc(line, func(ref string) string {
+		if value, ok := attributes[ref[1:len(ref)-1]]; ok {
+			return value
+		}
+		return ref
+	})
+	line = adocXrefPattern.ReplaceAllString(line, "$1")
+	line = adocXrefBarePattern.ReplaceAllString(line, "$1")
+	line = adocLinkPattern.ReplaceAllStringFunc(line, func(link string) string {
+		m := adocLinkPattern.FindStringSubmatch(link)
+		if m[3] != "" {
+			return m[3]
+		}
+		return m[1] + m[2]
+	})
+	line = adocMonoPattern.ReplaceAllString(line, "$1")
+	line = adocStrongPattern.ReplaceAllString(line, "$1")
+	line = adocEmphasisPattern.ReplaceAllString(line, "$1$2")
+	return line
+}
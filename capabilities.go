@@ -0,0 +1,35 @@
+package documentParser
+
+// ParserCapabilities はパーサーが抽出できる構造の種類
+type ParserCapabilities struct {
+	// Tables は表を行・セルの構造を保って出力できることを示す
+	Tables bool `json:"tables"`
+	// Images は画像の情報を抽出できることを示す
+	Images bool `json:"images"`
+	// Pages はページ/スライド/シートごとに分割して取得できることを示す
+	Pages bool `json:"pages"`
+	// Metadata はタイトルや作成者などのメタデータを取得できることを示す
+	Metadata bool `json:"metadata"`
+	// Thumbnail は埋め込まれたサムネイル画像を取得できることを示す（ThumbnailParserを実装している）
+	Thumbnail bool `json:"thumbnail"`
+}
+
+// CapabilityReporter は抽出できる構造の種類を報告できるパーサーのインターフェース
+// 実装していないパーサーは全ての項目が false として扱われる
+type CapabilityReporter interface {
+	// Capabilities はパーサーが抽出できる構造の種類を返す
+	Capabilities() ParserCapabilities
+}
+
+// Capabilities は拡張子ごとのパーサーの機能を返す
+func (f *DocumentParserFactory) Capabilities() map[string]ParserCapabilities {
+	result := make(map[string]ParserCapabilities, len(f.parsers))
+	for ext, parser := range f.parsers {
+		if reporter, ok := parser.(CapabilityReporter); ok {
+			result[ext] = reporter.Capabilities()
+		} else {
+			result[ext] = ParserCapabilities{}
+		}
+	}
+	return result
+}
@@ -0,0 +1,271 @@
+package documentParser
+
+import (
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// defaultDecompressedMaxSize は展開後のサイズの上限のデフォルト値（512MB）
+const defaultDecompressedMaxSize = 512 * 1024 * 1024
+
+// DecompressingParser はgzip・bzip2・Zstandardで圧縮された単一のファイル（.log.gz、.csv.bz2 など）のパーサー
+// ファクトリーは圧縮の拡張子を除いた拡張子（.log、.csv など）のパーサーをInnerに設定して使う
+// 圧縮形式は拡張子ではなく先頭のマジックナンバーで判定する
+// テキストのパーサーには展開しながら渡し、io.ReaderAtが必要なパーサーには展開後の内容をメモリに読み込んでから渡す
+type DecompressingParser struct {
+	BaseParser
+
+	// Inner は展開後の内容をパースするパーサー（nilの場合はテキストとして扱う）
+	Inner DocumentParser
+
+	// MaxSize は展開後のサイズの上限（0以下の場合はデフォルトの512MB）
+	MaxSize int64
+
+	// budget はアーカイブ内のエントリとして展開する場合に、入れ子のアーカイブ全体で共有する展開サイズの残量
+	budget *archiveBudget
+}
+
+// streamParser はio.ReaderAtを使わずに、先頭から順に読み込んでパースできるパーサー
+type streamParser interface {
+	parseStream(r io.Reader) (string, error)
+}
+
+// decompressionSuffixes はDecompressingParserが対応する圧縮の拡張子
+var decompressionSuffixes = []string{".gz", ".bz2", ".zst"}
+
+// 圧縮形式ごとのマジックナンバー
+var (
+	gzipMagic  = []byte{0x1f, 0x8b}
+	bzip2Magic = []byte("BZh")
+	zstdMagic  = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// SupportedExtensions はサポートする拡張子を返す
+func (p *DecompressingParser) SupportedExtensions() []string {
+	return decompressionSuffixes
+}
+
+// ParseFromFile はファイルパスから圧縮されたファイルをパース
+func (p *DecompressingParser) ParseFromFile(filePath string) (string, error) {
+	return parseFromFileCommon(p, filePath)
+}
+
+// ParseFromBytes はバイト配列から圧縮されたファイルをパース
+func (p *DecompressingParser) ParseFromBytes(data []byte) (string, error) {
+	return parseFromBytesCommon(p, data)
+}
+
+// ParseFromReader はio.ReaderAtから圧縮されたファイルを展開し、Innerのパーサーでパースする
+func (p *DecompressingParser) ParseFromReader(reader io.ReaderAt, size int64) (string, error) {
+	r, err := decompressReader(reader, size)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+
+	limited := p.limitReader(r)
+	defer p.charge(limited)
+	if sp, ok := p.inner().(streamParser); ok {
+		text, err := sp.parseStream(limited)
+		if limited.exceeded {
+			return "", limited.sizeError()
+		}
+		if err != nil {
+			return "", err
+		}
+		return truncateOutput(text, p.MaxOutputBytes), nil
+	}
+
+	data, err := p.readAll(limited)
+	if err != nil {
+		return "", err
+	}
+	text, err := p.inner().ParseFromReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", err
+	}
+	return truncateOutput(text, p.MaxOutputBytes), nil
+}
+
+// ParseWithPages は展開後の内容をInnerのパーサーでページ/シートごとに分けて返す
+// InnerがPageSeparatedParserを実装していない場合は全体を一つの要素として返す
+func (p *DecompressingParser) ParseWithPages(reader io.ReaderAt, size int64) (map[string]string, error) {
+	inner, ok := p.inner().(PageSeparatedParser)
+	if !ok {
+		content, err := p.ParseFromReader(reader, size)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]string{"Content": content}, nil
+	}
+
+	r, err := decompressReader(reader, size)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	limited := p.limitReader(r)
+	defer p.charge(limited)
+	data, err := p.readAll(limited)
+	if err != nil {
+		return nil, err
+	}
+	return inner.ParseWithPages(bytes.NewReader(data), int64(len(data)))
+}
+
+// withInner はInnerを差し替えたコピーを返す
+func (p *DecompressingParser) withInner(inner DocumentParser) *DecompressingParser {
+	clone := *p
+	clone.Inner = inner
+	return &clone
+}
+
+func (p *DecompressingParser) inner() DocumentParser {
+	if p.Inner != nil {
+		return p.Inner
+	}
+	return &TextParser{}
+}
+
+func (p *DecompressingParser) maxSize() int64 {
+	if p.MaxSize > 0 {
+		return p.MaxSize
+	}
+	return defaultDecompressedMaxSize
+}
+
+// nested はアーカイブ内のエントリとして展開するため、アーカイブ全体の展開サイズの残量を共有するコピーを返す
+// 展開後の内容がアーカイブ（.zip.gz など）の場合は、Innerにも入れ子の状態を引き継ぐ
+func (p *DecompressingParser) nested(state archiveState) DocumentParser {
+	clone := *p
+	clone.budget = state.budget
+	if ap, ok := p.Inner.(archiveParser); ok {
+		clone.Inner = ap.nested(state)
+	}
+	return &clone
+}
+
+// limitReader は展開後のサイズをMaxSize（アーカイブ内のエントリの場合はアーカイブ全体の残量も）までに制限するReaderを返す
+func (p *DecompressingParser) limitReader(r io.Reader) *decompressLimitReader {
+	limit := p.maxSize()
+	if p.budget != nil && p.budget.remaining < limit {
+		limit = p.budget.remaining
+	}
+	return &decompressLimitReader{r: r, remaining: limit, limit: limit}
+}
+
+// charge は展開した分をアーカイブ全体の展開サイズの残量から差し引く
+func (p *DecompressingParser) charge(r *decompressLimitReader) {
+	if p.budget != nil {
+		p.budget.remaining -= r.limit - max(r.remaining, 0)
+	}
+}
+
+// readAll は展開後の内容を全て読み込む
+func (p *DecompressingParser) readAll(r *decompressLimitReader) ([]byte, error) {
+	data, err := io.ReadAll(r)
+	if r.exceeded {
+		return nil, r.sizeError()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing data: %w", err)
+	}
+	return data, nil
+}
+
+// decompressReader は先頭のマジックナンバーから圧縮形式を判定し、展開しながら読み込むReaderを返す
+func decompressReader(reader io.ReaderAt, size int64) (io.ReadCloser, error) {
+	header := make([]byte, len(zstdMagic))
+	n, _ := reader.ReadAt(header, 0)
+	header = header[:n]
+	r := io.NewSectionReader(reader, 0, size)
+
+	switch {
+	case bytes.HasPrefix(header, gzipMagic):
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("error reading gzip stream: %w", err)
+		}
+		return gz, nil
+	case bytes.HasPrefix(header, bzip2Magic):
+		return io.NopCloser(bzip2.NewReader(r)), nil
+	case bytes.HasPrefix(header, zstdMagic):
+		zr, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, fmt.Errorf("error reading zstd stream: %w", err)
+		}
+		return zr.IOReadCloser(), nil
+	}
+	return nil, fmt.Errorf("unknown compression format")
+}
+
+// decompressLimitReader は展開後のサイズが上限を超えた場合にエラーを返すReader
+// io.LimitReaderと異なり、上限ちょうどで切れたのか超えたのかを区別する
+type decompressLimitReader struct {
+	r         io.Reader
+	remaining int64
+	exceeded  bool
+	// limit は展開後のサイズの上限
+	limit int64
+}
+
+func (l *decompressLimitReader) sizeError() error {
+	return fmt.Errorf("decompressed data exceeds maximum size of %d bytes", l.limit)
+}
+
+func (l *decompressLimitReader) Read(b []byte) (int, error) {
+	if l.remaining < 0 {
+		l.exceeded = true
+		return 0, fmt.Errorf("decompressed data exceeds maximum size")
+	}
+	if int64(len(b)) > l.remaining+1 {
+		b = b[:l.remaining+1]
+	}
+	n, err := l.r.Read(b)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		l.exceeded = true
+		return n, fmt.Errorf("decompressed data exceeds maximum size")
+	}
+	return n, err
+}
+
+// decompressingParser は "<拡張子><圧縮の拡張子>"（".log.gz" など）に対応するパーサーを返す
+// 圧縮を除いた拡張子に対応するパーサーがない場合（"access.log.1.gz" の ".1" など）はテキストとして扱う
+func (f *DocumentParserFactory) decompressingParser(ext string) (DocumentParser, bool) {
+	i := strings.LastIndex(ext, ".")
+	if i <= 0 {
+		return nil, false
+	}
+	wrapper, ok := f.parsers[ext[i:]].(*DecompressingParser)
+	if !ok {
+		return nil, false
+	}
+	inner, ok := f.parsers[ext[:i]]
+	if !ok {
+		inner = f.parsers[".txt"]
+	}
+	return wrapper.withInner(inner), true
+}
+
+// compressedExtension はfilePathが圧縮の拡張子で終わる場合、その前の拡張子と合わせた拡張子（".log.gz" など）を返す
+func compressedExtension(filePath string) (string, bool) {
+	lower := strings.ToLower(filePath)
+	for _, suffix := range decompressionSuffixes {
+		if !strings.HasSuffix(lower, suffix) {
+			continue
+		}
+		base := filePath[:len(filePath)-len(suffix)]
+		if i := strings.LastIndexAny(base, "./"); i >= 0 && base[i] == '.' {
+			return filePath[i:], true
+		}
+	}
+	return "", false
+}
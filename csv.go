@@ -0,0 +1,271 @@
+package documentParser
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+// CSVParser はCSV/TSVファイルのパーサー
+// 各行のセルを " | " で結合して出力する（Excelのシートと同じ形式）
+type CSVParser struct {
+	BaseParser
+
+	// Encoding は入力の文字コード（"shift_jis"、"euc-jp"、"utf-8" など）
+	// 空の場合は自動判定し、UTF-8として不正なバイト列を含む場合はShift_JIS（CP932）として扱う
+	Encoding string
+
+	// Delimiter は区切り文字（0の場合は先頭の行から , ; タブ | のいずれかを自動判定する）
+	Delimiter rune
+
+	// RecordMode が true の場合、1行目を見出しとして各行を "列名: 値, 列名: 値" の形式で出力する
+	// 見出しより列が多い行の余分な列は "column N" を列名とし、列が少ない行は存在する列のみを出力する
+	RecordMode bool
+}
+
+// SupportedExtensions はサポートする拡張子を返す
+func (p *CSVParser) SupportedExtensions() []string {
+	return []string{".csv", ".tsv"}
+}
+
+// Capabilities はパーサーが抽出できる構造の種類を返す
+func (p *CSVParser) Capabilities() ParserCapabilities {
+	return ParserCapabilities{Tables: true}
+}
+
+// setEncoding はファクトリーのWithEncodingをEncodingに反映する
+func (p *CSVParser) setEncoding(encoding string) {
+	p.Encoding = encoding
+}
+
+// ParseFromFile はファイルパスからCSVをパース
+func (p *CSVParser) ParseFromFile(filePath string) (string, error) {
+	return parseFromFileCommon(p, filePath)
+}
+
+// ParseFromBytes はバイト配列からCSVをパース
+func (p *CSVParser) ParseFromBytes(data []byte) (string, error) {
+	return parseFromBytesCommon(p, data)
+}
+
+// ParseFromReader はio.ReaderAtからCSVをパース
+func (p *CSVParser) ParseFromReader(reader io.ReaderAt, size int64) (string, error) {
+	if size > textMaxSize {
+		return "", fmt.Errorf("file size %d exceeds maximum allowed size of %d bytes", size, textMaxSize)
+	}
+
+	data, err := io.ReadAll(io.NewSectionReader(reader, 0, size))
+	if err != nil {
+		return "", fmt.Errorf("error reading CSV file: %w", err)
+	}
+
+	// csv.ReaderはUTF-8を前提とするため、先に文字コードを変換する
+	data, err = decodeText(data, p.Encoding)
+	if err != nil {
+		return "", err
+	}
+
+	csvReader := csv.NewReader(bytes.NewReader(data))
+	csvReader.Comma = p.delimiter(data)
+	csvReader.FieldsPerRecord = -1
+	csvReader.LazyQuotes = true
+
+	var sb strings.Builder
+	var header []string
+	for {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("error parsing CSV: %w", err)
+		}
+
+		if p.RecordMode {
+			if header == nil {
+				header = record
+				continue
+			}
+			if isEmptyRow(record) {
+				continue
+			}
+			sb.WriteString(formatCSVRecord(header, record))
+		} else {
+			sb.WriteString(strings.Join(record, p.cellSeparator(" | ")))
+		}
+		sb.WriteString("\n")
+		if p.outputLimitReached(sb.Len()) {
+			break
+		}
+	}
+
+	return truncateOutput(sb.String(), p.MaxOutputBytes), nil
+}
+
+// formatCSVRecord は行を見出しの列名と組み合わせて "列名: 値" のカンマ区切りに変換する
+func formatCSVRecord(header, record []string) string {
+	fields := make([]string, 0, len(record))
+	for i, value := range record {
+		name := ""
+		if i < len(header) {
+			name = strings.TrimSpace(header[i])
+		}
+		if name == "" {
+			name = fmt.Sprintf("column %d", i+1)
+		}
+		fields = append(fields, fmt.Sprintf("%s: %s", name, value))
+	}
+	return strings.Join(fields, ", ")
+}
+
+// csvDelimiterCandidates は自動判定の対象とする区切り文字（同点の場合は先のものを優先する）
+var csvDelimiterCandidates = []rune{',', ';', '\t', '|'}
+
+// csvSniffLines は区切り文字の自動判定に使う先頭の行数
+const csvSniffLines = 10
+
+// delimiter は区切り文字を返す
+// Delimiterが設定されていない場合は、先頭の行で候補の文字（, ; タブ |）の出現数を数え、
+// 出現数が同じ行が最も多い（行ごとのばらつきが少ない）ものを選ぶ
+func (p *CSVParser) delimiter(data []byte) rune {
+	if p.Delimiter != 0 {
+		return p.Delimiter
+	}
+
+	lines := csvSniffSample(data)
+	if len(lines) == 0 {
+		return ','
+	}
+
+	type score struct {
+		delimiter   rune
+		consistency int
+		count       int
+	}
+	var scores []score
+	for _, candidate := range csvDelimiterCandidates {
+		frequency := make(map[int]int)
+		for _, line := range lines {
+			frequency[strings.Count(line, string(candidate))]++
+		}
+
+		// 最も多くの行で一致する出現数（0回は除く）
+		best := score{delimiter: candidate}
+		for count, lines := range frequency {
+			if count > 0 && (lines > best.consistency || lines == best.consistency && count > best.count) {
+				best.consistency, best.count = lines, count
+			}
+		}
+		if best.consistency > 0 {
+			scores = append(scores, best)
+		}
+	}
+	if len(scores) == 0 {
+		return ','
+	}
+
+	sort.SliceStable(scores, func(i, j int) bool {
+		if scores[i].consistency != scores[j].consistency {
+			return scores[i].consistency > scores[j].consistency
+		}
+		return scores[i].count > scores[j].count
+	})
+	if len(scores) > 1 && scores[0].consistency == scores[1].consistency && scores[0].count == scores[1].count {
+		log.Printf("ambiguous CSV delimiter: %q and %q are equally likely, using %q",
+			scores[0].delimiter, scores[1].delimiter, scores[0].delimiter)
+	}
+	return scores[0].delimiter
+}
+
+// csvSniffSample は区切り文字の判定に使う先頭の空でない行を、引用符で囲まれた部分を除いて返す
+func csvSniffSample(data []byte) []string {
+	var lines []string
+	var line strings.Builder
+	inQuote := false
+	for _, r := range string(data) {
+		switch {
+		case r == '"':
+			inQuote = !inQuote
+		case inQuote:
+		case r == '\n':
+			if text := strings.TrimRight(line.String(), "\r"); strings.TrimSpace(text) != "" {
+				lines = append(lines, text)
+				if len(lines) == csvSniffLines {
+					return lines
+				}
+			}
+			line.Reset()
+		default:
+			line.WriteRune(r)
+		}
+	}
+	if strings.TrimSpace(line.String()) != "" {
+		lines = append(lines, line.String())
+	}
+	return lines
+}
+
+// utf8BOM はExcelなどが先頭に付けるUTF-8のバイトオーダーマーク
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// encodingAliases はhtmlindex（WHATWGのラベル）にない文字コード名の別名
+// htmlindexのShift_JISはNEC特殊文字・IBM拡張文字を含むCP932（Windows-31J）の対応表で変換するため、CP932の名前はShift_JISに対応させる
+var encodingAliases = map[string]string{
+	"cp932":       "windows-31j",
+	"windows-932": "windows-31j",
+}
+
+// lookupEncoding は文字コード名（WHATWGのラベルまたはencodingAliasesの別名）から文字コードを返す
+func lookupEncoding(encodingName string) (encoding.Encoding, error) {
+	name := strings.ToLower(strings.TrimSpace(encodingName))
+	if alias, ok := encodingAliases[name]; ok {
+		name = alias
+	}
+	enc, err := htmlindex.Get(name)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported encoding %s: %w", encodingName, err)
+	}
+	return enc, nil
+}
+
+// asciiCompatible は文字コードで改行が1バイトの '\n' になるかどうか（改行のバイトで行を数えられるか）を返す
+// UTF-16などは改行が2バイトになるためfalseになる
+func asciiCompatible(enc encoding.Encoding) bool {
+	encoded, err := enc.NewEncoder().Bytes([]byte("\n"))
+	return err == nil && bytes.Equal(encoded, []byte("\n"))
+}
+
+// decodeText は指定した文字コードのバイト列をUTF-8に変換する
+// encodingNameが空の場合は、UTF-8として正しければそのまま、そうでなければShift_JISとして変換する
+// 先頭のUTF-8 BOMは除去する
+func decodeText(data []byte, encodingName string) ([]byte, error) {
+	if bytes.HasPrefix(data, utf8BOM) {
+		return data[len(utf8BOM):], nil
+	}
+
+	if encodingName == "" {
+		if utf8.Valid(data) {
+			return data, nil
+		}
+		encodingName = "shift_jis"
+	}
+
+	enc, err := lookupEncoding(encodingName)
+	if err != nil {
+		return nil, err
+	}
+
+	decoded, err := enc.NewDecoder().Bytes(data)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding %s: %w", encodingName, err)
+	}
+	return decoded, nil
+}
@@ -0,0 +1,254 @@
+package documentParser
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+)
+
+// DBFParser はdBASEのテーブル（dBASE III / IV、FoxPro、Visual FoxPro の .dbf）のパーサー
+// 先頭にフィールド名の行、続いてレコードごとの行を " | "（CellSeparator）区切りで出力する
+// 削除済みのレコード（先頭が "*"）は出力しない。メモ（M / G）は別ファイル（.dbt / .fpt）に保存されるため空になる
+type DBFParser struct {
+	BaseParser
+}
+
+// dbfHeaderSize はファイルの先頭のヘッダーとフィールド記述子1つのサイズ
+const dbfHeaderSize = 32
+
+// dbfField はフィールド記述子
+type dbfField struct {
+	name     string
+	kind     byte
+	offset   int
+	length   int
+	decimals int
+}
+
+// SupportedExtensions はサポートする拡張子を返す
+func (p *DBFParser) SupportedExtensions() []string {
+	return []string{".dbf"}
+}
+
+// ParseFromFile はファイルパスからdBASEのテーブルをパース
+func (p *DBFParser) ParseFromFile(filePath string) (string, error) {
+	return parseFromFileCommon(p, filePath)
+}
+
+// ParseFromBytes はバイト配列からdBASEのテーブルをパース
+func (p *DBFParser) ParseFromBytes(data []byte) (string, error) {
+	return parseFromBytesCommon(p, data)
+}
+
+// ParseFromReader はio.ReaderAtからdBASEのテーブルをパースする
+// 文字列の文字コードはヘッダーの言語ドライバーIDから判定し、不明な場合はUTF-8として読めればUTF-8、読めなければWindows-1252とする
+func (p *DBFParser) ParseFromReader(reader io.ReaderAt, size int64) (string, error) {
+	header := make([]byte, dbfHeaderSize)
+	if _, err := reader.ReadAt(header, 0); err != nil {
+		return "", fmt.Errorf("error reading DBF header: %w", err)
+	}
+	recordCount := int64(binary.LittleEndian.Uint32(header[4:8]))
+	headerLength := int64(binary.LittleEndian.Uint16(header[8:10]))
+	recordLength := int64(binary.LittleEndian.Uint16(header[10:12]))
+	if headerLength < dbfHeaderSize+1 || headerLength > size || recordLength < 1 {
+		return "", fmt.Errorf("invalid DBF header")
+	}
+
+	descriptors := make([]byte, headerLength-dbfHeaderSize)
+	if _, err := reader.ReadAt(descriptors, dbfHeaderSize); err != nil && err != io.EOF {
+		return "", fmt.Errorf("error reading DBF field descriptors: %w", err)
+	}
+	fields, err := parseDBFFields(descriptors, int(recordLength))
+	if err != nil {
+		return "", err
+	}
+	dec := dbfDecoder(header[29])
+
+	separator := p.cellSeparator(" | ")
+	var sb strings.Builder
+	names := make([]string, len(fields))
+	for i, field := range fields {
+		names[i] = field.name
+	}
+	sb.WriteString(strings.Join(names, separator) + "\n")
+
+	// レコード数はヘッダーの値とファイルサイズの小さい方とする（書き込み途中のファイルなど）
+	recordCount = min(recordCount, (size-headerLength)/recordLength)
+	record := make([]byte, recordLength)
+	values := make([]string, len(fields))
+	for i := int64(0); i < recordCount; i++ {
+		if p.outputLimitReached(sb.Len()) {
+			break
+		}
+		if _, err := reader.ReadAt(record, headerLength+i*recordLength); err != nil {
+			return "", fmt.Errorf("error reading DBF record %d: %w", i+1, err)
+		}
+		switch record[0] {
+		case '*':
+			continue
+		case 0x1a:
+			// ファイルの終端
+			i = recordCount
+			continue
+		}
+		for j, field := range fields {
+			values[j] = field.value(record[field.offset:field.offset+field.length], dec)
+		}
+		sb.WriteString(strings.Join(values, separator) + "\n")
+	}
+	return truncateOutput(sb.String(), p.MaxOutputBytes), nil
+}
+
+// parseDBFFields はフィールド記述子（終端は0x0D）を読み込む
+// Visual FoxProのNULLの管理用のフィールド（_NullFlags）は除く
+func parseDBFFields(descriptors []byte, recordLength int) ([]dbfField, error) {
+	var fields []dbfField
+	offset := 1 // 先頭の1バイトは削除フラグ
+	for i := 0; i+dbfHeaderSize <= len(descriptors) && descriptors[i] != 0x0d; i += dbfHeaderSize {
+		d := descriptors[i : i+dbfHeaderSize]
+		name, _, _ := bytes.Cut(d[:11], []byte{0})
+		field := dbfField{
+			name:     strings.TrimSpace(string(name)),
+			kind:     d[11],
+			offset:   offset,
+			length:   int(d[16]),
+			decimals: int(d[17]),
+		}
+		// 文字型のフィールドは小数点以下の桁数のバイトを長さの上位バイトとして使い、255バイトを超える長さを表す
+		if field.kind == 'C' {
+			field.length += field.decimals << 8
+		}
+		if field.length == 0 {
+			return nil, fmt.Errorf("DBF field %s has zero length", field.name)
+		}
+		offset += field.length
+		if offset > recordLength {
+			return nil, fmt.Errorf("DBF field %s exceeds record length", field.name)
+		}
+		if field.kind == '0' {
+			continue
+		}
+		fields = append(fields, field)
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("no fields found in DBF header")
+	}
+	return fields, nil
+}
+
+// value はフィールドの値を文字列にする
+func (f dbfField) value(data []byte, dec *encoding.Decoder) string {
+	switch f.kind {
+	case 'C':
+		return decodeDBFString(bytes.TrimRight(data, " \x00"), dec)
+	case 'N', 'F':
+		return strings.TrimSpace(string(data))
+	case 'D':
+		if t, err := time.Parse("20060102", strings.TrimSpace(string(data))); err == nil {
+			return t.Format("2006-01-02")
+		}
+		return strings.TrimSpace(string(data))
+	case 'L':
+		switch data[0] {
+		case 'T', 't', 'Y', 'y':
+			return "true"
+		case 'F', 'f', 'N', 'n':
+			return "false"
+		}
+		return ""
+	case 'I':
+		if len(data) == 4 {
+			return strconv.FormatInt(int64(int32(binary.LittleEndian.Uint32(data))), 10)
+		}
+	case 'Y':
+		if len(data) == 8 {
+			// 通貨型は10000倍した整数
+			return strconv.FormatFloat(float64(int64(binary.LittleEndian.Uint64(data)))/10000, 'f', 4, 64)
+		}
+	case 'B':
+		// Visual FoxProの倍精度浮動小数点数（dBASE IVではメモのブロック番号）
+		if len(data) == 8 {
+			return strconv.FormatFloat(math.Float64frombits(binary.LittleEndian.Uint64(data)), 'f', -1, 64)
+		}
+		return ""
+	case 'T':
+		// Visual FoxProの日時型（ユリウス日とその日の経過ミリ秒）
+		if len(data) == 8 {
+			days := int64(binary.LittleEndian.Uint32(data[:4]))
+			ms := int64(binary.LittleEndian.Uint32(data[4:]))
+			if days == 0 {
+				return ""
+			}
+			// ユリウス日2440588が1970-01-01
+			t := time.Unix((days-2440588)*86400, 0).UTC().Add(time.Duration(ms) * time.Millisecond)
+			return t.Format("2006-01-02 15:04:05")
+		}
+	case 'M', 'G', 'P':
+		return ""
+	}
+	return decodeDBFString(bytes.TrimSpace(data), dec)
+}
+
+// decodeDBFString は文字列をUTF-8に変換する（decがnilの場合はUTF-8として読めればそのまま、読めなければWindows-1252とする）
+func decodeDBFString(data []byte, dec *encoding.Decoder) string {
+	if dec == nil {
+		if utf8.Valid(data) {
+			return string(data)
+		}
+		dec = charmap.Windows1252.NewDecoder()
+	}
+	text, err := dec.Bytes(data)
+	if err != nil {
+		return string(data)
+	}
+	return string(text)
+}
+
+// dbfDecoder はヘッダーの言語ドライバーID（コードページ）に対応するデコーダーを返す（不明な場合はnil）
+func dbfDecoder(languageDriver byte) *encoding.Decoder {
+	var enc encoding.Encoding
+	switch languageDriver {
+	case 0x01:
+		enc = charmap.CodePage437
+	case 0x02:
+		enc = charmap.CodePage850
+	case 0x03, 0x57, 0x58, 0x59:
+		enc = charmap.Windows1252
+	case 0x13, 0x7b:
+		enc = japanese.ShiftJIS
+	case 0x4d, 0x7a:
+		enc = simplifiedchinese.GBK
+	case 0x4e, 0x79:
+		enc = korean.EUCKR
+	case 0x4f, 0x78:
+		enc = traditionalchinese.Big5
+	case 0x64:
+		enc = charmap.CodePage852
+	case 0x65:
+		enc = charmap.CodePage866
+	case 0xc8:
+		enc = charmap.Windows1250
+	case 0xc9:
+		enc = charmap.Windows1251
+	case 0xca:
+		enc = charmap.Windows1254
+	case 0xcb:
+		enc = charmap.Windows1253
+	default:
+		return nil
+	}
+	return enc.NewDecoder()
+}
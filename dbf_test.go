@@ -0,0 +1,152 @@
+package documentParser
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"strings"
+	"testing"
+)
+
+// testDBFField はテスト用のDBFを作るためのフィールド定義
+type testDBFField struct {
+	name     string
+	kind     byte
+	length   int
+	decimals int
+}
+
+// buildTestDBF はフィールド定義とレコード（先頭の削除フラグを含む）からDBFのバイト列を作る
+// backlinkが正の場合はVisual FoxProと同様に、フィールド記述子の終端の後に指定したバイト数の領域を置く
+func buildTestDBF(version byte, languageDriver byte, fields []testDBFField, records [][]byte, backlink int) []byte {
+	recordLength := 1
+	for _, f := range fields {
+		recordLength += f.length
+	}
+	headerLength := dbfHeaderSize + len(fields)*dbfHeaderSize + 1 + backlink
+
+	var buf bytes.Buffer
+	header := make([]byte, dbfHeaderSize)
+	header[0] = version
+	header[1], header[2], header[3] = 124, 1, 1
+	binary.LittleEndian.PutUint32(header[4:8], uint32(len(records)))
+	binary.LittleEndian.PutUint16(header[8:10], uint16(headerLength))
+	binary.LittleEndian.PutUint16(header[10:12], uint16(recordLength))
+	header[29] = languageDriver
+	buf.Write(header)
+
+	for _, f := range fields {
+		d := make([]byte, dbfHeaderSize)
+		copy(d[:11], f.name)
+		d[11] = f.kind
+		d[16] = byte(f.length)
+		d[17] = byte(f.decimals)
+		buf.Write(d)
+	}
+	buf.WriteByte(0x0d)
+	buf.Write(make([]byte, backlink))
+
+	for _, r := range records {
+		buf.Write(r)
+	}
+	buf.WriteByte(0x1a)
+	return buf.Bytes()
+}
+
+// dbfRecord は削除フラグと各フィールドのバイト列を連結してレコードにする
+func dbfRecord(deleted bool, values ...[]byte) []byte {
+	flag := byte(' ')
+	if deleted {
+		flag = '*'
+	}
+	return append([]byte{flag}, bytes.Join(values, nil)...)
+}
+
+// padDBF は文字列を指定した長さまで空白で埋める
+func padDBF(s string, n int) []byte {
+	return []byte(s + strings.Repeat(" ", n-len(s)))
+}
+
+func TestDBFParserDBaseIII(t *testing.T) {
+	fields := []testDBFField{
+		{name: "NAME", kind: 'C', length: 10},
+		{name: "QTY", kind: 'N', length: 5},
+		{name: "ACTIVE", kind: 'L', length: 1},
+		{name: "UPDATED", kind: 'D', length: 8},
+	}
+	records := [][]byte{
+		dbfRecord(false, padDBF("apple", 10), []byte("   12"), []byte("T"), []byte("20240131")),
+		dbfRecord(true, padDBF("deleted", 10), []byte("    1"), []byte("F"), []byte("20240101")),
+		dbfRecord(false, padDBF("banana", 10), []byte("    3"), []byte("N"), []byte("        ")),
+	}
+	data := buildTestDBF(0x03, 0x03, fields, records, 0)
+
+	parser := &DBFParser{}
+	got, err := parser.ParseFromBytes(data)
+	if err != nil {
+		t.Fatalf("ParseFromBytes() error = %v", err)
+	}
+
+	want := "NAME | QTY | ACTIVE | UPDATED\n" +
+		"apple | 12 | true | 2024-01-31\n" +
+		"banana | 3 | false | \n"
+	if got != want {
+		t.Errorf("ParseFromBytes() = %q, want %q", got, want)
+	}
+}
+
+func TestDBFParserVisualFoxPro(t *testing.T) {
+	fields := []testDBFField{
+		{name: "ID", kind: 'I', length: 4},
+		{name: "PRICE", kind: 'Y', length: 8},
+		{name: "RATE", kind: 'B', length: 8},
+		{name: "STAMP", kind: 'T', length: 8},
+		{name: "_NullFlags", kind: '0', length: 1},
+	}
+
+	id := make([]byte, 4)
+	binary.LittleEndian.PutUint32(id, 42)
+	price := make([]byte, 8)
+	binary.LittleEndian.PutUint64(price, 123450)
+	rate := make([]byte, 8)
+	binary.LittleEndian.PutUint64(rate, math.Float64bits(0.5))
+	stamp := make([]byte, 8)
+	// 2024-01-31 12:00:00（ユリウス日2460341）
+	binary.LittleEndian.PutUint32(stamp[:4], 2460341)
+	binary.LittleEndian.PutUint32(stamp[4:], 12*60*60*1000)
+
+	deletedID := make([]byte, 4)
+	binary.LittleEndian.PutUint32(deletedID, 7)
+
+	records := [][]byte{
+		dbfRecord(false, id, price, rate, stamp, []byte{0}),
+		dbfRecord(true, deletedID, price, rate, stamp, []byte{0}),
+	}
+	data := buildTestDBF(0x30, 0x03, fields, records, 263)
+
+	parser := &DBFParser{}
+	got, err := parser.ParseFromBytes(data)
+	if err != nil {
+		t.Fatalf("ParseFromBytes() error = %v", err)
+	}
+
+	want := "ID | PRICE | RATE | STAMP\n" +
+		"42 | 12.3450 | 0.5 | 2024-01-31 12:00:00\n"
+	if got != want {
+		t.Errorf("ParseFromBytes() = %q, want %q", got, want)
+	}
+}
+
+func TestDBFParserZeroLengthField(t *testing.T) {
+	fields := []testDBFField{
+		{name: "NAME", kind: 'C', length: 4},
+		{name: "FLAG", kind: 'L', length: 0},
+	}
+	records := [][]byte{dbfRecord(false, padDBF("test", 4))}
+	data := buildTestDBF(0x03, 0x03, fields, records, 0)
+
+	parser := &DBFParser{}
+	if _, err := parser.ParseFromBytes(data); err == nil {
+		t.Error("ParseFromBytes() error = nil, want error for zero-length field")
+	}
+}
@@ -0,0 +1,132 @@
+package documentParser
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// ParseDir はディレクトリ内の対応する拡張子のファイルを全てパースし、パスごとのテキストとエラーを返す
+// recursive が true の場合はサブディレクトリもたどる。対応していない拡張子のファイルは結果に含めない
+// recursive が true の場合はディレクトリへのシンボリックリンクもたどるが、たどったディレクトリの中を指すリンクはたどらないため、ループしない
+// パースはGOMAXPROCS個まで並列に行う（SetMaxConcurrencyの制限も適用される）
+// 戻り値のマップのキーはdirを先頭に付けたパス（シンボリックリンクをたどった場合はリンクのパス）
+func (f *DocumentParserFactory) ParseDir(dir string, recursive bool) (map[string]string, map[string]error) {
+	results := make(map[string]string)
+	errs := make(map[string]error)
+
+	var paths []string
+	w := &dirWalker{factory: f, recursive: recursive, errs: errs}
+	w.walk(dir, dir, &paths)
+
+	var mu sync.Mutex
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+	for range min(runtime.GOMAXPROCS(0), max(len(paths), 1)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range jobs {
+				content, err := f.ParseFromFile(path)
+				mu.Lock()
+				if err != nil {
+					errs[path] = err
+				} else {
+					results[path] = content
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, path := range paths {
+		jobs <- path
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results, errs
+}
+
+// dirWalker はParseDirでパースするファイルを集める
+type dirWalker struct {
+	factory   *DocumentParserFactory
+	recursive bool
+	errs      map[string]error
+	// roots はたどったディレクトリの実際のパス（シンボリックリンクのループの検出に使う）
+	roots []string
+}
+
+// walk はroot（シンボリックリンクの場合はリンク先）をたどり、対応する拡張子のファイルのパスをpathsに追加する
+// displayはキーに使うパス（シンボリックリンクをたどった場合はリンクのパス）
+func (w *dirWalker) walk(root, display string, paths *[]string) {
+	real, err := filepath.EvalSymlinks(root)
+	if err == nil {
+		real, err = filepath.Abs(real)
+	}
+	if err != nil {
+		w.errs[display] = err
+		return
+	}
+	if w.walked(real) {
+		return
+	}
+	w.roots = append(w.roots, real)
+
+	err = filepath.WalkDir(real, func(path string, d fs.DirEntry, err error) error {
+		name := display
+		if rel, relErr := filepath.Rel(real, path); relErr == nil && rel != "." {
+			name = filepath.Join(display, rel)
+		}
+		if err != nil {
+			w.errs[name] = err
+			if d != nil && d.IsDir() && path != real {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if d.IsDir() {
+			if path != real && !w.recursive {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if d.Type()&fs.ModeSymlink != 0 {
+			info, err := os.Stat(path)
+			if err != nil {
+				w.errs[name] = err
+				return nil
+			}
+			if info.IsDir() {
+				if w.recursive {
+					w.walk(path, name, paths)
+				}
+				return nil
+			}
+		} else if !d.Type().IsRegular() {
+			return nil
+		}
+
+		if _, err := w.factory.GetParser(w.factory.fileExtension(name)); err == nil {
+			*paths = append(*paths, name)
+		}
+		return nil
+	})
+	if err != nil {
+		w.errs[display] = err
+	}
+}
+
+// walked はdirがたどったディレクトリ、またはその中のディレクトリかどうかを返す
+func (w *dirWalker) walked(dir string) bool {
+	for _, root := range w.roots {
+		if rel, err := filepath.Rel(root, dir); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
@@ -4,8 +4,13 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"io/fs"
+	"net/http"
 	"os"
+	"regexp"
 	"strings"
+	"sync/atomic"
+	"unicode/utf8"
 )
 
 // DocumentParser はドキュメントをパースするインターフェース
@@ -24,7 +29,188 @@ type DocumentParser interface {
 }
 
 // BaseParser は共通処理を提供する基底構造体
-type BaseParser struct{}
+type BaseParser struct {
+	// PageSeparator はページ/スライド/シートの間に挿入する区切り文字列（例: フォームフィード "\f"）
+	// 空の場合は区切りを挿入しない
+	PageSeparator string
+
+	// CellSeparator は表のセルの間に挿入する区切り文字列（DOCX・Excel・CSVの表）
+	// 空の場合は各パーサーのデフォルト（DOCXはタブ、Excel・CSVは " | "）を使う
+	CellSeparator string
+
+	// MaxOutputBytes はパース結果の最大バイト数（0以下は無制限）
+	// 超えた場合は末尾をTruncatedMarkerにした上でこのバイト数以内に切り詰める
+	MaxOutputBytes int
+
+	// MarkerStyle はPDFのページ・PPTXのスライド・Excelのシートの境界の書式（デフォルトはMarkerMarkdown）
+	MarkerStyle MarkerStyle
+
+	// Labels は見出しなどの出力する固定の文字列（nilの場合はDefaultLabelsの英語の文字列）
+	Labels *Labels
+
+	// CollapseBlankLines が true の場合、パース結果の連続する空行（空白のみの行を含む）を1行にまとめる
+	// MaxOutputBytes と同じく、ParseFromFile・ParseFromBytes とファクトリーのパース結果に適用される
+	CollapseBlankLines bool
+}
+
+// Labels はパース結果に出力する固定の文字列
+// 英語以外の環境で翻訳したり、空にして出力しないようにするために使う
+// 見出しの "%s" はページ番号・スライド番号・シート名に置き換える
+type Labels struct {
+	// EmptySlide はテキストのないスライドの本文（空の場合は何も出力しない）
+	EmptySlide string
+	// PageHeader はPDF・OneNoteのページの見出し（空の場合は見出しを出力しない）
+	PageHeader string
+	// SlideHeader はPPTXのスライドの見出し（空の場合は見出しを出力しない）
+	SlideHeader string
+	// SheetHeader はExcelのシートの見出し（空の場合は見出しを出力しない）
+	// IncludeSheetIndex が true の場合、"%s" は "番号: シート名" になる
+	SheetHeader string
+	// Separator はExcelの各シートの末尾に出力する区切り線（空の場合は区切り線を出力しない）
+	Separator string
+}
+
+// DefaultLabels はデフォルトの英語のLabelsを返す
+// 一部だけ変更する場合はこの戻り値を書き換えて使う
+func DefaultLabels() Labels {
+	return Labels{
+		EmptySlide:  "(No text found)",
+		PageHeader:  "Page %s",
+		SlideHeader: "Slide %s",
+		SheetHeader: "Sheet %s",
+		Separator:   "---",
+	}
+}
+
+// MarkerStyle はページ/スライド/シートの境界を示す見出しの書式
+type MarkerStyle int
+
+const (
+	// MarkerMarkdown は人が読むためのMarkdownの見出し（"## Page 3"、"## Slide 3"、"# Sheet 名前"）
+	MarkerMarkdown MarkerStyle = iota
+	// MarkerSentinel は本文と衝突しにくい機械可読の行（"<<<PAGE:3>>>"、"<<<SLIDE:3>>>"、"<<<SHEET:名前>>>"）
+	// 結合した文字列をSplitSentinelMarkersで再び分割できる
+	MarkerSentinel
+	// MarkerNone は境界を出力しない
+	MarkerNone
+)
+
+// TruncatedMarker はMaxOutputBytesで切り詰めたパース結果の末尾に付ける文字列
+const TruncatedMarker = "\n...(truncated)"
+
+// IsTruncated はパース結果がMaxOutputBytesにより切り詰められているかどうかを返す
+func IsTruncated(text string) bool {
+	return strings.HasSuffix(text, TruncatedMarker)
+}
+
+// base は埋め込まれたBaseParserを返す（ファクトリーから共通オプションを設定するために使う）
+func (p *BaseParser) base() *BaseParser {
+	return p
+}
+
+// baseProvider はBaseParserを埋め込んだパーサー
+type baseProvider interface {
+	base() *BaseParser
+}
+
+// formattingPreserver はレイアウト（改行・空白）を保持するオプションを持つパーサー
+type formattingPreserver interface {
+	setPreserveFormatting(preserve bool)
+}
+
+// cellSeparator はCellSeparatorが設定されていればそれを、なければdefを返す
+func (p *BaseParser) cellSeparator(def string) string {
+	if p.CellSeparator != "" {
+		return p.CellSeparator
+	}
+	return def
+}
+
+// outputLimitReached は出力のサイズがMaxOutputBytesを超えたかどうかを返す
+// 大きな出力を組み立てるパーサーが、上限を超えた時点で抽出を打ち切るために使う
+func (p *BaseParser) outputLimitReached(size int) bool {
+	return p.MaxOutputBytes > 0 && size > p.MaxOutputBytes
+}
+
+// limitOutput はパーサーのCollapseBlankLinesに従って空行をまとめ、MaxOutputBytesに従ってパース結果を切り詰める
+func limitOutput(parser DocumentParser, text string) string {
+	b, ok := parser.(baseProvider)
+	if !ok {
+		return text
+	}
+	if b.base().CollapseBlankLines {
+		text = collapseBlankLines(text)
+	}
+	return truncateOutput(text, b.base().MaxOutputBytes)
+}
+
+// blankLinesPattern は2行以上続く空行（空白のみの行を含む）にマッチする
+var blankLinesPattern = regexp.MustCompile(`\n[ \t\r]*\n(?:[ \t\r]*\n)+`)
+
+// collapseBlankLines は連続する空行を1行の空行にまとめる
+func collapseBlankLines(text string) string {
+	return blankLinesPattern.ReplaceAllString(text, "\n\n")
+}
+
+// truncateOutput はtextがmaxBytesを超える場合、UTF-8の文字の途中で切らないように
+// 切り詰めてTruncatedMarkerを付ける（結果はTruncatedMarkerを含めてmaxBytes以内）
+// maxBytesがTruncatedMarkerより短い場合はTruncatedMarkerを付けずに切り詰める
+func truncateOutput(text string, maxBytes int) string {
+	if maxBytes <= 0 || len(text) <= maxBytes {
+		return text
+	}
+	if maxBytes < len(TruncatedMarker) {
+		return cutText(text, maxBytes)
+	}
+	return cutText(text, maxBytes-len(TruncatedMarker)) + TruncatedMarker
+}
+
+// cutText はtextをUTF-8の文字の途中で切らないようにnバイト以内に切り詰める
+func cutText(text string, n int) string {
+	if len(text) <= n {
+		return text
+	}
+	for n > 0 && !utf8.RuneStart(text[n]) {
+		n--
+	}
+	return text[:n]
+}
+
+// boundaryMarker はMarkerStyleに従ってページ/スライド/シートの境界の見出しを返す
+// markdownはMarkerMarkdownの場合の見出し、kindとlabelはMarkerSentinelの場合の種類（"PAGE" など）と名前
+func (p *BaseParser) boundaryMarker(markdown, kind, label string) string {
+	switch p.MarkerStyle {
+	case MarkerSentinel:
+		return fmt.Sprintf("<<<%s:%s>>>\n", kind, label)
+	case MarkerNone:
+		return ""
+	}
+	return markdown
+}
+
+// labels はLabelsが設定されていればそれを、なければDefaultLabelsを返す
+func (p *BaseParser) labels() Labels {
+	if p.Labels != nil {
+		return *p.Labels
+	}
+	return DefaultLabels()
+}
+
+// heading はLabelsの見出しのformatの "%s" をvalueに置き換え、prefix（"## " など）とsuffix（改行）を付ける
+// formatが空の場合は見出しを出力しないため空文字列を返す
+func heading(prefix, format, value, suffix string) string {
+	if format == "" {
+		return ""
+	}
+	return prefix + strings.ReplaceAll(format, "%s", value) + suffix
+}
+
+// writePageSeparator は2ページ目以降の前に区切り文字列を書き込む
+func (p *BaseParser) writePageSeparator(sb *strings.Builder, pageIndex int) {
+	if pageIndex > 0 && p.PageSeparator != "" {
+		sb.WriteString(p.PageSeparator)
+	}
+}
 
 // ParseFromBytes のデフォルト実装（ReaderAtを使う実装にフォールバック）
 func (p *BaseParser) ParseFromBytes(data []byte) (string, error) {
@@ -66,18 +252,47 @@ func parseFromFileCommon(p DocumentParser, filePath string) (string, error) {
 		return "", fmt.Errorf("failed to get file stats: %w", err)
 	}
 
-	return p.ParseFromReader(file, stat.Size())
+	text, err := p.ParseFromReader(file, stat.Size())
+	return limitOutput(p, text), err
 }
 
 // parseFromBytesCommon は各パーサーで利用可能な共通実装
 func parseFromBytesCommon(p DocumentParser, data []byte) (string, error) {
 	reader := bytes.NewReader(data)
-	return p.ParseFromReader(reader, int64(len(data)))
+	text, err := p.ParseFromReader(reader, int64(len(data)))
+	return limitOutput(p, text), err
 }
 
 // DocumentParserFactory はファイル拡張子に基づいてパーサーを返す
 type DocumentParserFactory struct {
 	parsers map[string]DocumentParser
+	// priorities はRegisterParserWithPriorityで登録された拡張子ごとの優先度（ないものは0）
+	priorities    map[string]int
+	pageSeparator string
+	cellSeparator string
+
+	// preserveFormatting はSetPreserveFormattingで設定された値
+	preserveFormatting bool
+
+	// maxOutputBytes はSetMaxOutputBytesで設定された値
+	maxOutputBytes int
+
+	// markerStyle はSetMarkerStyleで設定された値
+	markerStyle MarkerStyle
+	// labels はSetLabelsで設定された値
+	labels *Labels
+	// collapseBlankLines はSetCollapseBlankLinesで設定された値
+	collapseBlankLines bool
+
+	// semaphore は同時に実行するパースの数を制限する（nilの場合は無制限）
+	// パースの実行中にSetMaxConcurrencyで変更できるように、アトミックに読み書きする
+	semaphore atomic.Pointer[chan struct{}]
+
+	// extensionResolver はSetExtensionResolverで設定された、パスから拡張子を決める関数
+	extensionResolver func(path string) string
+
+	// httpClient はParseFromURLで使うHTTPクライアント（nilの場合はデフォルト）
+	httpClient *http.Client
 }
 
 // NewDocumentParserFactory はファクトリーを初期化
@@ -107,36 +322,322 @@ func NewDocumentParserFactory() *DocumentParserFactory {
 		factory.parsers[ext] = textParser
 	}
 
+	markdownParser := &MarkdownParser{}
+	for _, ext := range markdownParser.SupportedExtensions() {
+		factory.parsers[ext] = markdownParser
+	}
+
+	rstParser := &RSTParser{}
+	for _, ext := range rstParser.SupportedExtensions() {
+		factory.parsers[ext] = rstParser
+	}
+
+	asciiDocParser := &AsciiDocParser{}
+	for _, ext := range asciiDocParser.SupportedExtensions() {
+		factory.parsers[ext] = asciiDocParser
+	}
+
+	latexParser := &LaTeXParser{}
+	for _, ext := range latexParser.SupportedExtensions() {
+		factory.parsers[ext] = latexParser
+	}
+
+	htmlParser := &HTMLParser{}
+	for _, ext := range htmlParser.SupportedExtensions() {
+		factory.parsers[ext] = htmlParser
+	}
+
+	mhtmlParser := &MHTMLParser{}
+	for _, ext := range mhtmlParser.SupportedExtensions() {
+		factory.parsers[ext] = mhtmlParser
+	}
+
+	jsonParser := &JSONParser{}
+	for _, ext := range jsonParser.SupportedExtensions() {
+		factory.parsers[ext] = jsonParser
+	}
+
+	xmlParser := &XMLParser{}
+	for _, ext := range xmlParser.SupportedExtensions() {
+		factory.parsers[ext] = xmlParser
+	}
+
+	for _, propertiesParser := range []*PropertiesParser{{}, {DotEnv: true}} {
+		for _, ext := range propertiesParser.SupportedExtensions() {
+			factory.parsers[ext] = propertiesParser
+		}
+	}
+
+	csvParser := &CSVParser{}
+	for _, ext := range csvParser.SupportedExtensions() {
+		factory.parsers[ext] = csvParser
+	}
+
+	dbfParser := &DBFParser{}
+	for _, ext := range dbfParser.SupportedExtensions() {
+		factory.parsers[ext] = dbfParser
+	}
+
+	vcardParser := &VCardParser{}
+	for _, ext := range vcardParser.SupportedExtensions() {
+		factory.parsers[ext] = vcardParser
+	}
+
+	icsParser := &ICSParser{}
+	for _, ext := range icsParser.SupportedExtensions() {
+		factory.parsers[ext] = icsParser
+	}
+
+	subtitleParser := &SubtitleParser{}
+	for _, ext := range subtitleParser.SupportedExtensions() {
+		factory.parsers[ext] = subtitleParser
+	}
+
+	ipynbParser := &IPYNBParser{}
+	for _, ext := range ipynbParser.SupportedExtensions() {
+		factory.parsers[ext] = ipynbParser
+	}
+
+	jsonlParser := &JSONLParser{}
+	for _, ext := range jsonlParser.SupportedExtensions() {
+		factory.parsers[ext] = jsonlParser
+	}
+
+	oneNoteParser := &OneNoteParser{}
+	for _, ext := range oneNoteParser.SupportedExtensions() {
+		factory.parsers[ext] = oneNoteParser
+	}
+
+	pagesParser := &PagesParser{}
+	for _, ext := range pagesParser.SupportedExtensions() {
+		factory.parsers[ext] = pagesParser
+	}
+
+	decompressingParser := &DecompressingParser{Inner: textParser}
+	for _, ext := range decompressingParser.SupportedExtensions() {
+		factory.parsers[ext] = decompressingParser
+	}
+
+	drivePointerParser := &DrivePointerParser{}
+	for _, ext := range drivePointerParser.SupportedExtensions() {
+		factory.parsers[ext] = drivePointerParser
+	}
+
 	excelParser := &ExcelParser{}
 	for _, ext := range excelParser.SupportedExtensions() {
 		factory.parsers[ext] = excelParser
 	}
 
+	zipParser := &ZIPParser{Factory: factory}
+	for _, ext := range zipParser.SupportedExtensions() {
+		factory.parsers[ext] = zipParser
+	}
+
+	tarParser := &TarParser{Factory: factory}
+	for _, ext := range tarParser.SupportedExtensions() {
+		factory.parsers[ext] = tarParser
+	}
+
 	return factory
 }
 
 // GetParser は拡張子に対応するパーサーを返す
 func (f *DocumentParserFactory) GetParser(extension string) (DocumentParser, error) {
-	ext := strings.ToLower(extension)
-	if !strings.HasPrefix(ext, ".") {
-		ext = "." + ext
-	}
-
+	ext := normalizeExtension(extension)
 	parser, ok := f.parsers[ext]
 	if !ok {
-		return nil, fmt.Errorf("unsupported file extension: %s", extension)
+		// ".log.gz" のような圧縮されたファイルは展開してから圧縮を除いた拡張子のパーサーに渡す
+		if parser, ok = f.decompressingParser(ext); !ok {
+			return nil, fmt.Errorf("unsupported file extension: %s", extension)
+		}
 	}
 
 	return parser, nil
 }
 
 // RegisterParser はカスタムパーサーを登録
+// 優先度0のRegisterParserWithPriorityと同じで、より高い優先度で登録されたパーサーは置き換えない
 func (f *DocumentParserFactory) RegisterParser(parser DocumentParser) {
+	f.RegisterParserWithPriority(parser, 0)
+}
+
+// RegisterParserWithPriority は優先度を指定してカスタムパーサーを登録する
+// 複数のパーサーが同じ拡張子を扱う場合、GetParserは優先度が最も高いパーサーを返す
+// 優先度が同じ場合は後に登録したパーサーが優先される。組み込みのパーサーの優先度は0のため、
+// 負の優先度で登録したパーサーは組み込みのパーサーがない拡張子のみを担当する
+func (f *DocumentParserFactory) RegisterParserWithPriority(parser DocumentParser, priority int) {
+	f.applyOptions(parser)
 	for _, ext := range parser.SupportedExtensions() {
-		f.parsers[ext] = parser
+		f.register(ext, parser, priority)
+	}
+}
+
+// RegisterParserForExtensions は指定した拡張子のみにパーサーを登録する
+// パーサーのSupportedExtensionsに含まれない拡張子も登録できる。優先度は0として扱う
+func (f *DocumentParserFactory) RegisterParserForExtensions(parser DocumentParser, exts ...string) {
+	f.applyOptions(parser)
+	for _, ext := range exts {
+		f.register(normalizeExtension(ext), parser, 0)
+	}
+}
+
+// register は拡張子に登録済みのパーサーの優先度がpriority以下の場合にパーサーを登録する
+func (f *DocumentParserFactory) register(ext string, parser DocumentParser, priority int) {
+	if _, ok := f.parsers[ext]; ok && f.priorities[ext] > priority {
+		return
+	}
+	if f.priorities == nil {
+		f.priorities = make(map[string]int)
+	}
+	f.parsers[ext] = parser
+	f.priorities[ext] = priority
+}
+
+// SetPageSeparator は登録済みの全パーサーにページ/スライド/シート間の区切り文字列を設定する
+// 以降に登録されるパーサーにも適用される
+func (f *DocumentParserFactory) SetPageSeparator(separator string) {
+	f.pageSeparator = separator
+	for _, parser := range f.parsers {
+		if b, ok := parser.(baseProvider); ok {
+			b.base().PageSeparator = separator
+		}
 	}
 }
 
+// SetMaxConcurrency はファクトリーのParseFrom*で同時に実行するパースの数をnに制限する
+// 上限に達している場合、呼び出しは他のパースが終わるまで待機する。0以下は無制限
+// パースの実行中に変更した場合、実行中のパースは変更前の制限で解放される
+func (f *DocumentParserFactory) SetMaxConcurrency(n int) {
+	if n <= 0 {
+		f.semaphore.Store(nil)
+		return
+	}
+	semaphore := make(chan struct{}, n)
+	f.semaphore.Store(&semaphore)
+}
+
+// acquire はパースの実行枠を確保し、解放する関数を返す
+func (f *DocumentParserFactory) acquire() func() {
+	semaphore := f.semaphore.Load()
+	if semaphore == nil {
+		return func() {}
+	}
+	*semaphore <- struct{}{}
+	return func() { <-*semaphore }
+}
+
+// SetCellSeparator は登録済みの全パーサーに表のセルの区切り文字列を設定する
+// 以降にRegisterParserで登録したパーサーにも適用される
+func (f *DocumentParserFactory) SetCellSeparator(separator string) {
+	f.cellSeparator = separator
+	for _, parser := range f.parsers {
+		if b, ok := parser.(baseProvider); ok {
+			b.base().CellSeparator = separator
+		}
+	}
+}
+
+// SetMaxOutputBytes は登録済みの全パーサーにパース結果の最大バイト数を設定する（0以下は無制限）
+// 超えた分は切り詰められ、結果の末尾はTruncatedMarkerになる（IsTruncatedで判定できる）
+// 以降にRegisterParserで登録したパーサーにも適用される
+func (f *DocumentParserFactory) SetMaxOutputBytes(n int) {
+	f.maxOutputBytes = n
+	for _, parser := range f.parsers {
+		if b, ok := parser.(baseProvider); ok {
+			b.base().MaxOutputBytes = n
+		}
+	}
+}
+
+// SetMarkerStyle は登録済みの全パーサーにページ/スライド/シートの境界の書式を設定する
+// MarkerSentinelを使うと、結合した文字列をSplitSentinelMarkersで確実に再分割できる
+// 以降にRegisterParserで登録したパーサーにも適用される
+func (f *DocumentParserFactory) SetMarkerStyle(style MarkerStyle) {
+	f.markerStyle = style
+	for _, parser := range f.parsers {
+		if b, ok := parser.(baseProvider); ok {
+			b.base().MarkerStyle = style
+		}
+	}
+}
+
+// SetLabels は登録済みの全パーサーに見出しなどの出力する固定の文字列を設定する
+// 以降にRegisterParserで登録したパーサーにも適用される
+func (f *DocumentParserFactory) SetLabels(labels Labels) {
+	f.labels = &labels
+	for _, parser := range f.parsers {
+		if b, ok := parser.(baseProvider); ok {
+			b.base().Labels = f.labels
+		}
+	}
+}
+
+// SetCollapseBlankLines は登録済みの全パーサーに、パース結果の連続する空行を1行にまとめるかどうかを設定する
+// 以降にRegisterParserで登録したパーサーにも適用される
+func (f *DocumentParserFactory) SetCollapseBlankLines(collapse bool) {
+	f.collapseBlankLines = collapse
+	for _, parser := range f.parsers {
+		if b, ok := parser.(baseProvider); ok {
+			b.base().CollapseBlankLines = collapse
+		}
+	}
+}
+
+// SetPreserveFormatting は登録済みの全パーサーにレイアウトをできるだけ忠実に保持するかどうかを設定する
+// 以降にRegisterParserで登録したパーサーにも適用される。対応するパーサーとオプションは以下のとおり
+//   - PDFParser: PreserveWhitespace（行ごとの改行を残し、空白を圧縮しない）
+//   - DOCXParser: PreserveLineBreaks（段落内の改行とタブを残す）
+//
+// その他のパーサーは元々改行を保持するため影響を受けない
+func (f *DocumentParserFactory) SetPreserveFormatting(preserve bool) {
+	f.preserveFormatting = preserve
+	for _, parser := range f.parsers {
+		if fp, ok := parser.(formattingPreserver); ok {
+			fp.setPreserveFormatting(preserve)
+		}
+	}
+}
+
+// applyOptions はファクトリーに設定されたオプションをパーサーに適用する
+// ファクトリー側で設定されていないオプションはパーサーの値を上書きしない
+func (f *DocumentParserFactory) applyOptions(parser DocumentParser) {
+	if fp, ok := parser.(formattingPreserver); ok && f.preserveFormatting {
+		fp.setPreserveFormatting(true)
+	}
+
+	b, ok := parser.(baseProvider)
+	if !ok {
+		return
+	}
+	if f.pageSeparator != "" {
+		b.base().PageSeparator = f.pageSeparator
+	}
+	if f.cellSeparator != "" {
+		b.base().CellSeparator = f.cellSeparator
+	}
+	if f.maxOutputBytes > 0 {
+		b.base().MaxOutputBytes = f.maxOutputBytes
+	}
+	if f.markerStyle != MarkerMarkdown {
+		b.base().MarkerStyle = f.markerStyle
+	}
+	if f.labels != nil {
+		b.base().Labels = f.labels
+	}
+	if f.collapseBlankLines {
+		b.base().CollapseBlankLines = true
+	}
+}
+
+// normalizeExtension は拡張子を小文字かつドット始まりの形式に正規化する
+func normalizeExtension(extension string) string {
+	ext := strings.ToLower(extension)
+	if !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	return ext
+}
+
 // SupportedExtensions はファクトリでサポートされる全ての拡張子を返す
 func (f *DocumentParserFactory) SupportedExtensions() []string {
 	var extensions []string
@@ -149,7 +650,9 @@ func (f *DocumentParserFactory) SupportedExtensions() []string {
 // ParseFromFile はファイルパスからドキュメントをパースする
 // ファイルの拡張子を自動的に検出し、適切なパーサーを使用する
 func (f *DocumentParserFactory) ParseFromFile(filePath string) (string, error) {
-	ext := getFileExtension(filePath)
+	defer f.acquire()()
+
+	ext := f.fileExtension(filePath)
 	parser, err := f.GetParser(ext)
 	if err != nil {
 		return "", fmt.Errorf("failed to get parser: %w", err)
@@ -160,11 +663,13 @@ func (f *DocumentParserFactory) ParseFromFile(filePath string) (string, error) {
 		return "", fmt.Errorf("failed to parse file: %w", err)
 	}
 
-	return content, nil
+	return limitOutput(parser, content), nil
 }
 
 // ParseFromBytes はバイト配列からドキュメントをパースする
 func (f *DocumentParserFactory) ParseFromBytes(ext string, data []byte) (string, error) {
+	defer f.acquire()()
+
 	parser, err := f.GetParser(ext)
 	if err != nil {
 		return "", fmt.Errorf("failed to get parser: %w", err)
@@ -175,24 +680,115 @@ func (f *DocumentParserFactory) ParseFromBytes(ext string, data []byte) (string,
 		return "", fmt.Errorf("failed to parse bytes: %w", err)
 	}
 
-	return content, nil
+	return limitOutput(parser, content), nil
 }
 
 // ParseFromReader はio.ReaderAtからドキュメントをパースする
 func (f *DocumentParserFactory) ParseFromReader(ext string, reader io.ReaderAt, size int64) (string, error) {
+	defer f.acquire()()
+
+	parser, err := f.GetParser(ext)
+	if err != nil {
+		return "", fmt.Errorf("failed to get parser: %w", err)
+	}
+
+	content, err := parser.ParseFromReader(reader, size)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse from reader: %w", err)
+	}
+
+	return limitOutput(parser, content), nil
+}
+
+// ParsePreview はドキュメントの先頭からmaxChars文字（rune）までのテキストを返す（検索結果のスニペットなどに使う）
+// パーサーの複製にMaxOutputBytesを設定するため、PDFのページ・PPTXのスライド・Excelのシート・Wordの段落は
+// 上限に達した時点で以降を読まない。結果にTruncatedMarkerは付けない
+func (f *DocumentParserFactory) ParsePreview(ext string, reader io.ReaderAt, size int64, maxChars int) (string, error) {
+	defer f.acquire()()
+
+	if maxChars <= 0 {
+		return "", fmt.Errorf("maxChars must be positive: %d", maxChars)
+	}
 	parser, err := f.GetParser(ext)
 	if err != nil {
 		return "", fmt.Errorf("failed to get parser: %w", err)
 	}
 
+	// 1文字は最大でutf8.UTFMaxバイトのため、このバイト数まで抽出すればmaxChars文字に足りる
+	parser = cloneParser(parser)
+	if b, ok := parser.(baseProvider); ok {
+		b.base().MaxOutputBytes = maxChars * utf8.UTFMax
+	}
+
 	content, err := parser.ParseFromReader(reader, size)
 	if err != nil {
 		return "", fmt.Errorf("failed to parse from reader: %w", err)
 	}
 
+	for i := range content {
+		if maxChars == 0 {
+			return content[:i], nil
+		}
+		maxChars--
+	}
 	return content, nil
 }
 
+// ParseFromFS はfs.FS（embed.FSやzip.Readerなど）上のファイルをパースする
+// fs.FileがReaderAtを実装していない場合は内容をメモリに読み込んでからパースする
+func (f *DocumentParserFactory) ParseFromFS(fsys fs.FS, name string) (string, error) {
+	defer f.acquire()()
+
+	parser, err := f.GetParser(f.fileExtension(name))
+	if err != nil {
+		return "", fmt.Errorf("failed to get parser: %w", err)
+	}
+
+	file, err := fsys.Open(name)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return "", fmt.Errorf("failed to get file stats: %w", err)
+	}
+
+	var content string
+	if readerAt, ok := file.(io.ReaderAt); ok {
+		content, err = parser.ParseFromReader(readerAt, stat.Size())
+	} else {
+		data, readErr := io.ReadAll(file)
+		if readErr != nil {
+			return "", fmt.Errorf("failed to read file: %w", readErr)
+		}
+		content, err = parser.ParseFromBytes(data)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to parse file: %w", err)
+	}
+
+	return limitOutput(parser, content), nil
+}
+
+// limitPages はMaxOutputBytesに従って、ページの順に内容の合計が上限を超えたページを切り詰めて以降のページを除く
+func limitPages(parser DocumentParser, pages map[string]string) map[string]string {
+	b, ok := parser.(baseProvider)
+	if !ok {
+		return pages
+	}
+	limited, truncated := truncatePages(sortedPages(pages), b.base().MaxOutputBytes)
+	if !truncated {
+		return pages
+	}
+	result := make(map[string]string, len(limited))
+	for _, page := range limited {
+		result[page.Name] = page.Content
+	}
+	return result
+}
+
 // PageSeparatedParser はページやシートごとに分割してパースするインターフェース
 type PageSeparatedParser interface {
 	DocumentParser
@@ -202,7 +798,9 @@ type PageSeparatedParser interface {
 
 // ParseFromFileWithPages はファイルパスからドキュメントをパースし、可能な場合はページ/シートごとに分割して返す
 func (f *DocumentParserFactory) ParseFromFileWithPages(filePath string) (map[string]string, error) {
-	ext := getFileExtension(filePath)
+	defer f.acquire()()
+
+	ext := f.fileExtension(filePath)
 	parser, err := f.GetParser(ext)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get parser: %w", err)
@@ -220,7 +818,11 @@ func (f *DocumentParserFactory) ParseFromFileWithPages(filePath string) (map[str
 			return nil, fmt.Errorf("failed to get file stats: %w", err)
 		}
 
-		return p.ParseWithPages(file, stat.Size())
+		pages, err := p.ParseWithPages(file, stat.Size())
+		if err != nil {
+			return nil, err
+		}
+		return limitPages(parser, pages), nil
 	}
 
 	// PageSeparatedParserを実装していない場合は通常パースを行い、全体を一つの要素として返す
@@ -234,14 +836,19 @@ func (f *DocumentParserFactory) ParseFromFileWithPages(filePath string) (map[str
 
 // ParseFromBytesWithPages はバイト配列からドキュメントをパースし、可能な場合はページ/シートごとに分割して返す
 func (f *DocumentParserFactory) ParseFromBytesWithPages(ext string, data []byte) (map[string]string, error) {
+	defer f.acquire()()
+
 	parser, err := f.GetParser(ext)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get parser: %w", err)
 	}
 
 	if p, ok := parser.(PageSeparatedParser); ok {
-		reader := bytes.NewReader(data)
-		return p.ParseWithPages(reader, int64(len(data)))
+		pages, err := p.ParseWithPages(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, err
+		}
+		return limitPages(parser, pages), nil
 	}
 
 	content, err := parser.ParseFromBytes(data)
@@ -254,13 +861,19 @@ func (f *DocumentParserFactory) ParseFromBytesWithPages(ext string, data []byte)
 
 // ParseFromReaderWithPages はio.ReaderAtからドキュメントをパースし、可能な場合はページ/シートごとに分割して返す
 func (f *DocumentParserFactory) ParseFromReaderWithPages(ext string, reader io.ReaderAt, size int64) (map[string]string, error) {
+	defer f.acquire()()
+
 	parser, err := f.GetParser(ext)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get parser: %w", err)
 	}
 
 	if p, ok := parser.(PageSeparatedParser); ok {
-		return p.ParseWithPages(reader, size)
+		pages, err := p.ParseWithPages(reader, size)
+		if err != nil {
+			return nil, err
+		}
+		return limitPages(parser, pages), nil
 	}
 
 	content, err := parser.ParseFromReader(reader, size)
@@ -271,8 +884,57 @@ func (f *DocumentParserFactory) ParseFromReaderWithPages(ext string, reader io.R
 	return map[string]string{"Content": content}, nil
 }
 
+// PageCounter は全文を抽出せずにページ数を返せるパーサーのインターフェース
+type PageCounter interface {
+	// PageCount はページ/スライド/シート数を返す
+	PageCount(reader io.ReaderAt, size int64) (int, error)
+}
+
+// PageCount はドキュメントのページ数を全文抽出なしで返す
+// ページ数が意味を持たない形式（プレーンテキストなど）の場合は -1 を返す
+func (f *DocumentParserFactory) PageCount(ext string, reader io.ReaderAt, size int64) (int, error) {
+	parser, err := f.GetParser(ext)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get parser: %w", err)
+	}
+
+	counter, ok := parser.(PageCounter)
+	if !ok {
+		return -1, nil
+	}
+
+	count, err := counter.PageCount(reader, size)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count pages: %w", err)
+	}
+
+	return count, nil
+}
+
+// SetExtensionResolver はファイルパスから拡張子を決める関数を設定する
+// ParseFromFile、ParseFromFS、ParseFromFileWithPages、IdentifyFile、アーカイブ内のエントリでパーサーを選ぶ前に呼ばれ、
+// 返した拡張子（".pdf" など）でパーサーを選ぶ。空文字列を返した場合とnilを設定した場合はパスの拡張子を使う
+// ディスク上のファイル名を変えずに、".dat" のような意味のない拡張子を独自の規則で対応付ける場合に使う
+func (f *DocumentParserFactory) SetExtensionResolver(resolver func(path string) string) {
+	f.extensionResolver = resolver
+}
+
+// fileExtension はSetExtensionResolverの関数、またはパスの拡張子からパーサーを選ぶための拡張子を返す
+func (f *DocumentParserFactory) fileExtension(filePath string) string {
+	if f.extensionResolver != nil {
+		if ext := f.extensionResolver(filePath); ext != "" {
+			return ext
+		}
+	}
+	return getFileExtension(filePath)
+}
+
 // getFileExtension はファイルパスから拡張子を取得
+// .tar.gz、.log.gz のような圧縮の拡張子を含む複合拡張子はまとめて返す
 func getFileExtension(filePath string) string {
+	if ext, ok := compressedExtension(filePath); ok {
+		return ext
+	}
 	for i := len(filePath) - 1; i >= 0; i-- {
 		if filePath[i] == '.' {
 			return filePath[i:]
@@ -0,0 +1,284 @@
+package documentParser
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSetMaxConcurrencyDuringParse(t *testing.T) {
+	factory := NewDocumentParserFactory()
+	factory.SetMaxConcurrency(2)
+	data := []byte("hello\n")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				text, err := factory.ParseFromReader(".txt", bytes.NewReader(data), int64(len(data)))
+				if err != nil || text != "hello\n" {
+					t.Errorf("ParseFromReader() = %q, %v", text, err)
+					return
+				}
+			}
+		}()
+	}
+	for n := 0; n < 50; n++ {
+		factory.SetMaxConcurrency(n % 3)
+	}
+	wg.Wait()
+}
+
+func TestSetMaxConcurrencyLimit(t *testing.T) {
+	factory := NewDocumentParserFactory()
+	factory.SetMaxConcurrency(1)
+
+	release := factory.acquire()
+	acquired := make(chan struct{})
+	go func() {
+		defer close(acquired)
+		factory.acquire()()
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("acquire() returned while the only slot was held")
+	case <-time.After(50 * time.Millisecond):
+	}
+	release()
+	<-acquired
+}
+
+// maxOffsetReaderAt は読み込まれた範囲の末尾の最大値を記録するio.ReaderAt
+type maxOffsetReaderAt struct {
+	*bytes.Reader
+	maxOffset int64
+}
+
+func (r *maxOffsetReaderAt) ReadAt(b []byte, off int64) (int, error) {
+	n, err := r.Reader.ReadAt(b, off)
+	r.maxOffset = max(r.maxOffset, off+int64(n))
+	return n, err
+}
+
+func TestParseFromReaderMaxOutputBytes(t *testing.T) {
+	tests := []struct {
+		name   string
+		parser DocumentParser
+		data   string
+	}{
+		{"text", &TextParser{BaseParser: BaseParser{MaxOutputBytes: 64}}, strings.Repeat("line of text\n", 1000)},
+		{"csv", &CSVParser{BaseParser: BaseParser{MaxOutputBytes: 64}}, strings.Repeat("a,b,c\n", 1000)},
+		{"jsonl", &JSONLParser{BaseParser: BaseParser{MaxOutputBytes: 64}}, strings.Repeat(`{"k":"v"}`+"\n", 1000)},
+		{"html", &HTMLParser{BaseParser: BaseParser{MaxOutputBytes: 64}}, "<html><body>" + strings.Repeat("<p>paragraph</p>", 1000) + "</body></html>"},
+		{"markdown", &MarkdownParser{TextParser: TextParser{BaseParser: BaseParser{MaxOutputBytes: 64}}, StripMarkdown: true}, strings.Repeat("# heading\n", 1000)},
+		{"properties", &PropertiesParser{TextParser: TextParser{BaseParser: BaseParser{MaxOutputBytes: 64}}, Normalize: true}, strings.Repeat("key=value\n", 1000)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.parser.ParseFromReader(strings.NewReader(tt.data), int64(len(tt.data)))
+			if err != nil {
+				t.Fatalf("ParseFromReader() error = %v", err)
+			}
+			if len(got) > 64 || !IsTruncated(got) {
+				t.Errorf("ParseFromReader() = %q (%d bytes), want at most 64 bytes ending with TruncatedMarker", got, len(got))
+			}
+		})
+	}
+}
+
+func TestTextParserParseFromReaderReadsOnlyLimit(t *testing.T) {
+	data := strings.Repeat("x", 1<<20)
+	reader := &maxOffsetReaderAt{Reader: bytes.NewReader([]byte(data))}
+
+	parser := &TextParser{BaseParser: BaseParser{MaxOutputBytes: 100}}
+	if _, err := parser.ParseFromReader(reader, int64(len(data))); err != nil {
+		t.Fatalf("ParseFromReader() error = %v", err)
+	}
+	if reader.maxOffset > 101 {
+		t.Errorf("ParseFromReader() read up to offset %d, want at most 101", reader.maxOffset)
+	}
+}
+
+func TestTruncatePagesSmallRemainingBudget(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents []string
+		maxBytes int
+	}{
+		{"budget smaller than marker", []string{strings.Repeat("a", 30), strings.Repeat("b", 30)}, 35},
+		{"first page fills budget", []string{strings.Repeat("a", 40), strings.Repeat("b", 30)}, 40},
+		{"limit smaller than marker", []string{strings.Repeat("a", 30)}, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var pages []Page
+			for i, content := range tt.contents {
+				pages = append(pages, Page{Number: i + 1, Content: content})
+			}
+			got, truncated := truncatePages(pages, tt.maxBytes)
+			if !truncated {
+				t.Fatal("truncatePages() truncated = false, want true")
+			}
+			total := 0
+			for _, page := range got {
+				total += len(page.Content)
+			}
+			if total > tt.maxBytes {
+				t.Errorf("truncatePages() total = %d bytes, want at most %d", total, tt.maxBytes)
+			}
+		})
+	}
+}
+
+func TestParseFromBytesWithPagesMaxOutputBytes(t *testing.T) {
+	data := buildTestWorkbook(t, []string{"One", "Two", "Three"}, map[string]any{
+		"One!A1":   strings.Repeat("a", 100),
+		"Two!A1":   strings.Repeat("b", 100),
+		"Three!A1": strings.Repeat("c", 100),
+	})
+
+	factory := NewDocumentParserFactory()
+	factory.SetMaxOutputBytes(150)
+	pages, err := factory.ParseFromBytesWithPages(".xlsx", data)
+	if err != nil {
+		t.Fatalf("ParseFromBytesWithPages() error = %v", err)
+	}
+
+	total, truncated := 0, false
+	for _, content := range pages {
+		total += len(content)
+		truncated = truncated || IsTruncated(content)
+	}
+	if total > 150 || !truncated {
+		t.Errorf("ParseFromBytesWithPages() = %q (%d bytes), want at most 150 bytes with TruncatedMarker", pages, total)
+	}
+}
+
+func TestCombinePages(t *testing.T) {
+	slides := map[string]string{"Slide 10": "ten\n", "Slide 2": "two"}
+	tests := []struct {
+		name  string
+		pages map[string]string
+		want  string
+	}{
+		{"slides", slides, "## Slide 2\ntwo\n\n## Slide 10\nten\n\n"},
+		{"pages", map[string]string{"Page 1": "one"}, "## Page 1\n\none\n\n"},
+		{"sheets", map[string]string{"Sheet1": "a | b\n", "Empty": ""}, "# Sheet Empty\n\n---\n\n# Sheet Sheet1\na | b\n\n---\n\n"},
+		{"content", map[string]string{"Content": "text"}, "text"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CombinePages(tt.pages); got != tt.want {
+				t.Errorf("CombinePages() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	factory := NewDocumentParserFactory()
+	factory.SetPageSeparator("\f")
+	labels := DefaultLabels()
+	labels.SlideHeader = "スライド %s"
+	factory.SetLabels(labels)
+	if got, want := factory.CombinePages(slides), "## スライド 2\ntwo\n\n\f## スライド 10\nten\n\n"; got != want {
+		t.Errorf("factory.CombinePages() = %q, want %q", got, want)
+	}
+
+	factory = NewDocumentParserFactory()
+	factory.SetMarkerStyle(MarkerSentinel)
+	got := factory.CombinePages(slides)
+	if want := "<<<SLIDE:2>>>\ntwo\n\n<<<SLIDE:10>>>\nten\n\n"; got != want {
+		t.Errorf("factory.CombinePages() = %q, want %q", got, want)
+	}
+	split := SplitSentinelMarkers(got)
+	if len(split) != 2 || split[0].Name != "Slide 2" || split[1].Name != "Slide 10" || split[1].Content != "ten" {
+		t.Errorf("SplitSentinelMarkers(CombinePages()) = %+v, want Slide 2 and Slide 10", split)
+	}
+
+	factory.SetMarkerStyle(MarkerNone)
+	if got, want := factory.CombinePages(map[string]string{"Sheet1": "a"}), "a\n\n---\n\n"; got != want {
+		t.Errorf("factory.CombinePages() = %q, want %q", got, want)
+	}
+}
+
+func TestSniffExtensionGzip(t *testing.T) {
+	gzipped := func(data []byte) []byte {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		gz.Write(data)
+		gz.Close()
+		return buf.Bytes()
+	}
+
+	var tarData bytes.Buffer
+	tw := tar.NewWriter(&tarData)
+	tw.WriteHeader(&tar.Header{Name: "a.txt", Mode: 0o644, Size: 5})
+	tw.Write([]byte("hello"))
+	tw.Close()
+
+	factory := NewDocumentParserFactory()
+	resp := &http.Response{
+		Request: &http.Request{URL: &url.URL{Path: "/download"}},
+		Header:  http.Header{"Content-Type": {"application/gzip"}},
+	}
+
+	tests := []struct {
+		name    string
+		data    []byte
+		want    string
+		wantURL string
+	}{
+		{"gzipped csv", gzipped([]byte("a,b\n1,2\n")), ".gz", ".gz"},
+		{"gzipped tar", gzipped(tarData.Bytes()), ".tar.gz", ".tar.gz"},
+		{"tar", tarData.Bytes(), ".tar", ".gz"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sniffExtension(bytes.NewReader(tt.data), int64(len(tt.data))); got != tt.want {
+				t.Errorf("sniffExtension() = %q, want %q", got, tt.want)
+			}
+			if got := factory.urlExtension(resp, tt.data); got != tt.wantURL {
+				t.Errorf("urlExtension(application/gzip) = %q, want %q", got, tt.wantURL)
+			}
+		})
+	}
+}
+
+// failingMetadataParser はメタデータの読み取りに失敗するテキストのパーサー
+type failingMetadataParser struct {
+	TextParser
+}
+
+func (p *failingMetadataParser) SupportedExtensions() []string {
+	return []string{".meta"}
+}
+
+func (p *failingMetadataParser) ParseMetadata(reader io.ReaderAt, size int64) (*Metadata, error) {
+	return nil, errors.New("broken metadata")
+}
+
+func TestParseStructuredMetadataError(t *testing.T) {
+	factory := NewDocumentParserFactory()
+	factory.RegisterParser(&failingMetadataParser{})
+
+	data := []byte("body")
+	doc, err := factory.ParseStructured(".meta", bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("ParseStructured() error = %v", err)
+	}
+	if doc.Metadata != nil || len(doc.Pages) != 1 || doc.Pages[0].Content != "body" {
+		t.Errorf("ParseStructured() = %+v, want pages without metadata", doc)
+	}
+}
@@ -3,37 +3,336 @@ package documentParser
 import (
 	"archive/zip"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"path"
+	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
 // DOCXParser はWordファイルのパーサー
 type DOCXParser struct {
 	BaseParser
+
+	// IncludeEmbeddedObjects が true の場合、word/embeddings/ 内の埋め込みオブジェクトのうち
+	// ファクトリーでパースできるもの（.xlsx など）のテキストを "[Embedded: 名前]" の見出し付きで末尾に追加する
+	IncludeEmbeddedObjects bool
+
+	// Factory は埋め込みオブジェクトのパースに使うファクトリー（nilの場合はデフォルトのファクトリー）
+	Factory *DocumentParserFactory
+
+	// AcceptRevisions が true の場合、変更履歴を承認した状態のテキストを返す（挿入は本文に含め、削除は除外する）
+	// AcceptRevisions と ShowRevisions がどちらも false の場合、変更履歴（w:ins / w:del）の中のテキストは出力しない
+	AcceptRevisions bool
+
+	// ShowRevisions が true の場合、挿入と削除を "[ins: テキスト]" / "[del: テキスト]" の形式で出力する
+	// AcceptRevisions が true の場合は無視される
+	ShowRevisions bool
+
+	// AnnotateRevisions が true の場合、ShowRevisions で出力する変更履歴に作成者と日付を付ける（例: "[ins by Alice 2024-01-01: テキスト]"）
+	AnnotateRevisions bool
+
+	// TableRowSeparator は表の行の間に挿入する区切り文字列（空の場合は改行）
+	// セルの区切りはBaseParserのCellSeparatorで設定する（デフォルトはタブ）
+	TableRowSeparator string
+
+	// FlattenTableCells が true の場合、以前の出力と同じく、セル内の空でない段落をそれぞれ1つの要素として区切り文字で結合する（空のセルは詰められる）
+	// false の場合、セル内の複数の段落をスペースで結合し、空のセルも残して列の位置を揃える
+	FlattenTableCells bool
+
+	// PreserveLineBreaks が true の場合、段落内の改行（w:br / w:cr）を "\n"、タブ（w:tab）を "\t" として出力する
+	// 改ページ（w:br w:type="page"）は従来どおりページの区切りとして扱う
+	PreserveLineBreaks bool
+
+	// ListMarkers が true の場合、箇条書き・段落番号（w:numPr）の付いた段落の先頭に、
+	// word/numbering.xml の書式に従った番号や記号（"1."、"a."、"i."、"•" など）を付け、レベルごとに2スペース字下げする
+	// numbering.xml がない場合や定義が見つからない場合は "-" を付ける
+	ListMarkers bool
+
+	// StripInvisibleChars が true の場合、検索の妨げになるソフトハイフン（U+00AD）・ゼロ幅スペース（U+200B）・
+	// BOM（U+FEFF）などの不可視文字を除去する（デフォルトは以前の出力と同じく残す）
+	StripInvisibleChars bool
+
+	// FromHeading が空でない場合、テキストがこれと一致する見出しの段落から後ろのみを抽出する（見つからない場合はErrHeadingNotFound）
+	// ToHeading が空でない場合、テキストがこれと一致する見出しの段落の手前までを抽出する（見つからない場合は末尾まで）
+	// 見出しは見出しスタイル（"heading 1" などの名前またはアウトラインレベルを持つスタイル）の段落で、
+	// テキストは前後の空白を除き、大文字と小文字を区別せずに比較する
+	FromHeading string
+	ToHeading   string
+
+	// IncludeImagePlaceholders が true の場合、本文中の画像（w:drawing / w:pict）の位置に
+	// 文書内の順番で番号を付けた "[image 1]"、"[image 2]" を出力する
+	IncludeImagePlaceholders bool
+
+	// PrependMetadata が true の場合、出力の先頭にコアプロパティ（docProps/core.xml）のタイトル・作成者・作成日時を
+	// "Title: 値" の行として追加する（空の項目は出力しない）
+	PrependMetadata bool
 }
 
+// docxImageMarker はランのテキスト内の画像の位置を示す印
+// XMLのテキストにはNUL文字が現れないため、本文の文字と区別できる
+const docxImageMarker = "\x00"
+
+// ErrHeadingNotFound はDOCXParserのFromHeadingに一致する見出しが文書にない場合に返すエラー
+var ErrHeadingNotFound = errors.New("heading not found")
+
 // SupportedExtensions はサポートする拡張子を返す
 func (p *DOCXParser) SupportedExtensions() []string {
-	return []string{".docx", ".doc"}
+	return []string{
+		".docx", ".doc",
+		".docm", // マクロ有効文書
+		".dotx", // テンプレート
+		".dotm", // マクロ有効テンプレート
+	}
+}
+
+// Capabilities はパーサーが抽出できる構造の種類を返す
+func (p *DOCXParser) Capabilities() ParserCapabilities {
+	return ParserCapabilities{Tables: true, Pages: true, Thumbnail: true}
+}
+
+// setPreserveFormatting はファクトリーのSetPreserveFormattingをPreserveLineBreaksに反映する
+func (p *DOCXParser) setPreserveFormatting(preserve bool) {
+	p.PreserveLineBreaks = preserve
+}
+
+// ParseFromFile はファイルパスからDOCXをパース
+func (p *DOCXParser) ParseFromFile(filePath string) (string, error) {
+	return parseFromFileCommon(p, filePath)
+}
+
+// ParseFromBytes はバイト配列からDOCXをパース
+func (p *DOCXParser) ParseFromBytes(data []byte) (string, error) {
+	return parseFromBytesCommon(p, data)
 }
 
 // ParseFromReader はio.ReaderAtからDOCXをパース
 func (p *DOCXParser) ParseFromReader(reader io.ReaderAt, size int64) (string, error) {
-	r, err := zip.NewReader(reader, size)
+	blocks, err := p.extractBlocks(reader, size)
 	if err != nil {
-		return "", fmt.Errorf("error reading Word file: %w", err)
+		return "", err
 	}
 
 	var allText strings.Builder
+	if p.PrependMetadata {
+		allText.WriteString(ooxmlMetadataHeader(reader, size))
+	}
+	for _, block := range blocks {
+		allText.WriteString(block.text)
+	}
+
+	if p.IncludeEmbeddedObjects && !p.outputLimitReached(allText.Len()) {
+		allText.WriteString(p.extractEmbeddedText(reader, size))
+	}
 
-	// word/document.xmlファイルを探す
+	return truncateOutput(allText.String(), p.MaxOutputBytes), nil
+}
+
+// EmbeddedObject はドキュメントに埋め込まれたオブジェクト
+type EmbeddedObject struct {
+	// Name はパッケージ内のパス（例: word/embeddings/Microsoft_Excel_Worksheet.xlsx）
+	Name        string
+	ContentType string
+	Size        int64
+}
+
+// docxEmbeddingsDir は埋め込みオブジェクトが格納されるディレクトリ
+const docxEmbeddingsDir = "word/embeddings/"
+
+// ParseEmbeddedObjects は埋め込みオブジェクトのファイル名とコンテンツタイプの一覧を返す
+func (p *DOCXParser) ParseEmbeddedObjects(reader io.ReaderAt, size int64) ([]EmbeddedObject, error) {
+	r, err := zip.NewReader(reader, size)
+	if err != nil {
+		return nil, fmt.Errorf("error reading Word file: %w", err)
+	}
+
+	contentTypes, err := readContentTypes(zipFileMap(r))
+	if err != nil {
+		return nil, err
+	}
+
+	var objects []EmbeddedObject
 	for _, f := range r.File {
-		if f.Name == "word/document.xml" {
-			rc, err := f.Open()
+		if !strings.HasPrefix(f.Name, docxEmbeddingsDir) || f.FileInfo().IsDir() {
+			continue
+		}
+		objects = append(objects, EmbeddedObject{
+			Name:        f.Name,
+			ContentType: contentTypes.lookup(f.Name),
+			Size:        int64(f.UncompressedSize64),
+		})
+	}
+
+	return objects, nil
+}
+
+// extractEmbeddedText はパース可能な埋め込みオブジェクトのテキストを見出し付きで返す
+func (p *DOCXParser) extractEmbeddedText(reader io.ReaderAt, size int64) string {
+	objects, err := p.ParseEmbeddedObjects(reader, size)
+	if err != nil {
+		log.Printf("Error reading embedded objects: %s", err)
+		return ""
+	}
+
+	factory := p.Factory
+	if factory == nil {
+		factory = NewDocumentParserFactory()
+	}
+
+	r, err := zip.NewReader(reader, size)
+	if err != nil {
+		return ""
+	}
+	files := zipFileMap(r)
+
+	var sb strings.Builder
+	for _, object := range objects {
+		parser, err := factory.GetParser(getFileExtension(path.Base(object.Name)))
+		if err != nil {
+			continue
+		}
+
+		data, err := readZipFile(files[object.Name])
+		if err != nil {
+			log.Printf("Error reading embedded object %s: %s", object.Name, err)
+			continue
+		}
+
+		text, err := parser.ParseFromBytes(data)
+		if err != nil {
+			log.Printf("Error parsing embedded object %s: %s", object.Name, err)
+			continue
+		}
+
+		sb.WriteString(fmt.Sprintf("\n[Embedded: %s]\n", path.Base(object.Name)))
+		sb.WriteString(text)
+	}
+
+	return sb.String()
+}
+
+// ParseWithPages はセクション区切り（w:sectPr）と明示的な改ページ（w:br w:type="page"）で分割し、
+// "Section 1", "Section 2" のようなキーのマップ形式で返す
+func (p *DOCXParser) ParseWithPages(reader io.ReaderAt, size int64) (map[string]string, error) {
+	blocks, err := p.extractBlocks(reader, size)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]string)
+	var section strings.Builder
+	flush := func() {
+		if strings.TrimSpace(section.String()) != "" {
+			result[fmt.Sprintf("Section %d", len(result)+1)] = section.String()
+		}
+		section.Reset()
+	}
+
+	for _, block := range blocks {
+		if block.breakBefore {
+			flush()
+		}
+		section.WriteString(block.text)
+		if block.breakAfter {
+			flush()
+		}
+	}
+	flush()
+
+	return result, nil
+}
+
+// tableOfContents は見出しの段落ごとに区切った目次を返す
+// 最初の見出しより前の本文はレベル0・名前なしの項目とし、見出しが1つもない場合はnilを返す
+func (p *DOCXParser) tableOfContents(reader io.ReaderAt, size int64) ([]TOCEntry, error) {
+	blocks, err := p.readBlocks(reader, size, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []TOCEntry
+	hasHeadings := false
+	for _, block := range blocks {
+		if block.headingLevel > 0 {
+			hasHeadings = true
+			entries = append(entries, TOCEntry{Name: block.heading, Level: block.headingLevel})
+		} else if len(entries) == 0 {
+			if strings.TrimSpace(block.text) == "" {
+				continue
+			}
+			entries = append(entries, TOCEntry{})
+		}
+		entries[len(entries)-1].CharCount += utf8.RuneCountInString(block.text)
+	}
+	if !hasHeadings {
+		return nil, nil
+	}
+	return entries, nil
+}
+
+// docxBlock は本文中の段落または表から抽出したテキスト
+type docxBlock struct {
+	text string
+	// breakBefore はブロックの前に改ページがあることを示す
+	breakBefore bool
+	// breakAfter はブロックの後にセクション区切りまたは改ページがあることを示す
+	breakAfter bool
+	// headingLevel は見出しの段落のレベル（1〜9）。見出し以外、または見出しを判定しない場合は0
+	headingLevel int
+	// heading は見出しの段落のテキスト（番号の印を付ける前）
+	heading string
+}
+
+// ErrEmptyBody は本文に段落や表が1つもない文書の場合に返すエラー
+// DOCXは本文（w:body）に段落や表がない場合、Pagesは本文にテキストのある段落がない場合に返す
+// DOCXの空の段落のみを含む文書はエラーにならず、空のテキストを返す
+var ErrEmptyBody = errors.New("document body is empty")
+
+// extractBlocks はword/document.xmlの本文から段落と表を順番に抽出する
+func (p *DOCXParser) extractBlocks(reader io.ReaderAt, size int64) ([]docxBlock, error) {
+	return p.readBlocks(reader, size, false)
+}
+
+// readBlocks はextractBlocksと同様に段落と表を抽出する
+// markHeadings が true の場合は見出しの段落にheadingLevelとheadingを設定する
+func (p *DOCXParser) readBlocks(reader io.ReaderAt, size int64, markHeadings bool) ([]docxBlock, error) {
+	r, err := zip.NewReader(reader, size)
+	if err != nil {
+		return nil, fmt.Errorf("error reading Word file: %w", err)
+	}
+
+	var blocks []docxBlock
+	files := zipFileMap(r)
+	mainPart := docxMainPart(files)
+	var numbering *docxNumbering
+	if p.ListMarkers {
+		numbering = readDocxNumbering(files, mainPart)
+	}
+	var headingStyles map[string]int
+	if markHeadings || p.FromHeading != "" || p.ToHeading != "" {
+		headingStyles = readDocxHeadingStyles(files, mainPart)
+	}
+	var headings *docxHeadingRange
+	if p.FromHeading != "" || p.ToHeading != "" {
+		headings = &docxHeadingRange{
+			from:    strings.TrimSpace(p.FromHeading),
+			to:      strings.TrimSpace(p.ToHeading),
+			styles:  headingStyles,
+			started: p.FromHeading == "",
+		}
+	}
+	hasBlocks, foundMainPart := false, false
+
+	// メイン文書パーツ（通常はword/document.xml）を探す
+	for _, f := range r.File {
+		if f.Name == mainPart {
+			foundMainPart = true
+			rc, err := openZipPart(f)
 			if err != nil {
-				return "", fmt.Errorf("error opening file %s: %w", f.Name, err)
+				return nil, err
 			}
 
 			// XMLをパース
@@ -41,7 +340,14 @@ func (p *DOCXParser) ParseFromReader(reader io.ReaderAt, size int64) (string, er
 				defer rc.Close()
 				decoder := xml.NewDecoder(rc)
 				inBody := false
+				total := 0
+				images := 0
 				for {
+					// MaxOutputBytesを超えた場合は以降の段落と表を読まない
+					if p.outputLimitReached(total) {
+						break
+					}
+
 					t, err := decoder.Token()
 					if err == io.EOF {
 						break
@@ -57,20 +363,57 @@ func (p *DOCXParser) ParseFromReader(reader io.ReaderAt, size int64) (string, er
 						}
 						if inBody {
 							if se.Name.Local == "p" {
-								var p DocxParagraph
-								if err := decoder.DecodeElement(&p, &se); err != nil {
+								var para DocxParagraph
+								if err := decoder.DecodeElement(&para, &se); err != nil {
 									return err
 								}
-								text := extractTextFromParagraph(p)
-								if text != "" {
-									allText.WriteString(text + "\n")
+								hasBlocks = true
+								block := p.paragraphBlock(para)
+								headingText := strings.TrimSpace(block.text)
+								if numbering != nil {
+									marker := numbering.marker(para.Properties.Numbering)
+									if marker != "" && block.text != "" {
+										block.text = marker + block.text
+									}
 								}
+								block.text = numberDocxImages(block.text, &images)
+								if markHeadings && headingText != "" {
+									block.headingLevel = docxHeadingLevel(para.Properties, headingStyles)
+									if block.headingLevel > 0 {
+										block.heading = headingText
+									}
+								}
+								if headings != nil {
+									if headings.isHeading(para.Properties) {
+										headings.heading(headingText)
+									}
+									if headings.ended {
+										return nil
+									}
+									if !headings.started {
+										continue
+									}
+								}
+								blocks = append(blocks, block)
+								total += len(block.text)
 							} else if se.Name.Local == "tbl" {
 								var tbl DocxTable
 								if err := decoder.DecodeElement(&tbl, &se); err != nil {
 									return err
 								}
-								allText.WriteString(extractTextFromTable(tbl))
+								hasBlocks = true
+								block := docxBlock{text: numberDocxImages(p.extractTextFromTable(tbl), &images)}
+								if headings != nil && !headings.started {
+									continue
+								}
+								blocks = append(blocks, block)
+								total += len(block.text)
+							} else if se.Name.Local == "sdtPr" {
+								// ブロックレベルのコンテンツコントロール（w:sdt）はプロパティを読み飛ばし、
+								// w:sdtContent内の段落と表は後続のトークンとして処理する
+								if err := decoder.Skip(); err != nil {
+									return err
+								}
 							}
 						}
 					case xml.EndElement:
@@ -83,27 +426,433 @@ func (p *DOCXParser) ParseFromReader(reader io.ReaderAt, size int64) (string, er
 			}()
 
 			if err != nil {
-				return "", err
+				return nil, err
 			}
 
 			break // document.xmlは1つなので見つけたら終了
 		}
 	}
 
-	return allText.String(), nil
+	if !foundMainPart {
+		return nil, fmt.Errorf("%s not found", mainPart)
+	}
+	if !hasBlocks {
+		return nil, ErrEmptyBody
+	}
+	if headings != nil && !headings.started {
+		return nil, fmt.Errorf("%w: %s", ErrHeadingNotFound, p.FromHeading)
+	}
+
+	return blocks, nil
+}
+
+// numberDocxImages はテキスト内の画像の印を "[image N]" に置き換える
+// countは文書内でこれまでに置き換えた画像の数で、置き換えた分だけ増やす
+func numberDocxImages(text string, count *int) string {
+	if !strings.Contains(text, docxImageMarker) {
+		return text
+	}
+	var sb strings.Builder
+	for {
+		before, after, found := strings.Cut(text, docxImageMarker)
+		sb.WriteString(before)
+		if !found {
+			break
+		}
+		*count++
+		fmt.Fprintf(&sb, "[image %d]", *count)
+		text = after
+	}
+	return sb.String()
+}
+
+// paragraphBlock は段落をブロックに変換する
+// 改ページが段落の先頭にある場合は段落の前、途中にある場合は段落の後で区切る
+func (p *DOCXParser) paragraphBlock(para DocxParagraph) docxBlock {
+	var block docxBlock
+	if text := p.extractTextFromParagraph(para); text != "" {
+		block.text = text + "\n"
+	}
+
+	seenText := false
+	for _, run := range para.Runs {
+		if run.hasPageBreak() {
+			if seenText {
+				block.breakAfter = true
+			} else {
+				block.breakBefore = true
+			}
+		}
+		if run.Text.Content != "" {
+			seenText = true
+		}
+	}
+
+	if para.Properties.SectionProperties != nil {
+		block.breakAfter = true
+	}
+
+	return block
+}
+
+// PageCount はセクション数を返す
+// DOCXはページ情報を持たないため、セクション区切り（w:sectPr）の数をページ数の目安とする
+func (p *DOCXParser) PageCount(reader io.ReaderAt, size int64) (int, error) {
+	r, err := zip.NewReader(reader, size)
+	if err != nil {
+		return 0, fmt.Errorf("error reading Word file: %w", err)
+	}
+
+	files := zipFileMap(r)
+	f, ok := files[docxMainPart(files)]
+	if !ok {
+		return 1, nil
+	}
+
+	rc, err := openZipPart(f)
+	if err != nil {
+		return 0, err
+	}
+	defer rc.Close()
+
+	sections := 0
+	decoder := xml.NewDecoder(rc)
+	for {
+		t, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, fmt.Errorf("error parsing XML: %w", err)
+		}
+		if se, ok := t.(xml.StartElement); ok && se.Name.Local == "sectPr" {
+			sections++
+		}
+	}
+
+	return max(sections, 1), nil
 }
 
 // WordのXML構造を表現する構造体
 type DocxText struct {
 	Content string `xml:",chardata"`
+	// Space はxml:space属性（"preserve" の場合は前後の空白も本文の一部）
+	Space string `xml:"space,attr"`
+}
+
+// value はテキストを返す
+// xml:space="preserve" でない場合、改行を含む前後の空白はXMLの整形（インデント）によるものとして除く
+// 改行を含まない空白は、xml:spaceを付けずにラン間の区切りを書く生成元があるため残す
+func (t DocxText) value() string {
+	if t.Space == "preserve" {
+		return t.Content
+	}
+	text := t.Content
+	if trimmed := strings.TrimLeft(text, " \t\r\n"); strings.ContainsAny(text[:len(text)-len(trimmed)], "\r\n") {
+		text = trimmed
+	}
+	if trimmed := strings.TrimRight(text, " \t\r\n"); strings.ContainsAny(text[len(trimmed):], "\r\n") {
+		text = trimmed
+	}
+	return text
 }
 
 type DocxRun struct {
-	Text DocxText `xml:"t"`
+	Properties  DocxRunProperties `xml:"rPr"`
+	Text        DocxText          `xml:"t"`
+	DeletedText DocxText          `xml:"delText"`
+	Breaks      []DocxBreak       `xml:"br"`
+
+	// formatted はテキスト・改行・タブ・画像の印を文書内の順番で結合したもの（PreserveLineBreaks用）
+	formatted string
+	// imageText は画像を含むランのテキストに画像の印（docxImageMarker）を挿入したもの（IncludeImagePlaceholders用）
+	imageText string
+}
+
+// UnmarshalXML はテキストと改行・タブの順番を保持してランをデコードする
+// ラン内に複数のw:tがある場合はTextに連結する
+func (r *DocxRun) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var formatted, imageText strings.Builder
+	hasImage := false
+	err := walkDocxChildren(d, func(se xml.StartElement) error {
+		switch se.Name.Local {
+		case "rPr":
+			return d.DecodeElement(&r.Properties, &se)
+		case "t", "delText":
+			var text DocxText
+			if err := d.DecodeElement(&text, &se); err != nil {
+				return err
+			}
+			if se.Name.Local == "t" {
+				r.Text.Content += text.value()
+				imageText.WriteString(text.value())
+			} else {
+				r.DeletedText.Content += text.value()
+			}
+			formatted.WriteString(text.value())
+		case "drawing", "pict", "AlternateContent":
+			// mc:AlternateContentはw:drawingとその代替のw:pictを持つため、まとめて1つの画像とする
+			hasImage = true
+			formatted.WriteString(docxImageMarker)
+			imageText.WriteString(docxImageMarker)
+			return d.Skip()
+		case "br":
+			var br DocxBreak
+			if err := d.DecodeElement(&br, &se); err != nil {
+				return err
+			}
+			r.Breaks = append(r.Breaks, br)
+			if br.Type == "" || br.Type == "textWrapping" {
+				formatted.WriteString("\n")
+			}
+		case "cr":
+			formatted.WriteString("\n")
+			return d.Skip()
+		case "tab":
+			formatted.WriteString("\t")
+			return d.Skip()
+		default:
+			return d.Skip()
+		}
+		return nil
+	})
+	r.formatted = formatted.String()
+	if hasImage {
+		r.imageText = imageText.String()
+	}
+	return err
+}
+
+// hasPageBreak は改ページを含むランかどうかを判定する
+func (r DocxRun) hasPageBreak() bool {
+	for _, br := range r.Breaks {
+		if br.Type == "page" {
+			return true
+		}
+	}
+	return false
+}
+
+// DocxRunProperties はランの書式（w:rPr）のうち、テキストの強調に関わるもの
+// Wordはスペルチェックや編集の履歴（rsid）で同じ書式の単語を複数のランに分けるため、書式が同じ隣接するランは結合して扱う
+type DocxRunProperties struct {
+	Bold          DocxToggle `xml:"b"`
+	Italic        DocxToggle `xml:"i"`
+	Strike        DocxToggle `xml:"strike"`
+	Underline     docxVal    `xml:"u"`
+	VerticalAlign docxVal    `xml:"vertAlign"`
+}
+
+// DocxToggle はオン/オフの書式（w:b など）
+// 要素があればオンで、w:val が "0"・"false"・"off" の場合はオフ
+type DocxToggle struct {
+	Present bool
+	Value   string
+}
+
+// UnmarshalXML は要素の存在とw:val属性を読み込む
+func (t *DocxToggle) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	t.Present = true
+	for _, attr := range start.Attr {
+		if attr.Name.Local == "val" {
+			t.Value = attr.Value
+		}
+	}
+	return d.Skip()
+}
+
+// On は書式がオンかどうかを返す
+func (t DocxToggle) On() bool {
+	if !t.Present {
+		return false
+	}
+	switch strings.ToLower(t.Value) {
+	case "0", "false", "off":
+		return false
+	}
+	return true
+}
+
+// sameFormatting はテキストの強調に関わる書式が同じかどうかを返す
+func (p DocxRunProperties) sameFormatting(other DocxRunProperties) bool {
+	return p.Bold.On() == other.Bold.On() &&
+		p.Italic.On() == other.Italic.On() &&
+		p.Strike.On() == other.Strike.On() &&
+		p.Underline.Val == other.Underline.Val &&
+		p.VerticalAlign.Val == other.VerticalAlign.Val
+}
+
+// mergeDocxRun はbの内容をaの後ろに連結したランを返す（書式はaのものを使う）
+func mergeDocxRun(a, b DocxRun) DocxRun {
+	merged := a
+	merged.Text.Content = a.Text.Content + b.Text.Content
+	merged.DeletedText.Content = a.DeletedText.Content + b.DeletedText.Content
+	merged.Breaks = append(append([]DocxBreak(nil), a.Breaks...), b.Breaks...)
+	merged.formatted = a.formatted + b.formatted
+	if a.imageText != "" || b.imageText != "" {
+		merged.imageText = a.textWithImages() + b.textWithImages()
+	}
+	return merged
+}
+
+// textWithImages は画像の印を含むランのテキストを返す
+func (r DocxRun) textWithImages() string {
+	if r.imageText != "" {
+		return r.imageText
+	}
+	return r.Text.Content
+}
+
+// coalesceDocxRuns は書式が同じ隣接するランを1つのランに結合する
+func coalesceDocxRuns(runs []DocxRun) []DocxRun {
+	var result []DocxRun
+	for _, run := range runs {
+		if n := len(result); n > 0 && result[n-1].Properties.sameFormatting(run.Properties) {
+			result[n-1] = mergeDocxRun(result[n-1], run)
+			continue
+		}
+		result = append(result, run)
+	}
+	return result
+}
+
+// coalesceDocxSegments は段落内の書式が同じ隣接するランを結合する（変更履歴の中のランも同様に結合する）
+// 変更履歴どうしは結合しないため、別々のw:insは別々の "[ins: ...]" として出力される
+func coalesceDocxSegments(segments []docxSegment) []docxSegment {
+	var result []docxSegment
+	for _, segment := range segments {
+		n := len(result)
+		if n > 0 && segment.run != nil && result[n-1].run != nil && result[n-1].run.Properties.sameFormatting(segment.run.Properties) {
+			merged := mergeDocxRun(*result[n-1].run, *segment.run)
+			result[n-1].run = &merged
+			continue
+		}
+		if segment.revision != nil {
+			revision := *segment.revision
+			revision.Runs = coalesceDocxRuns(revision.Runs)
+			segment.revision = &revision
+		}
+		result = append(result, segment)
+	}
+	return result
+}
+
+type DocxBreak struct {
+	Type string `xml:"type,attr"`
 }
 
 type DocxParagraph struct {
-	Runs []DocxRun `xml:"r"`
+	Properties DocxParagraphProperties `xml:"pPr"`
+	Runs       []DocxRun               `xml:"r"`
+	Insertions []DocxRevision          `xml:"ins"`
+	Deletions  []DocxRevision          `xml:"del"`
+
+	// segments はランと変更履歴を文書内の順番で保持する
+	segments []docxSegment
+}
+
+// DocxRevision は変更履歴（w:ins / w:del）
+type DocxRevision struct {
+	Author string    `xml:"author,attr"`
+	Date   string    `xml:"date,attr"`
+	Runs   []DocxRun `xml:"r"`
+}
+
+// docxSegment は段落内のランまたは変更履歴
+type docxSegment struct {
+	run      *DocxRun
+	revision *DocxRevision
+	// deleted は削除の変更履歴であることを示す
+	deleted bool
+}
+
+// UnmarshalXML はランと変更履歴の順番を保持して段落をデコードする
+func (p *DocxParagraph) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	return walkDocxChildren(d, func(se xml.StartElement) error {
+		switch se.Name.Local {
+		case "pPr":
+			return d.DecodeElement(&p.Properties, &se)
+		case "r":
+			var run DocxRun
+			if err := d.DecodeElement(&run, &se); err != nil {
+				return err
+			}
+			p.Runs = append(p.Runs, run)
+			p.segments = append(p.segments, docxSegment{run: &run})
+		case "ins", "del":
+			var revision DocxRevision
+			if err := d.DecodeElement(&revision, &se); err != nil {
+				return err
+			}
+			deleted := se.Name.Local == "del"
+			if deleted {
+				p.Deletions = append(p.Deletions, revision)
+			} else {
+				p.Insertions = append(p.Insertions, revision)
+			}
+			p.segments = append(p.segments, docxSegment{revision: &revision, deleted: deleted})
+		default:
+			return d.Skip()
+		}
+		return nil
+	})
+}
+
+// walkDocxChildren は要素の子要素を順番にhandleへ渡す
+// コンテンツコントロール（w:sdt）はw:sdtContentの中身を親の子要素として扱い、w:sdtPrは読み飛ばす
+// ハイパーリンク（w:hyperlink）、スマートタグ（w:smartTag）、カスタムXML（w:customXml）、単純フィールド（w:fldSimple）、
+// 書字方向（w:dir / w:bdo）も同様に中身を親の子要素として扱う（Strict OOXMLを出力するツールはランをこれらで囲むことが多い）
+// handleは渡された要素をDecodeElementまたはSkipで読み終える必要がある
+func walkDocxChildren(d *xml.Decoder, handle func(se xml.StartElement) error) error {
+	depth := 0
+	for {
+		t, err := d.Token()
+		if err != nil {
+			return err
+		}
+
+		switch se := t.(type) {
+		case xml.StartElement:
+			switch se.Name.Local {
+			case "sdt", "sdtContent", "hyperlink", "smartTag", "customXml", "fldSimple", "dir", "bdo":
+				depth++
+			case "sdtPr", "sdtEndPr", "smartTagPr", "customXmlPr":
+				if err := d.Skip(); err != nil {
+					return err
+				}
+			default:
+				if err := handle(se); err != nil {
+					return err
+				}
+			}
+		case xml.EndElement:
+			if depth == 0 {
+				return nil
+			}
+			depth--
+		}
+	}
+}
+
+// DocxParagraphProperties は段落のプロパティ
+// 段落内のw:sectPrはその段落でセクションが終わることを示す
+// w:numPrは箇条書き・段落番号の定義（numbering.xmlのw:num）とレベルを示す
+type DocxParagraphProperties struct {
+	SectionProperties *struct{}                `xml:"sectPr"`
+	Numbering         *DocxNumberingProperties `xml:"numPr"`
+	Style             *docxVal                 `xml:"pStyle"`
+	OutlineLevel      *docxVal                 `xml:"outlineLvl"`
+}
+
+// DocxNumberingProperties は段落の箇条書き・段落番号（w:numPr）
+type DocxNumberingProperties struct {
+	Level docxVal `xml:"ilvl"`
+	NumID docxVal `xml:"numId"`
+}
+
+// docxVal はw:val属性のみを持つ要素
+type docxVal struct {
+	Val string `xml:"val,attr"`
 }
 
 // テーブル構造体
@@ -119,6 +868,51 @@ type DocxTableCell struct {
 	Paragraphs []DocxParagraph `xml:"p"`
 }
 
+// UnmarshalXML はコンテンツコントロールで囲まれた行も含めて表をデコードする
+func (t *DocxTable) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	return walkDocxChildren(d, func(se xml.StartElement) error {
+		if se.Name.Local != "tr" {
+			return d.Skip()
+		}
+		var row DocxTableRow
+		if err := d.DecodeElement(&row, &se); err != nil {
+			return err
+		}
+		t.Rows = append(t.Rows, row)
+		return nil
+	})
+}
+
+// UnmarshalXML はコンテンツコントロールで囲まれたセルも含めて行をデコードする
+func (r *DocxTableRow) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	return walkDocxChildren(d, func(se xml.StartElement) error {
+		if se.Name.Local != "tc" {
+			return d.Skip()
+		}
+		var cell DocxTableCell
+		if err := d.DecodeElement(&cell, &se); err != nil {
+			return err
+		}
+		r.Cells = append(r.Cells, cell)
+		return nil
+	})
+}
+
+// UnmarshalXML はコンテンツコントロールで囲まれた段落も含めてセルをデコードする
+func (c *DocxTableCell) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	return walkDocxChildren(d, func(se xml.StartElement) error {
+		if se.Name.Local != "p" {
+			return d.Skip()
+		}
+		var para DocxParagraph
+		if err := d.DecodeElement(&para, &se); err != nil {
+			return err
+		}
+		c.Paragraphs = append(c.Paragraphs, para)
+		return nil
+	})
+}
+
 // ParseDocxToString は後方互換性のための既存メソッド
 func ParseDocxToString(docxFilePath string) string {
 	parser := &DOCXParser{}
@@ -129,29 +923,435 @@ func ParseDocxToString(docxFilePath string) string {
 	return result
 }
 
-func extractTextFromParagraph(p DocxParagraph) string {
+func (p *DOCXParser) extractTextFromParagraph(para DocxParagraph) string {
 	var paragraphText strings.Builder
-	for _, run := range p.Runs {
-		paragraphText.WriteString(run.Text.Content)
+
+	// UnmarshalXMLを経由せずに作られた段落はランのみを使う
+	if para.segments == nil {
+		for _, run := range coalesceDocxRuns(para.Runs) {
+			paragraphText.WriteString(p.runText(run, false))
+		}
+		return paragraphText.String()
+	}
+
+	for _, segment := range coalesceDocxSegments(para.segments) {
+		if segment.run != nil {
+			paragraphText.WriteString(p.runText(*segment.run, false))
+		} else {
+			paragraphText.WriteString(p.revisionText(*segment.revision, segment.deleted))
+		}
 	}
 	return paragraphText.String()
 }
 
-func extractTextFromTable(tbl DocxTable) string {
-	var sb strings.Builder
+// runText はランのテキストを返す（deletedの場合は削除されたテキスト）
+// PreserveLineBreaks が true の場合は改行とタブも含め、IncludeImagePlaceholders が true の場合は画像の印を残し、StripInvisibleChars が true の場合は不可視文字を除去する
+func (p *DOCXParser) runText(run DocxRun, deleted bool) string {
+	var text string
+	switch {
+	case p.PreserveLineBreaks && run.formatted != "":
+		text = run.formatted
+	case deleted:
+		text = run.DeletedText.Content
+	case p.IncludeImagePlaceholders && run.imageText != "":
+		text = run.imageText
+	default:
+		text = run.Text.Content
+	}
+	if !p.IncludeImagePlaceholders {
+		text = strings.ReplaceAll(text, docxImageMarker, "")
+	}
+	if p.StripInvisibleChars {
+		text = stripInvisibleChars(text)
+	}
+	return text
+}
+
+// revisionText は変更履歴のテキストをAcceptRevisions / ShowRevisions / AnnotateRevisions に従って変換する
+func (p *DOCXParser) revisionText(revision DocxRevision, deleted bool) string {
+	var text strings.Builder
+	for _, run := range revision.Runs {
+		text.WriteString(p.runText(run, deleted))
+	}
+
+	if p.AcceptRevisions {
+		if deleted {
+			return ""
+		}
+		return text.String()
+	}
+	if !p.ShowRevisions || text.Len() == 0 {
+		return ""
+	}
+
+	label := "ins"
+	if deleted {
+		label = "del"
+	}
+	if p.AnnotateRevisions {
+		if revision.Author != "" {
+			label += " by " + revision.Author
+		}
+		// 日付はISO 8601形式（2024-01-01T00:00:00Z）のため日付部分のみを使う
+		if date, _, _ := strings.Cut(revision.Date, "T"); date != "" {
+			label += " " + date
+		}
+	}
+	return fmt.Sprintf("[%s: %s]", label, text.String())
+}
+
+// extractTextFromTable は表を行ごとのテキストに変換する
+// 行の要素はセル（FlattenTableCellsの場合は空でない段落）で、全てのセルが空白のみの（FlattenTableCellsの場合は要素がない）行は除外する
+func (p *DOCXParser) extractTextFromTable(tbl DocxTable) string {
+	rowSeparator := p.TableRowSeparator
+	if rowSeparator == "" {
+		rowSeparator = "\n"
+	}
+
+	var rows []string
 	for _, row := range tbl.Rows {
-		rowTexts := []string{}
+		var rowTexts []string
+		hasText := false
 		for _, cell := range row.Cells {
-			for _, p := range cell.Paragraphs {
-				text := extractTextFromParagraph(p)
-				if text != "" {
-					rowTexts = append(rowTexts, text)
+			var paragraphs []string
+			for _, para := range cell.Paragraphs {
+				if text := p.extractTextFromParagraph(para); text != "" {
+					paragraphs = append(paragraphs, text)
 				}
 			}
+			if p.FlattenTableCells {
+				rowTexts = append(rowTexts, paragraphs...)
+				hasText = hasText || len(paragraphs) > 0
+				continue
+			}
+			cellText := strings.Join(paragraphs, " ")
+			rowTexts = append(rowTexts, cellText)
+			hasText = hasText || strings.TrimSpace(cellText) != ""
 		}
-		if len(rowTexts) > 0 {
-			sb.WriteString(strings.Join(rowTexts, "\t") + "\n")
+		if hasText {
+			rows = append(rows, strings.Join(rowTexts, p.cellSeparator("\t")))
 		}
 	}
-	return sb.String()
+
+	if len(rows) == 0 {
+		return ""
+	}
+	return strings.Join(rows, rowSeparator) + "\n"
+}
+
+// docxDocumentPart と docxNumberingPart は、リレーションシップで見つからない場合に使う
+// メイン文書パーツと箇条書き・段落番号の定義を持つパーツのパス
+const (
+	docxDocumentPart  = "word/document.xml"
+	docxNumberingPart = "word/numbering.xml"
+)
+
+// docxMainPart はパッケージのリレーションシップ（_rels/.rels）からメイン文書パーツのパスを返す
+// Strict OOXML（ISO/IEC 29500 Strict）ではリレーションシップの種類がhttp://purl.oclc.org/ooxml/ で始まるため、
+// 種類の末尾（"/officeDocument"）で判定する
+func docxMainPart(files map[string]*zip.File) string {
+	for _, part := range relatedParts(files, "", "/officeDocument") {
+		if _, ok := files[part]; ok {
+			return part
+		}
+	}
+	return docxDocumentPart
+}
+
+// docxNumberingXML はword/numbering.xmlの構造
+type docxNumberingXML struct {
+	AbstractNums []struct {
+		ID     string         `xml:"abstractNumId,attr"`
+		Levels []docxNumLevel `xml:"lvl"`
+	} `xml:"abstractNum"`
+	Nums []struct {
+		ID            string  `xml:"numId,attr"`
+		AbstractNumID docxVal `xml:"abstractNumId"`
+		Overrides     []struct {
+			Level int     `xml:"ilvl,attr"`
+			Start docxVal `xml:"startOverride"`
+		} `xml:"lvlOverride"`
+	} `xml:"num"`
+}
+
+// docxNumLevel は箇条書き・段落番号の1レベル分の書式
+type docxNumLevel struct {
+	Level  int     `xml:"ilvl,attr"`
+	Start  docxVal `xml:"start"`
+	Format docxVal `xml:"numFmt"`
+	Text   docxVal `xml:"lvlText"`
+}
+
+// docxNumbering はnumIdごとのレベルの書式と、段落番号のカウンター
+type docxNumbering struct {
+	// levels はnumIdごとの、レベル番号をキーとした書式（abstractNumIdを解決し、startOverrideを反映したもの）
+	levels map[string]map[int]docxNumLevel
+	// counters はnumIdごとの、各レベルの現在の番号
+	counters map[string]*docxListCounters
+}
+
+// docxListCounters は1つのリストの各レベルの現在の番号
+// 開始番号が0のリストもあるため、番号を使い始めたかどうかは番号の値ではなくstartedで判定する
+type docxListCounters struct {
+	values  [docxMaxListLevel]int
+	started [docxMaxListLevel]bool
+}
+
+// docxHeadingRange はFromHeading・ToHeadingで指定された見出しの範囲を追跡する
+type docxHeadingRange struct {
+	from, to string
+	// styles は見出しスタイルのスタイルIDと見出しのレベル
+	styles map[string]int
+	// started はFromHeadingの見出しが見つかったこと、ended はToHeadingの見出しが見つかったことを示す
+	started, ended bool
+}
+
+// isHeading は段落が見出し（見出しスタイル、または本文以外のアウトラインレベル）かどうかを返す
+func (r *docxHeadingRange) isHeading(props DocxParagraphProperties) bool {
+	return docxHeadingLevel(props, r.styles) > 0
+}
+
+// docxHeadingLevel は段落の見出しのレベル（1〜9、見出しでない場合は0）を返す
+// 段落に直接指定されたアウトラインレベルを優先し、なければ段落のスタイルのレベルとする
+func docxHeadingLevel(props DocxParagraphProperties, styles map[string]int) int {
+	if props.OutlineLevel != nil {
+		if level, err := strconv.Atoi(props.OutlineLevel.Val); err == nil && level < 9 {
+			return level + 1
+		}
+		return 0
+	}
+	if props.Style == nil {
+		return 0
+	}
+	return styles[props.Style.Val]
+}
+
+// heading は見出しのテキストで範囲の開始と終了を更新する
+func (r *docxHeadingRange) heading(text string) {
+	switch {
+	case !r.started:
+		r.started = strings.EqualFold(text, r.from)
+	case r.to != "" && strings.EqualFold(text, r.to):
+		r.ended = true
+	}
+}
+
+// docxStylesXML はword/styles.xmlのうち見出しの判定に使う部分
+type docxStylesXML struct {
+	Styles []struct {
+		Type         string   `xml:"type,attr"`
+		ID           string   `xml:"styleId,attr"`
+		Name         docxVal  `xml:"name"`
+		OutlineLevel *docxVal `xml:"pPr>outlineLvl"`
+	} `xml:"style"`
+}
+
+// readDocxHeadingStyles はメイン文書パーツが参照するスタイル定義（通常はword/styles.xml）から見出しスタイルのIDとレベルを返す
+// スタイルIDは言語によって異なる（日本語版のWordでは "1" など）ため、名前（"heading 1"、"Title"）とアウトラインレベルで判定する
+// スタイル定義がない場合は "Heading1" のような英語のスタイルIDのみを見出しとする。タイトルのレベルは1とする
+func readDocxHeadingStyles(files map[string]*zip.File, mainPart string) map[string]int {
+	styles := make(map[string]int)
+	for i := 1; i <= 9; i++ {
+		styles["Heading"+strconv.Itoa(i)] = i
+	}
+	styles["Title"] = 1
+
+	partName := "word/styles.xml"
+	if parts := relatedParts(files, mainPart, "/styles"); len(parts) > 0 {
+		partName = parts[0]
+	}
+	f, ok := files[partName]
+	if !ok {
+		return styles
+	}
+	data, err := readZipFile(f)
+	if err != nil {
+		log.Printf("Error reading %s: %s", partName, err)
+		return styles
+	}
+	var def docxStylesXML
+	if err := xml.Unmarshal(data, &def); err != nil {
+		log.Printf("Error parsing XML for %s: %s", partName, err)
+		return styles
+	}
+
+	for _, style := range def.Styles {
+		if style.Type != "" && style.Type != "paragraph" {
+			continue
+		}
+		name := strings.ToLower(style.Name.Val)
+		switch {
+		case style.OutlineLevel != nil:
+			if level, err := strconv.Atoi(style.OutlineLevel.Val); err == nil && level < 9 {
+				styles[style.ID] = level + 1
+			}
+		case strings.HasPrefix(name, "heading"):
+			level, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(name, "heading")))
+			if err != nil || level < 1 || level > 9 {
+				level = 1
+			}
+			styles[style.ID] = level
+		case name == "title":
+			styles[style.ID] = 1
+		}
+	}
+	return styles
+}
+
+// readDocxNumbering はメイン文書パーツが参照する箇条書き・段落番号の定義（通常はword/numbering.xml）を読み込む
+// ファイルがない場合や読み込めない場合は、定義のないdocxNumbering（全て "-" になる）を返す
+func readDocxNumbering(files map[string]*zip.File, mainPart string) *docxNumbering {
+	numbering := &docxNumbering{
+		levels:   make(map[string]map[int]docxNumLevel),
+		counters: make(map[string]*docxListCounters),
+	}
+
+	partName := docxNumberingPart
+	if parts := relatedParts(files, mainPart, "/numbering"); len(parts) > 0 {
+		partName = parts[0]
+	}
+	f, ok := files[partName]
+	if !ok {
+		return numbering
+	}
+	data, err := readZipFile(f)
+	if err != nil {
+		log.Printf("Error reading %s: %s", partName, err)
+		return numbering
+	}
+	var def docxNumberingXML
+	if err := xml.Unmarshal(data, &def); err != nil {
+		log.Printf("Error parsing XML for %s: %s", partName, err)
+		return numbering
+	}
+
+	abstract := make(map[string][]docxNumLevel)
+	for _, a := range def.AbstractNums {
+		abstract[a.ID] = a.Levels
+	}
+	for _, num := range def.Nums {
+		levels := make(map[int]docxNumLevel)
+		for _, level := range abstract[num.AbstractNumID.Val] {
+			levels[level.Level] = level
+		}
+		for _, override := range num.Overrides {
+			if level, ok := levels[override.Level]; ok && override.Start.Val != "" {
+				level.Start = override.Start
+				levels[override.Level] = level
+			}
+		}
+		numbering.levels[num.ID] = levels
+	}
+	return numbering
+}
+
+// docxMaxListLevel はWordの箇条書き・段落番号の最大のレベル数
+const docxMaxListLevel = 9
+
+// marker は段落の番号を1つ進め、字下げと番号・記号を含む接頭辞を返す（番号のない段落は空文字列）
+func (n *docxNumbering) marker(props *DocxNumberingProperties) string {
+	if props == nil || props.NumID.Val == "" || props.NumID.Val == "0" {
+		return ""
+	}
+	level, _ := strconv.Atoi(props.Level.Val)
+	level = min(max(level, 0), docxMaxListLevel-1)
+	indent := strings.Repeat("  ", level)
+
+	levels, ok := n.levels[props.NumID.Val]
+	def, defined := levels[level]
+	if !ok || !defined {
+		return indent + "- "
+	}
+
+	// このレベルの番号を進め、下位のレベルの番号をリセットする
+	counters, ok := n.counters[props.NumID.Val]
+	if !ok {
+		counters = &docxListCounters{}
+		n.counters[props.NumID.Val] = counters
+	}
+	for i := 0; i <= level; i++ {
+		if !counters.started[i] {
+			counters.started[i] = true
+			counters.values[i] = levelStart(levels[i]) - 1
+			if i < level {
+				counters.values[i]++
+			}
+		}
+	}
+	counters.values[level]++
+	for i := level + 1; i < docxMaxListLevel; i++ {
+		counters.values[i], counters.started[i] = 0, false
+	}
+
+	if def.Format.Val == "bullet" {
+		return indent + docxBulletText(def.Text.Val) + " "
+	}
+	if def.Format.Val == "none" {
+		return indent
+	}
+
+	text := def.Text.Val
+	if text == "" {
+		text = fmt.Sprintf("%%%d.", level+1)
+	}
+	// lvlTextの "%1"〜"%9" は各レベルの番号に置き換える
+	for i := docxMaxListLevel; i >= 1; i-- {
+		placeholder := "%" + strconv.Itoa(i)
+		if strings.Contains(text, placeholder) {
+			text = strings.ReplaceAll(text, placeholder, formatListNumber(counters.values[i-1], levels[i-1].Format.Val))
+		}
+	}
+	return indent + text + " "
+}
+
+// docxMaxListStart は開始番号の上限（Wordで指定できる最大値）
+const docxMaxListStart = 32767
+
+// levelStart はレベルの開始番号を返す（指定がない場合は1、0〜docxMaxListStartの範囲に収める）
+func levelStart(level docxNumLevel) int {
+	if start, err := strconv.Atoi(level.Start.Val); err == nil {
+		return min(max(start, 0), docxMaxListStart)
+	}
+	return 1
+}
+
+// docxBulletText は箇条書きの記号を返す
+// Symbol・Wingdingsフォントの私用領域の文字（U+F000〜U+F0FF）は表示できないため "•" に置き換える
+func docxBulletText(text string) string {
+	if text == "" {
+		return "•"
+	}
+	for _, r := range text {
+		if r >= 0xF000 && r <= 0xF0FF {
+			return "•"
+		}
+	}
+	return text
+}
+
+// formatListNumber は番号をnumFmtの形式（decimal、lowerLetter、upperRoman など）に変換する
+// 対応していない形式は10進数にする
+func formatListNumber(n int, format string) string {
+	switch format {
+	case "lowerLetter", "upperLetter":
+		// Wordも26を超えると "aa"、"bb" のように同じ文字を繰り返す
+		if n <= 0 {
+			break
+		}
+		if format == "lowerLetter" {
+			return strings.ToLower(toAlphabetic(n))
+		}
+		return toAlphabetic(n)
+	case "lowerRoman", "upperRoman":
+		if n <= 0 {
+			break
+		}
+		if format == "lowerRoman" {
+			return strings.ToLower(toRoman(n))
+		}
+		return toRoman(n)
+	case "decimalZero":
+		return fmt.Sprintf("%02d", n)
+	}
+	return strconv.Itoa(n)
 }
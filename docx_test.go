@@ -0,0 +1,386 @@
+package documentParser
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// buildTestZip はパスと内容のマップからzipのバイト列を作る（パスの昇順に格納する）
+func buildTestZip(t testing.TB, files map[string]string) []byte {
+	t.Helper()
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, name := range names {
+		fw, err := w.Create(name)
+		if err != nil {
+			t.Fatalf("Create(%s) error = %v", name, err)
+		}
+		if _, err := fw.Write([]byte(files[name])); err != nil {
+			t.Fatalf("Write(%s) error = %v", name, err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	return buf.Bytes()
+}
+
+// testDOCXNamespace はテスト用のDOCXのWordprocessingMLの名前空間
+const testDOCXNamespace = "http://schemas.openxmlformats.org/wordprocessingml/2006/main"
+
+// testDOCXDocument はw:bodyの中身からword/document.xmlの内容を作る
+func testDOCXDocument(body string) string {
+	return `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<w:document xmlns:w="` + testDOCXNamespace + `"><w:body>` + body + `</w:body></w:document>`
+}
+
+// buildTestDOCX はw:bodyの中身からword/document.xmlのみを持つDOCXを作る
+func buildTestDOCX(t testing.TB, body string) []byte {
+	t.Helper()
+	return buildTestZip(t, map[string]string{docxDocumentPart: testDOCXDocument(body)})
+}
+
+func FuzzDOCX(f *testing.F) {
+	f.Add(testDOCXDocument(`<w:p><w:r><w:t>hello</w:t></w:r></w:p>`))
+	f.Add(testDOCXDocument(`<w:tbl><w:tr><w:tc><w:p><w:r><w:t>cell</w:t></w:r></w:p></w:tc></w:tr></w:tbl>`))
+	f.Add(testDOCXDocument(`<w:sdt><w:sdtContent><w:p><w:ins><w:r><w:t>x</w:t></w:r></w:ins></w:p></w:sdtContent></w:sdt>`))
+	f.Add(testDOCXDocument(`<w:p><w:pPr><w:pStyle w:val="Heading1"/><w:numPr><w:ilvl w:val="99"/><w:numId w:val="1"/></w:numPr></w:pPr></w:p>`))
+	f.Add(`<!DOCTYPE d [<!ENTITY a "aaaa">]><w:document xmlns:w="` + testDOCXNamespace + `"><w:body><w:p><w:r><w:t>&a;</w:t></w:r></w:p>`)
+
+	f.Fuzz(func(t *testing.T, document string) {
+		data := buildTestZip(t, map[string]string{docxDocumentPart: document})
+		parser := &DOCXParser{}
+		// エラーは許容し、パニックしないことのみを確認する
+		parser.ParseFromBytes(data)
+	})
+}
+
+func TestDOCXParserPreservedSpaces(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "xml:space preserve between runs",
+			body: `<w:p><w:r><w:t xml:space="preserve">hello </w:t></w:r><w:r><w:t>world</w:t></w:r>` +
+				`<w:r><w:t xml:space="preserve"> again</w:t></w:r></w:p>`,
+			want: "hello world again\n",
+		},
+		{
+			name: "indentation between text nodes",
+			body: "<w:p>\n  <w:r>\n    <w:t>hello</w:t>\n  </w:r>\n  <w:r>\n    <w:t>world</w:t>\n  </w:r>\n</w:p>",
+			want: "helloworld\n",
+		},
+		{
+			name: "indentation between text nodes in one run",
+			body: "<w:p><w:r>\n  <w:t xml:space=\"preserve\">one </w:t>\n  <w:t>two</w:t>\n</w:r></w:p>",
+			want: "one two\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := &DOCXParser{}
+			got, err := parser.ParseFromBytes(buildTestDOCX(t, tt.body))
+			if err != nil {
+				t.Fatalf("ParseFromBytes() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseFromBytes() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCoalesceDocxSegments(t *testing.T) {
+	tests := []struct {
+		name      string
+		paragraph string
+		want      []string
+	}{
+		{
+			name: "fragmented runs",
+			paragraph: `<w:r w:rsidR="001"><w:rPr><w:b/></w:rPr><w:t>hel</w:t></w:r>` +
+				`<w:r w:rsidR="002"><w:rPr><w:b/><w:noProof/></w:rPr><w:t>lo</w:t></w:r>` +
+				`<w:r><w:rPr><w:b w:val="1"/></w:rPr><w:t xml:space="preserve"> world</w:t></w:r>`,
+			want: []string{"hello world"},
+		},
+		{
+			name: "different formatting",
+			paragraph: `<w:r><w:rPr><w:b/></w:rPr><w:t>bold</w:t></w:r>` +
+				`<w:r><w:t>plain</w:t></w:r>` +
+				`<w:r><w:rPr><w:i/></w:rPr><w:t>italic</w:t></w:r>` +
+				`<w:r><w:rPr><w:vertAlign w:val="superscript"/></w:rPr><w:t>2</w:t></w:r>`,
+			want: []string{"bold", "plain", "italic", "2"},
+		},
+		{
+			name: "explicitly off toggles match missing ones",
+			paragraph: `<w:r><w:rPr><w:b w:val="0"/><w:i w:val="false"/></w:rPr><w:t>a</w:t></w:r>` +
+				`<w:r><w:t>b</w:t></w:r>`,
+			want: []string{"ab"},
+		},
+		{
+			name: "fragmented revisions",
+			paragraph: `<w:ins w:author="A"><w:r><w:t>hel</w:t></w:r><w:r><w:t>lo</w:t></w:r></w:ins>` +
+				`<w:ins w:author="A"><w:r><w:t xml:space="preserve"> world</w:t></w:r></w:ins>` +
+				`<w:r><w:t>!</w:t></w:r>`,
+			want: []string{"ins:hello", "ins: world", "!"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var para DocxParagraph
+			data := `<w:p xmlns:w="` + testDOCXNamespace + `">` + tt.paragraph + `</w:p>`
+			if err := xml.Unmarshal([]byte(data), &para); err != nil {
+				t.Fatalf("xml.Unmarshal() error = %v", err)
+			}
+
+			var got []string
+			for _, segment := range coalesceDocxSegments(para.segments) {
+				if segment.run != nil {
+					got = append(got, segment.run.Text.Content)
+					continue
+				}
+				if len(segment.revision.Runs) != 1 {
+					t.Errorf("revision has %d runs after coalescing, want 1", len(segment.revision.Runs))
+				}
+				text := "ins:"
+				for _, run := range segment.revision.Runs {
+					text += run.Text.Content
+				}
+				got = append(got, text)
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("coalesceDocxSegments() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDOCXParserRevisions(t *testing.T) {
+	body := `<w:p><w:r><w:t xml:space="preserve">a </w:t></w:r>` +
+		`<w:ins w:author="Alice" w:date="2024-01-01T00:00:00Z"><w:r><w:t>hel</w:t></w:r><w:r><w:t>lo</w:t></w:r></w:ins>` +
+		`<w:ins w:author="Bob"><w:r><w:t xml:space="preserve"> world</w:t></w:r></w:ins>` +
+		`<w:del w:author="Alice"><w:r><w:delText xml:space="preserve"> old</w:delText></w:r></w:del>` +
+		`<w:r><w:t>!</w:t></w:r></w:p>`
+	data := buildTestDOCX(t, body)
+
+	tests := []struct {
+		name   string
+		parser *DOCXParser
+		want   string
+	}{
+		{"default omits revisions", &DOCXParser{}, "a !\n"},
+		{"accept", &DOCXParser{AcceptRevisions: true}, "a hello world!\n"},
+		{"show", &DOCXParser{ShowRevisions: true}, "a [ins: hello][ins:  world][del:  old]!\n"},
+		{"annotate", &DOCXParser{ShowRevisions: true, AnnotateRevisions: true}, "a [ins by Alice 2024-01-01: hello][ins by Bob:  world][del by Alice:  old]!\n"},
+		{"annotate without show", &DOCXParser{AnnotateRevisions: true}, "a !\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.parser.ParseFromBytes(data)
+			if err != nil {
+				t.Fatalf("ParseFromBytes() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseFromBytes() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDOCXParserStrictOOXML(t *testing.T) {
+	// Strict OOXMLは名前空間とリレーションシップの種類のURIが異なり、メイン文書パーツの名前も固定ではない
+	const strictNamespace = "http://purl.oclc.org/ooxml/wordprocessingml/main"
+	body := `<w:p><w:pPr><w:pStyle w:val="Heading1"/></w:pPr><w:r><w:t>Title</w:t></w:r></w:p>` +
+		`<w:p><w:r><w:t xml:space="preserve">strict </w:t></w:r><w:r><w:t>body</w:t></w:r></w:p>` +
+		`<w:tbl><w:tr><w:tc><w:p><w:r><w:t>cell</w:t></w:r></w:p></w:tc></w:tr></w:tbl>`
+
+	transitional := buildTestDOCX(t, body)
+	strict := buildTestZip(t, map[string]string{
+		"_rels/.rels": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+			`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://purl.oclc.org/ooxml/officeDocument/relationships/officeDocument" Target="word/document2.xml"/>` +
+			`</Relationships>`,
+		"word/document2.xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+			`<w:document xmlns:w="` + strictNamespace + `" w:conformance="strict"><w:body>` + body + `</w:body></w:document>`,
+	})
+
+	if got := docxMainPart(zipFileMap(mustZipReader(t, strict))); got != "word/document2.xml" {
+		t.Errorf("docxMainPart() = %q, want %q", got, "word/document2.xml")
+	}
+
+	parser := &DOCXParser{}
+	want, err := parser.ParseFromBytes(transitional)
+	if err != nil {
+		t.Fatalf("ParseFromBytes(transitional) error = %v", err)
+	}
+	got, err := parser.ParseFromBytes(strict)
+	if err != nil {
+		t.Fatalf("ParseFromBytes(strict) error = %v", err)
+	}
+	if got != want {
+		t.Errorf("ParseFromBytes(strict) = %q, want the transitional output %q", got, want)
+	}
+	if !strings.Contains(got, "strict body") || !strings.Contains(got, "cell") {
+		t.Errorf("ParseFromBytes(strict) = %q, want text from paragraphs and tables", got)
+	}
+}
+
+// mustZipReader はzipのバイト列からzip.Readerを作る
+func mustZipReader(t testing.TB, data []byte) *zip.Reader {
+	t.Helper()
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("zip.NewReader() error = %v", err)
+	}
+	return r
+}
+
+func TestDOCXParserInvisibleChars(t *testing.T) {
+	data := buildTestDOCX(t, "<w:p><w:r><w:t>soft\u00ADhyphen zero\u200Bwidth \uFEFFbom</w:t></w:r></w:p>")
+
+	tests := []struct {
+		name   string
+		parser *DOCXParser
+		want   string
+	}{
+		{"default", &DOCXParser{}, "soft\u00ADhyphen zero\u200Bwidth \uFEFFbom\n"},
+		{"strip", &DOCXParser{StripInvisibleChars: true}, "softhyphen zerowidth bom\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.parser.ParseFromBytes(data)
+			if err != nil {
+				t.Fatalf("ParseFromBytes() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseFromBytes() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDOCXParserListMarkersStart(t *testing.T) {
+	numbering := func(start string) string {
+		return `<w:numbering xmlns:w="` + testDOCXNamespace + `">` +
+			`<w:abstractNum w:abstractNumId="0">` +
+			`<w:lvl w:ilvl="0"><w:start w:val="` + start + `"/><w:numFmt w:val="decimal"/><w:lvlText w:val="%1."/></w:lvl>` +
+			`<w:lvl w:ilvl="1"><w:start w:val="0"/><w:numFmt w:val="lowerLetter"/><w:lvlText w:val="%1.%2"/></w:lvl>` +
+			`</w:abstractNum><w:num w:numId="1"><w:abstractNumId w:val="0"/></w:num></w:numbering>`
+	}
+	item := func(level int, text string) string {
+		return `<w:p><w:pPr><w:numPr><w:ilvl w:val="` + strconv.Itoa(level) + `"/><w:numId w:val="1"/></w:numPr></w:pPr>` +
+			`<w:r><w:t>` + text + `</w:t></w:r></w:p>`
+	}
+	body := item(0, "a") + item(0, "b") + item(1, "b1") + item(1, "b2") + item(0, "c")
+
+	tests := []struct {
+		name  string
+		start string
+		want  string
+	}{
+		{"start at zero", "0", "0. a\n1. b\n  1.0 b1\n  1.a b2\n2. c\n"},
+		{"start at one", "1", "1. a\n2. b\n  2.0 b1\n  2.a b2\n3. c\n"},
+		{"negative start", "-5", "0. a\n1. b\n  1.0 b1\n  1.a b2\n2. c\n"},
+		{"huge start", "2147483647", "32767. a\n32768. b\n  32768.0 b1\n  32768.a b2\n32769. c\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := buildTestZip(t, map[string]string{
+				docxDocumentPart:  testDOCXDocument(body),
+				docxNumberingPart: numbering(tt.start),
+			})
+			parser := &DOCXParser{ListMarkers: true}
+			got, err := parser.ParseFromBytes(data)
+			if err != nil {
+				t.Fatalf("ParseFromBytes() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseFromBytes() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDOCXParserTableLayout(t *testing.T) {
+	body := `<w:tbl>` +
+		`<w:tr><w:tc><w:p><w:r><w:t>a</w:t></w:r></w:p><w:p><w:r><w:t>b</w:t></w:r></w:p></w:tc><w:tc><w:p/></w:tc><w:tc><w:p><w:r><w:t>c</w:t></w:r></w:p></w:tc></w:tr>` +
+		`<w:tr><w:tc><w:p/></w:tc><w:tc><w:p/></w:tc><w:tc><w:p/></w:tc></w:tr>` +
+		`<w:tr><w:tc><w:p><w:r><w:t>d</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>e</w:t></w:r></w:p></w:tc><w:tc><w:p/></w:tc></w:tr>` +
+		`</w:tbl>`
+	data := buildTestDOCX(t, body)
+
+	tests := []struct {
+		name   string
+		parser *DOCXParser
+		want   string
+	}{
+		{"default", &DOCXParser{}, "a b\t\tc\nd\te\t\n"},
+		{"flatten", &DOCXParser{FlattenTableCells: true}, "a\tb\tc\nd\te\n"},
+		{"separators", &DOCXParser{BaseParser: BaseParser{CellSeparator: " | "}, TableRowSeparator: " / "}, "a b |  | c / d | e | \n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.parser.ParseFromBytes(data)
+			if err != nil {
+				t.Fatalf("ParseFromBytes() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseFromBytes() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDOCXParserBlockContentControl(t *testing.T) {
+	body := `<w:p><w:r><w:t>before</w:t></w:r></w:p>` +
+		`<w:sdt><w:sdtPr><w:alias w:val="Parties"/></w:sdtPr><w:sdtContent>` +
+		`<w:p><w:r><w:t>Parties</w:t></w:r></w:p>` +
+		`<w:tbl><w:tr><w:tc><w:p><w:r><w:t>Buyer</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>ACME</w:t></w:r></w:p></w:tc></w:tr>` +
+		`<w:tr><w:tc><w:p><w:r><w:t>Seller</w:t></w:r></w:p></w:tc><w:tc><w:sdt><w:sdtContent><w:p><w:r><w:t>Example Ltd</w:t></w:r></w:p></w:sdtContent></w:sdt></w:tc></w:tr></w:tbl>` +
+		`<w:sdt><w:sdtContent><w:p><w:r><w:t>nested</w:t></w:r></w:p></w:sdtContent></w:sdt>` +
+		`</w:sdtContent></w:sdt>` +
+		`<w:p><w:r><w:t>after</w:t></w:r></w:p>`
+
+	parser := &DOCXParser{}
+	got, err := parser.ParseFromBytes(buildTestDOCX(t, body))
+	if err != nil {
+		t.Fatalf("ParseFromBytes() error = %v", err)
+	}
+
+	want := "before\nParties\nBuyer\tACME\nSeller\tExample Ltd\nnested\nafter\n"
+	if got != want {
+		t.Errorf("ParseFromBytes() = %q, want %q", got, want)
+	}
+}
+
+func TestDOCXParserMissingDocumentPart(t *testing.T) {
+	parser := &DOCXParser{}
+	_, err := parser.ParseFromBytes(buildTestZip(t, map[string]string{"word/styles.xml": "<w:styles/>"}))
+	if err == nil || errors.Is(err, ErrEmptyBody) {
+		t.Errorf("ParseFromBytes() error = %v, want a missing part error", err)
+	}
+
+	_, err = parser.ParseFromBytes(buildTestDOCX(t, ""))
+	if !errors.Is(err, ErrEmptyBody) {
+		t.Errorf("ParseFromBytes(empty body) error = %v, want %v", err, ErrEmptyBody)
+	}
+}
@@ -0,0 +1,69 @@
+package documentParser
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrRemoteDocumentPointer はGoogleドライブのポインターファイル（.gdoc / .gsheet など）で、
+// 本文がファイル内に含まれていない場合に返すエラー
+var ErrRemoteDocumentPointer = errors.New("remote document pointer")
+
+// DrivePointerParser はGoogleドライブのポインターファイル（.gdoc、.gsheet、.gslides）のパーサー
+// ポインターファイルはドキュメントのIDとURLのみを持つ小さなJSONのため、本文の代わりにURLを案内する文を返す
+type DrivePointerParser struct {
+	BaseParser
+
+	// ReturnError が true の場合、案内文を返す代わりにErrRemoteDocumentPointerをラップしたエラーを返す
+	// パイプライン側でerrors.Isにより判定し、リモートのドキュメントを別途取得する場合に使う
+	ReturnError bool
+}
+
+// drivePointer はポインターファイルのJSON
+type drivePointer struct {
+	DocID string `json:"doc_id"`
+	URL   string `json:"url"`
+}
+
+// SupportedExtensions はサポートする拡張子を返す
+func (p *DrivePointerParser) SupportedExtensions() []string {
+	return []string{".gdoc", ".gsheet", ".gslides"}
+}
+
+// ParseFromFile はファイルパスからポインターファイルをパース
+func (p *DrivePointerParser) ParseFromFile(filePath string) (string, error) {
+	return parseFromFileCommon(p, filePath)
+}
+
+// ParseFromBytes はバイト配列からポインターファイルをパース
+func (p *DrivePointerParser) ParseFromBytes(data []byte) (string, error) {
+	return parseFromBytesCommon(p, data)
+}
+
+// ParseFromReader はio.ReaderAtからポインターファイルをパース
+func (p *DrivePointerParser) ParseFromReader(reader io.ReaderAt, size int64) (string, error) {
+	data, err := io.ReadAll(io.NewSectionReader(reader, 0, size))
+	if err != nil {
+		return "", fmt.Errorf("error reading pointer file: %w", err)
+	}
+
+	var pointer drivePointer
+	if err := json.Unmarshal(data, &pointer); err != nil {
+		return "", fmt.Errorf("error parsing pointer file: %w", err)
+	}
+
+	url := pointer.URL
+	if url == "" && pointer.DocID != "" {
+		url = "https://drive.google.com/open?id=" + pointer.DocID
+	}
+	if url == "" {
+		return "", fmt.Errorf("pointer file has neither url nor doc_id")
+	}
+
+	if p.ReturnError {
+		return "", fmt.Errorf("%w: %s", ErrRemoteDocumentPointer, url)
+	}
+	return fmt.Sprintf("This file is a Google Drive pointer. The document content is not stored locally: %s\n", url), nil
+}
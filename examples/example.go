@@ -72,6 +72,7 @@ func main() {
 	}
 
 	// 例5: XLSXファイルのパース
+	xlsxFilePath := "assets/sample.xlsx"
 	// 例6: XLSXファイルのシート分割パース
 	fmt.Printf("=== ファイルからパース (シート分割): %s ===\n", xlsxFilePath)
 	sheetContents, err := factory.ParseFromFileWithPages(xlsxFilePath)
@@ -1,20 +1,94 @@
 package documentParser
 
 import (
+	"archive/zip"
+	"encoding/xml"
 	"fmt"
 	"io"
 	"log"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/xuri/excelize/v2"
 )
 
 type ExcelParser struct {
 	BaseParser
+
+	// IncludeDefinedNames が true の場合、ブックの定義された名前を "# Defined Names" セクションとして追加する
+	IncludeDefinedNames bool
+
+	// IncludeChartTitles が true の場合、各シートに配置されたグラフのタイトルを追加する
+	IncludeChartTitles bool
+
+	// SkipEmptySheets が true の場合、空でない行を1つも持たないシートを出力から除外する
+	SkipEmptySheets bool
+
+	// AllowEmpty が true の場合、データを持たない有効なブックをエラーにせず空の結果として返す
+	// 読み込めないファイルは従来通りエラーになる
+	AllowEmpty bool
+
+	// ExcelOptions はexcelize.OpenReaderに渡すオプション
+	// UnzipSizeLimit、UnzipXMLSizeLimit、RawCellValue などで大きなブックのメモリ使用量や挙動を調整できる
+	ExcelOptions excelize.Options
+
+	// IncludeSheetIndex が true の場合、結合出力のシート見出しにブック内の順番（1始まり）を付ける
+	IncludeSheetIndex bool
+
+	// IncludeComments が true の場合、各シートのセルのコメント（メモ）を "## Comments" セクションとして追加する
+	IncludeComments bool
+
+	// ISODates が true の場合、日付の表示形式が設定されたセルを "YYYY-MM-DD"（時刻を含む場合は "YYYY-MM-DD HH:MM"、
+	// 時刻のみの場合は "HH:MM"）で出力する。シリアル値（44927 など）や地域依存の表示の代わりに使う
+	// セルごとにスタイルを参照するため、シート全体がメモリに読み込まれる
+	ISODates bool
+
+	// KeepTrailingEmpty が true の場合、行末の空のセルとシート末尾の空の行も出力する
+	// デフォルトでは使用範囲が実際のデータより広いシートでも、区切り文字や空行が末尾に並ばないように除去する
+	KeepTrailingEmpty bool
+
+	// FlushEvery はParseToWriterで出力をフラッシュする行数の間隔（0以下の場合は1行ごと）
+	// 書き込み先がFlushを実装していない場合は無視される
+	FlushEvery int
+
+	// ShowHyperlinks が true の場合、ハイパーリンクを持つセルを "表示テキスト <URL>" の形式で出力する
+	// ブック内の場所へのリンクはリンク先（"Sheet2!A1" など）を出力する
+	ShowHyperlinks bool
+
+	// MarkdownHyperlinks が true の場合、ShowHyperlinks のリンクをMarkdown形式（"[表示テキスト](URL)"）で出力する
+	MarkdownHyperlinks bool
+
+	// IncludePivotTables が true の場合、各シートのピボットテーブルの名前・配置範囲・元データの範囲・フィールド名を
+	// "## Pivot Tables" セクションとして追加する
+	IncludePivotTables bool
+
+	// IncludePivotRecords が true の場合、IncludePivotTables の各ピボットテーブルにキャッシュされた元データのレコード
+	// （xl/pivotCache/pivotCacheRecordsN.xml）を表として追加する。元データのシートが削除されていても内容を取得できる
+	IncludePivotRecords bool
+
+	// MarkdownTable が true の場合、各シートの行を " | " 区切りの代わりにGitHub形式のMarkdownの表として出力する
+	// 最初の空でない行を見出し行とし、列数は最も長い行に揃える。セル内のパイプは "\|"、改行は "<br>" に置き換える
+	// 列数を揃えるため、ParseToWriterでもシートの行をまとめてから書き込む
+	MarkdownTable bool
+
+	// PrependMetadata が true の場合、出力の先頭にコアプロパティ（docProps/core.xml）のタイトル・作成者・作成日時を
+	// "Title: 値" の行として追加する（空の項目は出力しない）。出力が空の場合は追加しない
+	PrependMetadata bool
 }
 
 func (p *ExcelParser) SupportedExtensions() []string {
-	return []string{".xlsx", ".xls"}
+	return []string{
+		".xlsx", ".xls",
+		".xlsm", // マクロ有効ブック
+		".xltx", // テンプレート
+		".xltm", // マクロ有効テンプレート
+	}
+}
+
+// Capabilities はパーサーが抽出できる構造の種類を返す
+func (p *ExcelParser) Capabilities() ParserCapabilities {
+	return ParserCapabilities{Tables: true, Pages: true, Thumbnail: true}
 }
 
 func (p *ExcelParser) ParseFromFile(filePath string) (string, error) {
@@ -25,85 +99,1311 @@ func (p *ExcelParser) ParseFromBytes(data []byte) (string, error) {
 	return parseFromBytesCommon(p, data)
 }
 
-// sheetContent はシート名と内容を保持する構造体
-type sheetContent struct {
-	name    string
-	content string
+// Sheet はシート名と内容を保持する構造体
+type Sheet struct {
+	// Index はブック内の順番（1始まり）
+	Index int
+	Name  string
+	// Content は各行のセルを " | " で結合したテキスト
+	Content string
+	// RowCount は出力した行数（グラフのタイトルは含まない）
+	RowCount int
+	// Empty は空でない行を1つも持たない場合に true
+	Empty bool
+}
+
+// workbookContent はブック全体から抽出した内容を保持する構造体
+type workbookContent struct {
+	sheets       []Sheet
+	definedNames string
+}
+
+// ExtractSheets はExcelファイルから全シートの内容をブック内の順番で返す
+// 順番はシート名の順ではなく、Excelで表示されるタブの並び（xl/workbook.xmlのシート定義の順）になる
+// SkipEmptySheets、IncludeChartTitles、AllowEmpty の設定が反映される
+func (p *ExcelParser) ExtractSheets(reader io.ReaderAt, size int64) ([]Sheet, error) {
+	workbook, err := p.extractSheets(reader, size)
+	if err != nil {
+		return nil, err
+	}
+	return workbook.sheets, nil
 }
 
 // extractSheets はExcelファイルから全シートの内容を抽出する
-func (p *ExcelParser) extractSheets(reader io.ReaderAt, size int64) ([]sheetContent, error) {
-	f, err := excelize.OpenReader(io.NewSectionReader(reader, 0, size))
+func (p *ExcelParser) extractSheets(reader io.ReaderAt, size int64) (*workbookContent, error) {
+	f, err := excelize.OpenReader(io.NewSectionReader(reader, 0, size), p.ExcelOptions)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open file: %w", err)
 	}
 	defer f.Close()
 
 	sheetList := f.GetSheetList()
-	var results []sheetContent
+	var results []Sheet
 
-	for _, sheet := range sheetList {
-		var buf strings.Builder
+	var chartTitles map[string][]string
+	if p.IncludeChartTitles {
+		chartTitles, err = excelChartTitles(reader, size)
+		if err != nil {
+			log.Printf("failed to get chart titles: %v\n", err)
+		}
+	}
 
-		rows, err := f.Rows(sheet)
+	var pivotTables map[string]string
+	if p.IncludePivotTables {
+		pivotTables, err = p.pivotTables(reader, size)
+		if err != nil {
+			log.Printf("failed to get pivot tables: %v\n", err)
+		}
+	}
+
+	written := 0
+	for i, sheet := range sheetList {
+		// MaxOutputBytesを超えた場合は以降のシートを読まない
+		if p.outputLimitReached(written) {
+			break
+		}
+
+		var buf strings.Builder
+		rowCount, hasData, err := p.writeSheet(f, sheet, chartTitles[sheet], pivotTables[sheet], &buf, func(bool) bool {
+			return !p.outputLimitReached(written + buf.Len())
+		})
 		if err != nil {
 			log.Printf("failed to get rows for sheet %s: %v\n", sheet, err)
 			continue
 		}
 
-		for rows.Next() {
-			row, err := rows.Columns()
-			if err != nil {
-				log.Printf("failed to get row: %v\n", err)
+		if p.SkipEmptySheets && !hasData {
+			continue
+		}
+
+		written += buf.Len()
+		results = append(results, Sheet{
+			Index:    i + 1,
+			Name:     sheet,
+			Content:  buf.String(),
+			RowCount: rowCount,
+			Empty:    !hasData,
+		})
+	}
+
+	if len(results) == 0 && !p.AllowEmpty {
+		return nil, fmt.Errorf("no data found")
+	}
+
+	if p.AllowEmpty && allSheetsEmpty(results) {
+		results = nil
+	}
+
+	workbook := &workbookContent{sheets: results}
+	if p.IncludeDefinedNames {
+		workbook.definedNames = formatDefinedNames(f.GetDefinedName())
+	}
+
+	return workbook, nil
+}
+
+// writeSheet はシートの各行と、グラフのタイトル・ピボットテーブル・コメントをwに書き込む
+// beforeRow は各行を読む前に呼ばれ、false を返すとそれ以降の行を読まない（引数は空でない行を書き込み済みかどうか）
+func (p *ExcelParser) writeSheet(f *excelize.File, sheet string, chartTitles []string, pivotTables string, w io.Writer, beforeRow func(hasData bool) bool) (rowCount int, hasData bool, err error) {
+	rows, err := f.Rows(sheet)
+	if err != nil {
+		return 0, false, err
+	}
+	defer rows.Close()
+
+	var tableRows [][]string
+	err = p.forEachRow(f, sheet, rows, func() bool { return beforeRow(hasData) }, func(row []string) error {
+		if !hasData && !isEmptyRow(row) {
+			hasData = true
+		}
+		rowCount++
+		if p.MarkdownTable {
+			tableRows = append(tableRows, row)
+			return nil
+		}
+		if _, err := fmt.Fprintf(w, "%v\n", strings.Join(row, p.cellSeparator(" | "))); err != nil {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return rowCount, hasData, err
+	}
+	if len(tableRows) > 0 {
+		if err := writeMarkdownTable(w, tableRows); err != nil {
+			return rowCount, hasData, err
+		}
+	}
+
+	if len(chartTitles) > 0 {
+		hasData = true
+		io.WriteString(w, "\n## Charts\n")
+		for _, title := range chartTitles {
+			fmt.Fprintf(w, "- %s\n", title)
+		}
+	}
+
+	if pivotTables != "" {
+		hasData = true
+		io.WriteString(w, "\n## Pivot Tables\n")
+		io.WriteString(w, pivotTables)
+	}
+
+	if p.IncludeComments {
+		comments, err := f.GetComments(sheet)
+		if err != nil {
+			log.Printf("failed to get comments for sheet %s: %v\n", sheet, err)
+		} else if len(comments) > 0 {
+			hasData = true
+			io.WriteString(w, "\n## Comments\n")
+			io.WriteString(w, formatComments(comments))
+		}
+	}
+
+	return rowCount, hasData, nil
+}
+
+// markdownCellReplacer はMarkdownの表のセルで表を崩す文字をエスケープする
+var markdownCellReplacer = strings.NewReplacer("|", "\\|", "\r\n", "<br>", "\n", "<br>", "\r", "<br>")
+
+// writeMarkdownTable は行をGitHub形式のMarkdownの表としてwに書き込む
+// 先頭の空の行は除き、最初の行を見出し行とする。途中の空の行は空のセルの行になる
+func writeMarkdownTable(w io.Writer, rows [][]string) error {
+	for len(rows) > 0 && isEmptyRow(rows[0]) {
+		rows = rows[1:]
+	}
+	columns := 0
+	for _, row := range rows {
+		columns = max(columns, len(row))
+	}
+	if columns == 0 {
+		return nil
+	}
+
+	writeRow := func(row []string) error {
+		var sb strings.Builder
+		sb.WriteString("|")
+		for i := 0; i < columns; i++ {
+			cell := ""
+			if i < len(row) {
+				cell = markdownCellReplacer.Replace(strings.TrimSpace(row[i]))
+			}
+			sb.WriteString(" " + cell + " |")
+		}
+		sb.WriteString("\n")
+		_, err := io.WriteString(w, sb.String())
+		return err
+	}
+
+	if err := writeRow(rows[0]); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, "|"+strings.Repeat(" --- |", columns)+"\n"); err != nil {
+		return err
+	}
+	for _, row := range rows[1:] {
+		if err := writeRow(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// forEachRow はシートの各行に日付の書式・ハイパーリンク・末尾の空のセルの除去を適用してfnに渡す
+// KeepTrailingEmpty が false の場合、空の行は後に空でない行が続く場合のみ長さ0の行として渡す
+// next は各行を読む前に呼ばれ、false を返すとそれ以降の行を読まない。fnがエラーを返した場合はそのエラーを返す
+func (p *ExcelParser) forEachRow(f *excelize.File, sheet string, rows *excelize.Rows, next func() bool, fn func(row []string) error) error {
+	var dates *excelDateFormatter
+	if p.ISODates {
+		dates = newExcelDateFormatter(f, sheet)
+	}
+
+	rowNumber, emptyRows := 0, 0
+	for rows.Next() {
+		if !next() {
+			break
+		}
+		rowNumber++
+		row, err := rows.Columns()
+		if err != nil {
+			log.Printf("failed to get row: %v\n", err)
+			continue
+		}
+		if dates != nil {
+			dates.formatRow(rowNumber, row)
+		}
+		if p.ShowHyperlinks {
+			p.linkCells(f, sheet, rowNumber, row)
+		}
+		if !p.KeepTrailingEmpty {
+			row = trimTrailingEmptyCells(row)
+			// 空の行は後に空でない行が続く場合のみ渡す
+			if len(row) == 0 {
+				emptyRows++
 				continue
 			}
-			buf.WriteString(fmt.Sprintf("%v\n", strings.Join(row, " | ")))
+			for ; emptyRows > 0; emptyRows-- {
+				if err := fn([]string{}); err != nil {
+					return err
+				}
+			}
 		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-		results = append(results, sheetContent{
-			name:    sheet,
-			content: buf.String(),
+// StreamRows は全シートをブックのタブの順に1行ずつ読み込み、行ごとにfnを呼ぶ
+// 結合したテキストを組み立てないため、ETLなどで大きなブックの行を逐次処理する場合に使う
+// ISODates、ShowHyperlinks、KeepTrailingEmpty の設定が反映される（途中の空の行は長さ0の行になる）
+// fnがエラーを返した場合は以降の行を読まずに、そのエラーをそのまま返す
+func (p *ExcelParser) StreamRows(reader io.ReaderAt, size int64, fn func(sheet string, row []string) error) error {
+	f, err := excelize.OpenReader(io.NewSectionReader(reader, 0, size), p.ExcelOptions)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer f.Close()
+
+	for _, sheet := range f.GetSheetList() {
+		rows, err := f.Rows(sheet)
+		if err != nil {
+			log.Printf("failed to get rows for sheet %s: %v\n", sheet, err)
+			continue
+		}
+		err = p.forEachRow(f, sheet, rows, func() bool { return true }, func(row []string) error {
+			return fn(sheet, row)
 		})
+		rows.Close()
+		if err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	if len(results) == 0 {
-		return nil, fmt.Errorf("no data found")
+// CellType はExtractTypedが返すセルの値の種類
+type CellType string
+
+const (
+	// CellEmpty は値を持たないセル（TypedCellのゼロ値）
+	CellEmpty CellType = ""
+	// CellString は文字列（共有文字列・インライン文字列・エラー値）のセル。Valueはstring
+	CellString CellType = "string"
+	// CellNumber は数値のセル。Valueはfloat64
+	CellNumber CellType = "number"
+	// CellBool は真偽値のセル。Valueはbool
+	CellBool CellType = "bool"
+	// CellDate は日付型のセル、または日付の表示形式が設定された数値のセル。Valueはtime.Time
+	CellDate CellType = "date"
+	// CellFormula は数式のセル。Valueはキャッシュされた計算結果（数値の場合はfloat64、真偽値の場合はbool、それ以外はstring）
+	CellFormula CellType = "formula"
+)
+
+// TypedCell は型付きのセルの値
+type TypedCell struct {
+	Type  CellType
+	Value any
+	// Text は表示形式を適用した値（ParseFromReaderの出力と同じ文字列）
+	Text string
+	// Formula はCellFormulaの場合の数式（先頭の "=" は含まない）
+	Formula string
+}
+
+// TypedSheet はExtractTypedが返すシートの型付きの値
+type TypedSheet struct {
+	// Index はブック内の順番（1始まり）
+	Index int
+	Name  string
+	// Rows は1行目からの行ごとのセル（Rows[0][0] がA1）。空のセルはType が CellEmpty になる
+	// KeepTrailingEmpty が false の場合、行末の空のセルとシート末尾の空の行は含まない
+	Rows [][]TypedCell
+}
+
+// ExtractTyped は全シートのセルを文字列に変換せずに、値と型（excelizeのGetCellTypeによる）をブックのタブの順に返す
+// "44927" のような数値と文字列、日付のシリアル値を区別したままデータパイプラインに渡す場合に使う
+// 日付の表示形式が設定された数値のセルはCellDate（time.Time）として返す
+func (p *ExcelParser) ExtractTyped(reader io.ReaderAt, size int64) ([]TypedSheet, error) {
+	f, err := excelize.OpenReader(io.NewSectionReader(reader, 0, size), p.ExcelOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer f.Close()
+
+	var sheets []TypedSheet
+	for i, sheet := range f.GetSheetList() {
+		rows, err := f.GetRows(sheet, excelize.Options{RawCellValue: true})
+		if err != nil {
+			log.Printf("failed to get rows for sheet %s: %v\n", sheet, err)
+			continue
+		}
+
+		dates := newExcelDateFormatter(f, sheet)
+		typed := make([][]TypedCell, len(rows))
+		for r, row := range rows {
+			if !p.KeepTrailingEmpty {
+				row = trimTrailingEmptyCells(row)
+			}
+			typed[r] = make([]TypedCell, len(row))
+			for c, raw := range row {
+				cell, err := excelize.CoordinatesToCellName(c+1, r+1)
+				if err != nil {
+					continue
+				}
+				typed[r][c] = dates.typedCell(cell, raw)
+			}
+		}
+		if !p.KeepTrailingEmpty {
+			for len(typed) > 0 && len(typed[len(typed)-1]) == 0 {
+				typed = typed[:len(typed)-1]
+			}
+		}
+		sheets = append(sheets, TypedSheet{Index: i + 1, Name: sheet, Rows: typed})
+	}
+	return sheets, nil
+}
+
+// typedCell はセルの生の値rawを型に応じた値に変換する（値も数式も持たない場合はCellEmpty）
+func (d *excelDateFormatter) typedCell(cell, raw string) TypedCell {
+	formula, _ := d.f.GetCellFormula(d.sheet, cell)
+	if raw == "" && formula == "" {
+		return TypedCell{}
+	}
+
+	text, err := d.f.GetCellValue(d.sheet, cell)
+	if err != nil {
+		text = raw
+	}
+	typed := TypedCell{Type: CellString, Value: raw, Text: text}
+
+	if formula != "" {
+		typed.Type, typed.Formula = CellFormula, formula
+		if value, err := strconv.ParseFloat(raw, 64); err == nil {
+			typed.Value = value
+		}
+		if cellType, err := d.f.GetCellType(d.sheet, cell); err == nil && cellType == excelize.CellTypeBool {
+			typed.Value = raw == "1"
+		}
+		return typed
 	}
 
-	return results, nil
+	cellType, err := d.f.GetCellType(d.sheet, cell)
+	if err != nil {
+		return typed
+	}
+	switch cellType {
+	case excelize.CellTypeBool:
+		typed.Type, typed.Value = CellBool, raw == "1"
+	case excelize.CellTypeDate:
+		// 日付型のセル（t="d"）はISO 8601の文字列を持つ
+		t, err := time.Parse(time.RFC3339Nano, raw)
+		if err != nil {
+			if t, err = time.Parse("2006-01-02T15:04:05", raw); err != nil {
+				return typed
+			}
+		}
+		typed.Type, typed.Value = CellDate, t
+	case excelize.CellTypeNumber, excelize.CellTypeUnset:
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return typed
+		}
+		typed.Type, typed.Value = CellNumber, value
+		if styleID, err := d.f.GetCellStyle(d.sheet, cell); err == nil && d.layout(styleID) != "" {
+			if t, err := excelize.ExcelDateToTime(value, d.date1904); err == nil {
+				typed.Type, typed.Value = CellDate, t
+			}
+		}
+	}
+	return typed
 }
 
+// ParseFromReader は全シートの内容を "# Sheet シート名" の見出し付きで結合して返す
+// シートはExtractSheetsと同じくブックのタブの順に出力される
 func (p *ExcelParser) ParseFromReader(reader io.ReaderAt, size int64) (string, error) {
-	sheets, err := p.extractSheets(reader, size)
+	workbook, err := p.extractSheets(reader, size)
 	if err != nil {
 		return "", err
 	}
 
 	var buf strings.Builder
-	for _, sheet := range sheets {
-		buf.WriteString(fmt.Sprintf("# Sheet %s\n", sheet.name))
-		buf.WriteString(sheet.content)
-		buf.WriteString("\n---\n\n")
+	for i, sheet := range workbook.sheets {
+		p.writePageSeparator(&buf, i)
+		buf.WriteString(p.sheetHeading(sheet.Index, sheet.Name))
+		buf.WriteString(sheet.Content)
+		buf.WriteString(p.sheetTerminator())
+	}
+
+	if workbook.definedNames != "" {
+		buf.WriteString(fmt.Sprintf("# %s\n", definedNamesKey))
+		buf.WriteString(workbook.definedNames)
 	}
 
-	if buf.Len() == 0 {
+	if buf.Len() == 0 && !p.AllowEmpty {
 		return "", fmt.Errorf("no data found")
 	}
 
-	return buf.String(), nil
+	if p.PrependMetadata && buf.Len() > 0 {
+		return truncateOutput(ooxmlMetadataHeader(reader, size)+buf.String(), p.MaxOutputBytes), nil
+	}
+	return truncateOutput(buf.String(), p.MaxOutputBytes), nil
+}
+
+// sheetHeading は結合出力のシートの見出しを返す
+func (p *ExcelParser) sheetHeading(index int, name string) string {
+	if p.IncludeSheetIndex {
+		return p.boundaryMarker(heading("# ", p.labels().SheetHeader, fmt.Sprintf("%d: %s", index, name), "\n"), "SHEET", fmt.Sprintf("%d:%s", index, name))
+	}
+	return p.boundaryMarker(heading("# ", p.labels().SheetHeader, name, "\n"), "SHEET", name)
+}
+
+// sheetTerminator は各シートの末尾に書き込む区切り線（Labels.Separator）を返す
+func (p *ExcelParser) sheetTerminator() string {
+	if separator := p.labels().Separator; separator != "" {
+		return "\n" + separator + "\n\n"
+	}
+	return "\n"
+}
+
+// ParseToWriter はParseFromReaderと同じ形式の出力を、シート全体をメモリに溜めずに1行ずつwへ書き込む
+// wがFlushを実装している場合（bufio.Writer、http.ResponseWriterなど）はFlushEvery行ごとにフラッシュする
+// SkipEmptySheets と AllowEmpty を反映するため、空でない行が現れるまでシートの見出しと先頭の空行は保留する
+// MaxOutputBytes を超えた場合は書き込みを打ち切り、末尾にTruncatedMarkerを書き込む
+func (p *ExcelParser) ParseToWriter(reader io.ReaderAt, size int64, w io.Writer) error {
+	f, err := excelize.OpenReader(io.NewSectionReader(reader, 0, size), p.ExcelOptions)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer f.Close()
+
+	var chartTitles map[string][]string
+	if p.IncludeChartTitles {
+		chartTitles, err = excelChartTitles(reader, size)
+		if err != nil {
+			log.Printf("failed to get chart titles: %v\n", err)
+		}
+	}
+
+	var pivotTables map[string]string
+	if p.IncludePivotTables {
+		pivotTables, err = p.pivotTables(reader, size)
+		if err != nil {
+			log.Printf("failed to get pivot tables: %v\n", err)
+		}
+	}
+
+	flushEvery := max(p.FlushEvery, 1)
+	out := &countingWriter{w: w}
+	if p.PrependMetadata {
+		out.w = &prefixWriter{w: w, prefix: ooxmlMetadataHeader(reader, size)}
+	}
+
+	// deferred は空のシートの出力（後に空でないシートが続く場合のみ書き込む）
+	var deferred strings.Builder
+	sheetCount := 0
+	hasAnyData := false
+	truncated := false
+
+	for i, sheet := range f.GetSheetList() {
+		if p.outputLimitReached(out.n) {
+			truncated = true
+			break
+		}
+
+		sw := &excelSheetWriter{w: out}
+		p.writePageSeparator(&sw.pending, sheetCount)
+		sw.pending.WriteString(p.sheetHeading(i+1, sheet))
+
+		rowsSinceFlush := 0
+		_, hasData, err := p.writeSheet(f, sheet, chartTitles[sheet], pivotTables[sheet], sw, func(hasData bool) bool {
+			if hasData && !sw.started {
+				io.WriteString(out, deferred.String())
+				deferred.Reset()
+				sw.start()
+			}
+			if sw.started {
+				if rowsSinceFlush++; rowsSinceFlush >= flushEvery {
+					flushWriter(w)
+					rowsSinceFlush = 0
+				}
+			}
+			if p.outputLimitReached(out.n) {
+				truncated = true
+				return false
+			}
+			return true
+		})
+		if err != nil {
+			if out.err != nil {
+				return fmt.Errorf("failed to write sheet %s: %w", sheet, out.err)
+			}
+			log.Printf("failed to get rows for sheet %s: %v\n", sheet, err)
+			continue
+		}
+
+		if !hasData {
+			if !p.SkipEmptySheets {
+				deferred.WriteString(sw.pending.String())
+				deferred.WriteString(p.sheetTerminator())
+				sheetCount++
+			}
+			continue
+		}
+
+		if !sw.started {
+			io.WriteString(out, deferred.String())
+			deferred.Reset()
+			sw.start()
+		}
+		io.WriteString(out, p.sheetTerminator())
+		flushWriter(w)
+		sheetCount++
+		hasAnyData = true
+	}
+
+	// AllowEmpty の場合、全てのシートが空なら何も出力しない
+	if hasAnyData || !p.AllowEmpty {
+		io.WriteString(out, deferred.String())
+	}
+
+	if p.IncludeDefinedNames {
+		if names := formatDefinedNames(f.GetDefinedName()); names != "" {
+			fmt.Fprintf(out, "# %s\n", definedNamesKey)
+			io.WriteString(out, names)
+		}
+	}
+
+	if truncated {
+		io.WriteString(out, TruncatedMarker)
+	}
+	flushWriter(w)
+
+	if out.err != nil {
+		return fmt.Errorf("failed to write output: %w", out.err)
+	}
+	if out.n == 0 && !p.AllowEmpty {
+		return fmt.Errorf("no data found")
+	}
+	return nil
+}
+
+// excelSheetWriter はstartが呼ばれるまで書き込みをpendingに保留するWriter
+type excelSheetWriter struct {
+	w       io.Writer
+	pending strings.Builder
+	started bool
+}
+
+// Write はstart前はpendingに、start後はwに書き込む
+func (sw *excelSheetWriter) Write(b []byte) (int, error) {
+	if !sw.started {
+		return sw.pending.Write(b)
+	}
+	return sw.w.Write(b)
+}
+
+// start は保留していた内容を書き込み、以降の書き込みをwに直接渡す
+func (sw *excelSheetWriter) start() {
+	io.WriteString(sw.w, sw.pending.String())
+	sw.pending.Reset()
+	sw.started = true
+}
+
+// countingWriter は書き込んだバイト数と最初のエラーを記録するWriter
+type countingWriter struct {
+	w   io.Writer
+	n   int
+	err error
+}
+
+// Write はwに書き込み、書き込んだバイト数を加算する
+func (cw *countingWriter) Write(b []byte) (int, error) {
+	if cw.err != nil {
+		return 0, cw.err
+	}
+	n, err := cw.w.Write(b)
+	cw.n += n
+	cw.err = err
+	return n, err
+}
+
+// prefixWriter は最初の書き込みの前にprefixを書き込むWriter（何も書き込まれない場合はprefixも書き込まない）
+type prefixWriter struct {
+	w      io.Writer
+	prefix string
+}
+
+// Write は初回のみprefixを書き込んでからbを書き込む
+func (pw *prefixWriter) Write(b []byte) (int, error) {
+	if pw.prefix != "" && len(b) > 0 {
+		if _, err := io.WriteString(pw.w, pw.prefix); err != nil {
+			return 0, err
+		}
+		pw.prefix = ""
+	}
+	return pw.w.Write(b)
+}
+
+// flushWriter はwがFlushを実装している場合にフラッシュする
+func flushWriter(w io.Writer) {
+	switch fw := w.(type) {
+	case interface{ Flush() error }:
+		if err := fw.Flush(); err != nil {
+			log.Printf("failed to flush output: %v\n", err)
+		}
+	case interface{ Flush() }:
+		fw.Flush()
+	}
 }
 
 // ParseWithPages はシートごとに内容を分けてマップ形式で返す
+// マップはシートの順番を保持しないため、順番が必要な場合はParseSheetsOrderedを使う
 func (p *ExcelParser) ParseWithPages(reader io.ReaderAt, size int64) (map[string]string, error) {
-	sheets, err := p.extractSheets(reader, size)
+	workbook, err := p.extractSheets(reader, size)
 	if err != nil {
 		return nil, err
 	}
 
 	result := make(map[string]string)
+	for _, sheet := range workbook.sheets {
+		result[sheet.Name] = sheet.Content
+	}
+
+	// シート名と衝突しない場合のみ定義された名前を追加する
+	if workbook.definedNames != "" {
+		if _, exists := result[definedNamesKey]; !exists {
+			result[definedNamesKey] = workbook.definedNames
+		}
+	}
+
+	return result, nil
+}
+
+// ParseSheetsOrdered はParseWithPagesと同じシートごとの内容を、ブックのタブの順番のスライスで返す
+// 順番はシート名の順ではなく、Excelで表示されるタブの並び（xl/workbook.xmlのシート定義の順）になる。ParseFromReaderの結合出力も同じ順番
+// IncludeDefinedNamesが有効な場合、定義された名前はシート名と衝突しなければ最後の要素（名前: "Defined Names"）になる
+func (p *ExcelParser) ParseSheetsOrdered(reader io.ReaderAt, size int64) ([]Page, error) {
+	workbook, err := p.extractSheets(reader, size)
+	if err != nil {
+		return nil, err
+	}
+
+	pages := make([]Page, 0, len(workbook.sheets)+1)
+	names := make(map[string]bool)
+	for _, sheet := range workbook.sheets {
+		pages = append(pages, Page{Number: sheet.Index, Name: sheet.Name, Content: sheet.Content})
+		names[sheet.Name] = true
+	}
+
+	if workbook.definedNames != "" && !names[definedNamesKey] {
+		pages = append(pages, Page{Number: len(pages) + 1, Name: definedNamesKey, Content: workbook.definedNames})
+	}
+
+	return pages, nil
+}
+
+// parsePages はシートをブック内の順番でページとして返す
+func (p *ExcelParser) parsePages(reader io.ReaderAt, size int64) ([]Page, error) {
+	sheets, err := p.ExtractSheets(reader, size)
+	if err != nil {
+		return nil, err
+	}
+
+	pages := make([]Page, len(sheets))
+	for i, sheet := range sheets {
+		pages[i] = Page{Number: sheet.Index, Name: sheet.Name, Content: sheet.Content}
+	}
+	return pages, nil
+}
+
+// PageCount はシート数を返す
+// excelizeでブック全体を開かず、xl/workbook.xmlのシート定義のみを読み込む
+func (p *ExcelParser) PageCount(reader io.ReaderAt, size int64) (int, error) {
+	r, err := zip.NewReader(reader, size)
+	if err != nil {
+		return 0, fmt.Errorf("error reading Excel file: %w", err)
+	}
+
+	workbook, err := readXlsxWorkbook(zipFileMap(r))
+	if err != nil {
+		return 0, err
+	}
+	return len(workbook.Sheets), nil
+}
+
+// allSheetsEmpty は全てのシートがデータを持たないかどうかを判定する
+func allSheetsEmpty(sheets []Sheet) bool {
 	for _, sheet := range sheets {
-		result[sheet.name] = sheet.content
+		if !sheet.Empty {
+			return false
+		}
+	}
+	return true
+}
+
+// isEmptyRow は行の全てのセルが空白かどうかを判定する
+func isEmptyRow(row []string) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// trimTrailingEmptyCells は行末の空のセルを除いた行を返す
+func trimTrailingEmptyCells(row []string) []string {
+	end := len(row)
+	for end > 0 && strings.TrimSpace(row[end-1]) == "" {
+		end--
+	}
+	return row[:end]
+}
+
+// linkCells はハイパーリンクを持つセルの値をリンク先付きの形式に置き換える
+func (p *ExcelParser) linkCells(f *excelize.File, sheet string, rowNumber int, row []string) {
+	for i, value := range row {
+		if value == "" {
+			continue
+		}
+		cell, err := excelize.CoordinatesToCellName(i+1, rowNumber)
+		if err != nil {
+			continue
+		}
+		ok, target, err := f.GetCellHyperLink(sheet, cell)
+		if err != nil {
+			log.Printf("failed to get hyperlink for %s!%s: %v\n", sheet, cell, err)
+			continue
+		}
+		if !ok || target == "" {
+			continue
+		}
+		if p.MarkdownHyperlinks {
+			row[i] = fmt.Sprintf("[%s](%s)", value, target)
+		} else {
+			row[i] = fmt.Sprintf("%s <%s>", value, target)
+		}
+	}
+}
+
+// excelDateFormatter は日付の表示形式が設定されたセルをISO形式に変換する
+type excelDateFormatter struct {
+	f        *excelize.File
+	sheet    string
+	date1904 bool
+	// layouts はスタイルIDごとのtime.Formatのレイアウト（日付の形式でない場合は空文字列）
+	layouts map[int]string
+}
+
+// newExcelDateFormatter はシートの日付セルを変換するexcelDateFormatterを作る
+func newExcelDateFormatter(f *excelize.File, sheet string) *excelDateFormatter {
+	d := &excelDateFormatter{f: f, sheet: sheet, layouts: make(map[int]string)}
+	if props, err := f.GetWorkbookProps(); err == nil && props.Date1904 != nil {
+		d.date1904 = *props.Date1904
+	}
+	return d
+}
+
+// formatRow は行内の日付セルの値をISO形式に置き換える
+func (d *excelDateFormatter) formatRow(rowNumber int, row []string) {
+	for i, value := range row {
+		if value == "" {
+			continue
+		}
+		cell, err := excelize.CoordinatesToCellName(i+1, rowNumber)
+		if err != nil {
+			continue
+		}
+		if formatted, ok := d.formatCell(cell); ok {
+			row[i] = formatted
+		}
+	}
+}
+
+// formatCell はセルが日付の場合にISO形式の値を返す
+func (d *excelDateFormatter) formatCell(cell string) (string, bool) {
+	cellType, err := d.f.GetCellType(d.sheet, cell)
+	if err != nil {
+		return "", false
+	}
+	raw, err := d.f.GetCellValue(d.sheet, cell, excelize.Options{RawCellValue: true})
+	if err != nil || raw == "" {
+		return "", false
+	}
+
+	// 日付型のセル（t="d"）はISO 8601の文字列を持つ
+	if cellType == excelize.CellTypeDate {
+		t, err := time.Parse(time.RFC3339Nano, raw)
+		if err != nil {
+			if t, err = time.Parse("2006-01-02T15:04:05", raw); err != nil {
+				return "", false
+			}
+		}
+		if t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 {
+			return t.Format("2006-01-02"), true
+		}
+		return t.Format("2006-01-02 15:04"), true
+	}
+	if cellType != excelize.CellTypeNumber && cellType != excelize.CellTypeUnset {
+		return "", false
+	}
+
+	styleID, err := d.f.GetCellStyle(d.sheet, cell)
+	if err != nil {
+		return "", false
+	}
+	layout := d.layout(styleID)
+	if layout == "" {
+		return "", false
+	}
+
+	serial, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return "", false
+	}
+	t, err := excelize.ExcelDateToTime(serial, d.date1904)
+	if err != nil {
+		return "", false
+	}
+	return t.Format(layout), true
+}
+
+// layout はスタイルの表示形式に対応するtime.Formatのレイアウトを返す
+func (d *excelDateFormatter) layout(styleID int) string {
+	if layout, ok := d.layouts[styleID]; ok {
+		return layout
+	}
+
+	layout := ""
+	if style, err := d.f.GetStyle(styleID); err == nil {
+		var hasDate, hasTime bool
+		if style.CustomNumFmt != nil {
+			hasDate, hasTime = dateFormatParts(*style.CustomNumFmt)
+		} else {
+			hasDate, hasTime = builtinDateFormatParts(style.NumFmt)
+		}
+		switch {
+		case hasDate && hasTime:
+			layout = "2006-01-02 15:04"
+		case hasDate:
+			layout = "2006-01-02"
+		case hasTime:
+			layout = "15:04"
+		}
+	}
+	d.layouts[styleID] = layout
+	return layout
+}
+
+// builtinDateFormatParts は組み込みの表示形式IDが日付・時刻を含むかどうかを返す
+// 経過時間の形式（45〜47の "mm:ss" など）は日時として扱わない
+func builtinDateFormatParts(id int) (hasDate, hasTime bool) {
+	switch id {
+	case 14, 15, 16, 17, 27, 28, 29, 30, 31, 34, 35, 36, 50, 51, 52, 53, 54, 57, 58:
+		return true, false
+	case 22:
+		return true, true
+	case 18, 19, 20, 21, 32, 33, 55, 56:
+		return false, true
+	}
+	return false, false
+}
+
+// dateFormatParts はユーザー定義の表示形式（"yyyy/mm/dd hh:mm" など）が日付・時刻を含むかどうかを返す
+// 引用符で囲まれた文字列、エスケープされた文字、[$-411] のような角括弧の指定は無視する
+func dateFormatParts(code string) (hasDate, hasTime bool) {
+	// セクションが複数ある場合は正の数の形式（先頭）のみを見る
+	code, _, _ = strings.Cut(code, ";")
+
+	var tokens strings.Builder
+	inQuote, inBracket := false, false
+	for i := 0; i < len(code); i++ {
+		c := code[i]
+		switch {
+		case inQuote:
+			inQuote = c != '"'
+		case inBracket:
+			inBracket = c != ']'
+		case c == '"':
+			inQuote = true
+		case c == '[':
+			inBracket = true
+		case c == '\\' || c == '_' || c == '*':
+			// 次の1文字はリテラル
+			i++
+		default:
+			tokens.WriteByte(c | 0x20)
+		}
+	}
+
+	t := tokens.String()
+	hasDate = strings.ContainsAny(t, "yd")
+	hasTime = strings.ContainsAny(t, "hs")
+	if !hasDate && !hasTime && strings.Contains(t, "m") {
+		// "mmm" のような月のみの形式
+		hasDate = !strings.ContainsAny(t, "0#?")
+	}
+	return hasDate, hasTime
+}
+
+// formatComments はコメントを "セル: コメント" の行に変換する
+// コメント内の改行はスペースに置き換える
+func formatComments(comments []excelize.Comment) string {
+	var buf strings.Builder
+	for _, comment := range comments {
+		text := comment.Text
+		if text == "" {
+			var runs strings.Builder
+			for _, run := range comment.Paragraph {
+				runs.WriteString(run.Text)
+			}
+			text = runs.String()
+		}
+		text = strings.Join(strings.Fields(text), " ")
+		buf.WriteString(fmt.Sprintf("%s: %s\n", comment.Cell, text))
+	}
+	return buf.String()
+}
+
+// definedNamesKey は定義された名前セクションの見出し
+const definedNamesKey = "Defined Names"
+
+// formatDefinedNames は定義された名前を "名前: 参照範囲" の行に変換する
+func formatDefinedNames(names []excelize.DefinedName) string {
+	var buf strings.Builder
+	for _, name := range names {
+		line := fmt.Sprintf("%s: %s", name.Name, name.RefersTo)
+		if name.Scope != "" && name.Scope != "Workbook" {
+			line += fmt.Sprintf(" (scope: %s)", name.Scope)
+		}
+		buf.WriteString(line + "\n")
+	}
+	return buf.String()
+}
+
+// xlsxWorkbook はxl/workbook.xmlのシート定義を表現する構造体
+type xlsxWorkbook struct {
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		RID  string `xml:"id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+// xlsxWorkbookPart はブック定義のパーツ名
+const xlsxWorkbookPart = "xl/workbook.xml"
+
+// readXlsxWorkbook はxl/workbook.xmlを読み込む
+func readXlsxWorkbook(files map[string]*zip.File) (*xlsxWorkbook, error) {
+	f, ok := files[xlsxWorkbookPart]
+	if !ok {
+		return nil, fmt.Errorf("%s not found", xlsxWorkbookPart)
+	}
+	data, err := readZipFile(f)
+	if err != nil {
+		return nil, err
+	}
+	var workbook xlsxWorkbook
+	if err := xml.Unmarshal(data, &workbook); err != nil {
+		return nil, fmt.Errorf("error parsing XML for %s: %w", xlsxWorkbookPart, err)
+	}
+	return &workbook, nil
+}
+
+// xlsxSheetParts はブックのリレーションシップを辿り、シート名ごとのワークシートのパーツ名を返す
+func xlsxSheetParts(files map[string]*zip.File, workbook *xlsxWorkbook) (map[string]string, error) {
+	workbookRels, err := readRelationships(files, xlsxWorkbookPart)
+	if err != nil {
+		return nil, err
+	}
+	partsByID := make(map[string]string)
+	for _, rel := range workbookRels {
+		partsByID[rel.ID] = resolveTarget(xlsxWorkbookPart, rel.Target)
+	}
+
+	parts := make(map[string]string)
+	for _, sheet := range workbook.Sheets {
+		if part, ok := partsByID[sheet.RID]; ok {
+			parts[sheet.Name] = part
+		}
+	}
+	return parts, nil
+}
+
+// excelChartTitles はシート名ごとに配置されたグラフのタイトルを返す
+// シート -> 描画 -> グラフ のリレーションシップを辿って取得する
+func excelChartTitles(reader io.ReaderAt, size int64) (map[string][]string, error) {
+	r, err := zip.NewReader(reader, size)
+	if err != nil {
+		return nil, fmt.Errorf("error reading Excel file: %w", err)
+	}
+	files := zipFileMap(r)
+
+	workbook, err := readXlsxWorkbook(files)
+	if err != nil {
+		return nil, err
+	}
+
+	sheetParts, err := xlsxSheetParts(files, workbook)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string][]string)
+	for _, sheet := range workbook.Sheets {
+		sheetPart, ok := sheetParts[sheet.Name]
+		if !ok {
+			continue
+		}
+		for _, drawingPart := range relatedParts(files, sheetPart, "/drawing") {
+			for _, chartPart := range relatedParts(files, drawingPart, "/chart") {
+				cf, ok := files[chartPart]
+				if !ok {
+					continue
+				}
+				content, err := readZipFile(cf)
+				if err != nil {
+					log.Printf("failed to read chart %s: %v\n", chartPart, err)
+					continue
+				}
+				var chart chartSpace
+				if err := xml.Unmarshal(content, &chart); err != nil {
+					log.Printf("failed to parse chart %s: %v\n", chartPart, err)
+					continue
+				}
+				if title := strings.Join(chart.Chart.Title.Texts, ""); title != "" {
+					result[sheet.Name] = append(result[sheet.Name], title)
+				}
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// xlsxPivotTable はxl/pivotTables/pivotTableN.xmlのピボットテーブルの定義
+type xlsxPivotTable struct {
+	Name     string `xml:"name,attr"`
+	Location struct {
+		Ref string `xml:"ref,attr"`
+	} `xml:"location"`
+	RowFields  []xlsxPivotFieldRef `xml:"rowFields>field"`
+	ColFields  []xlsxPivotFieldRef `xml:"colFields>field"`
+	DataFields []struct {
+		Name  string `xml:"name,attr"`
+		Field int    `xml:"fld,attr"`
+	} `xml:"dataFields>dataField"`
+}
+
+// xlsxPivotFieldRef はピボットテーブルの行・列に配置されたフィールドの番号（-2は値フィールドの並び）
+type xlsxPivotFieldRef struct {
+	X int `xml:"x,attr"`
+}
+
+// xlsxPivotCacheDefinition はxl/pivotCache/pivotCacheDefinitionN.xmlのキャッシュの定義
+type xlsxPivotCacheDefinition struct {
+	Source struct {
+		Type      string `xml:"type,attr"`
+		Worksheet struct {
+			Ref   string `xml:"ref,attr"`
+			Name  string `xml:"name,attr"`
+			Sheet string `xml:"sheet,attr"`
+		} `xml:"worksheetSource"`
+	} `xml:"cacheSource"`
+	Fields []struct {
+		Name        string `xml:"name,attr"`
+		SharedItems struct {
+			Items []xlsxPivotCacheValue `xml:",any"`
+		} `xml:"sharedItems"`
+	} `xml:"cacheFields>cacheField"`
+}
+
+// xlsxPivotCacheRecords はxl/pivotCache/pivotCacheRecordsN.xmlのキャッシュされたレコード
+type xlsxPivotCacheRecords struct {
+	Records []struct {
+		Values []xlsxPivotCacheValue `xml:",any"`
+	} `xml:"r"`
+}
+
+// xlsxPivotCacheValue はキャッシュの値（要素名が型を表す: s 文字列、n 数値、b 真偽値、d 日時、e エラー、m 空、x 共有アイテムの番号）
+type xlsxPivotCacheValue struct {
+	XMLName xml.Name
+	V       string `xml:"v,attr"`
+}
+
+// pivotTables はシート名ごとに、配置されたピボットテーブルの説明を返す
+// シート -> ピボットテーブル -> キャッシュの定義 -> キャッシュのレコード のリレーションシップを辿って取得する
+func (p *ExcelParser) pivotTables(reader io.ReaderAt, size int64) (map[string]string, error) {
+	r, err := zip.NewReader(reader, size)
+	if err != nil {
+		return nil, fmt.Errorf("error reading Excel file: %w", err)
+	}
+	files := zipFileMap(r)
+
+	workbook, err := readXlsxWorkbook(files)
+	if err != nil {
+		return nil, err
+	}
+	sheetParts, err := xlsxSheetParts(files, workbook)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]string)
+	for _, sheet := range workbook.Sheets {
+		var sb strings.Builder
+		for _, pivotPart := range relatedParts(files, sheetParts[sheet.Name], "/pivotTable") {
+			var pivot xlsxPivotTable
+			if err := readXMLPart(files, pivotPart, &pivot); err != nil {
+				log.Printf("failed to read pivot table %s: %v\n", pivotPart, err)
+				continue
+			}
+
+			var cache xlsxPivotCacheDefinition
+			cacheParts := relatedParts(files, pivotPart, "/pivotCacheDefinition")
+			if len(cacheParts) > 0 {
+				if err := readXMLPart(files, cacheParts[0], &cache); err != nil {
+					log.Printf("failed to read pivot cache %s: %v\n", cacheParts[0], err)
+				}
+			}
+
+			if sb.Len() > 0 {
+				sb.WriteString("\n")
+			}
+			p.writePivotTable(&sb, files, pivot, cache, cacheParts)
+		}
+		if sb.Len() > 0 {
+			result[sheet.Name] = sb.String()
+		}
 	}
 
 	return result, nil
 }
+
+// writePivotTable はピボットテーブルの名前・範囲・フィールド（IncludePivotRecordsの場合はレコードも）を書き込む
+func (p *ExcelParser) writePivotTable(sb *strings.Builder, files map[string]*zip.File, pivot xlsxPivotTable, cache xlsxPivotCacheDefinition, cacheParts []string) {
+	fieldName := func(index int) string {
+		if index >= 0 && index < len(cache.Fields) {
+			return cache.Fields[index].Name
+		}
+		return ""
+	}
+	fieldNames := func(refs []xlsxPivotFieldRef) string {
+		var names []string
+		for _, ref := range refs {
+			if name := fieldName(ref.X); name != "" {
+				names = append(names, name)
+			}
+		}
+		return strings.Join(names, ", ")
+	}
+
+	fmt.Fprintf(sb, "### %s\n", pivot.Name)
+	if pivot.Location.Ref != "" {
+		fmt.Fprintf(sb, "Location: %s\n", pivot.Location.Ref)
+	}
+	if source := pivotSource(cache); source != "" {
+		fmt.Fprintf(sb, "Source: %s\n", source)
+	}
+
+	var names []string
+	for _, field := range cache.Fields {
+		names = append(names, field.Name)
+	}
+	if len(names) > 0 {
+		fmt.Fprintf(sb, "Fields: %s\n", strings.Join(names, ", "))
+	}
+	if rows := fieldNames(pivot.RowFields); rows != "" {
+		fmt.Fprintf(sb, "Rows: %s\n", rows)
+	}
+	if cols := fieldNames(pivot.ColFields); cols != "" {
+		fmt.Fprintf(sb, "Columns: %s\n", cols)
+	}
+	var values []string
+	for _, field := range pivot.DataFields {
+		if field.Name != "" {
+			values = append(values, field.Name)
+		} else if name := fieldName(field.Field); name != "" {
+			values = append(values, name)
+		}
+	}
+	if len(values) > 0 {
+		fmt.Fprintf(sb, "Values: %s\n", strings.Join(values, ", "))
+	}
+
+	if p.IncludePivotRecords && len(cacheParts) > 0 {
+		for _, recordsPart := range relatedParts(files, cacheParts[0], "/pivotCacheRecords") {
+			var records xlsxPivotCacheRecords
+			if err := readXMLPart(files, recordsPart, &records); err != nil {
+				log.Printf("failed to read pivot cache records %s: %v\n", recordsPart, err)
+				continue
+			}
+			if len(records.Records) == 0 {
+				continue
+			}
+
+			separator := p.cellSeparator(" | ")
+			sb.WriteString("Records:\n")
+			sb.WriteString(strings.Join(names, separator) + "\n")
+			for _, record := range records.Records {
+				row := make([]string, len(record.Values))
+				for i, value := range record.Values {
+					row[i] = pivotCacheValue(cache, i, value)
+				}
+				sb.WriteString(strings.Join(row, separator) + "\n")
+			}
+		}
+	}
+}
+
+// pivotSource はキャッシュの元データ（"シート名!範囲"、名前付き範囲・テーブル名、外部データ）を返す
+func pivotSource(cache xlsxPivotCacheDefinition) string {
+	source := cache.Source.Worksheet
+	switch {
+	case source.Name != "":
+		return source.Name
+	case source.Sheet != "" && source.Ref != "":
+		return source.Sheet + "!" + source.Ref
+	case source.Ref != "":
+		return source.Ref
+	case cache.Source.Type != "" && cache.Source.Type != "worksheet":
+		return cache.Source.Type
+	}
+	return ""
+}
+
+// pivotCacheValue はレコードのfieldIndex番目の値を文字列にする（共有アイテムの番号はキャッシュの定義で解決する）
+func pivotCacheValue(cache xlsxPivotCacheDefinition, fieldIndex int, value xlsxPivotCacheValue) string {
+	switch value.XMLName.Local {
+	case "x":
+		index, err := strconv.Atoi(value.V)
+		if err != nil || fieldIndex >= len(cache.Fields) || index < 0 || index >= len(cache.Fields[fieldIndex].SharedItems.Items) {
+			return ""
+		}
+		return pivotCacheValue(cache, fieldIndex, cache.Fields[fieldIndex].SharedItems.Items[index])
+	case "m":
+		return ""
+	case "b":
+		if value.V == "1" || value.V == "true" {
+			return "TRUE"
+		}
+		return "FALSE"
+	}
+	return value.V
+}
+
+// readXMLPart はzip内のパーツを読み込んでvにデコードする
+func readXMLPart(files map[string]*zip.File, partName string, v any) error {
+	f, ok := files[partName]
+	if !ok {
+		return fmt.Errorf("%s not found", partName)
+	}
+	data, err := readZipFile(f)
+	if err != nil {
+		return err
+	}
+	if err := xml.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("error parsing XML for %s: %w", partName, err)
+	}
+	return nil
+}
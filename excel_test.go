@@ -0,0 +1,183 @@
+package documentParser
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// buildTestWorkbook はsheetsの順番（タブの順）でシートを作り、cellsの値（"シート名!セル" → 値）を設定したブックを返す
+func buildTestWorkbook(t *testing.T, sheets []string, cells map[string]any) []byte {
+	t.Helper()
+
+	f := excelize.NewFile()
+	defer f.Close()
+	if err := f.SetSheetName("Sheet1", sheets[0]); err != nil {
+		t.Fatalf("SetSheetName() error = %v", err)
+	}
+	for _, name := range sheets[1:] {
+		if _, err := f.NewSheet(name); err != nil {
+			t.Fatalf("NewSheet() error = %v", err)
+		}
+	}
+	for ref, value := range cells {
+		sheet, cell, _ := strings.Cut(ref, "!")
+		if err := f.SetCellValue(sheet, cell, value); err != nil {
+			t.Fatalf("SetCellValue(%s) error = %v", ref, err)
+		}
+	}
+
+	buf, err := f.WriteToBuffer()
+	if err != nil {
+		t.Fatalf("WriteToBuffer() error = %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestExcelParserParseSheetsOrdered(t *testing.T) {
+	tabs := []string{"Zeta", "Alpha", "Mid"}
+	data := buildTestWorkbook(t, tabs, map[string]any{
+		"Zeta!A1":  "z",
+		"Alpha!A1": "a",
+		"Mid!A1":   "m",
+	})
+
+	parser := &ExcelParser{}
+	pages, err := parser.ParseSheetsOrdered(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("ParseSheetsOrdered() error = %v", err)
+	}
+
+	var names []string
+	for i, page := range pages {
+		names = append(names, page.Name)
+		if page.Number != i+1 {
+			t.Errorf("ParseSheetsOrdered()[%d].Number = %d, want %d", i, page.Number, i+1)
+		}
+	}
+	if strings.Join(names, ",") != strings.Join(tabs, ",") {
+		t.Errorf("ParseSheetsOrdered() names = %v, want tab order %v", names, tabs)
+	}
+
+	sorted := append([]string(nil), names...)
+	sort.Strings(sorted)
+	if strings.Join(sorted, ",") == strings.Join(names, ",") {
+		t.Fatalf("test sheets are already in alphabetical order")
+	}
+
+	text, err := parser.ParseFromReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("ParseFromReader() error = %v", err)
+	}
+	z, a, m := strings.Index(text, "Zeta"), strings.Index(text, "Alpha"), strings.Index(text, "Mid")
+	if z < 0 || !(z < a && a < m) {
+		t.Errorf("ParseFromReader() sheets are not in tab order: %q", text)
+	}
+}
+
+func TestExcelParserTrimsOvershootingUsedRange(t *testing.T) {
+	f := excelize.NewFile()
+	defer f.Close()
+	style, err := f.NewStyle(&excelize.Style{Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"FFFF00"}}})
+	if err != nil {
+		t.Fatalf("NewStyle() error = %v", err)
+	}
+	// 書式だけが設定された空のセルで、使用範囲をA1:Z1000まで広げる
+	if err := f.SetCellStyle("Sheet1", "A1", "Z1000", style); err != nil {
+		t.Fatalf("SetCellStyle() error = %v", err)
+	}
+	for row, values := range [][]string{{"a", "b", "c"}, {"1", "", "3"}, {"x", "y", ""}} {
+		for col, value := range values {
+			if value == "" {
+				continue
+			}
+			cell, _ := excelize.CoordinatesToCellName(col+1, row+1)
+			if err := f.SetCellValue("Sheet1", cell, value); err != nil {
+				t.Fatalf("SetCellValue(%s) error = %v", cell, err)
+			}
+		}
+	}
+	buf, err := f.WriteToBuffer()
+	if err != nil {
+		t.Fatalf("WriteToBuffer() error = %v", err)
+	}
+	data := buf.Bytes()
+
+	trimmed, err := (&ExcelParser{}).ParseFromBytes(data)
+	if err != nil {
+		t.Fatalf("ParseFromBytes() error = %v", err)
+	}
+	kept, err := (&ExcelParser{KeepTrailingEmpty: true}).ParseFromBytes(data)
+	if err != nil {
+		t.Fatalf("ParseFromBytes(KeepTrailingEmpty) error = %v", err)
+	}
+
+	want := "# Sheet Sheet1\na | b | c\n1 |  | 3\nx | y\n\n---\n\n"
+	if trimmed != want {
+		t.Errorf("ParseFromBytes() = %q, want %q", trimmed, want)
+	}
+	if got := strings.Count(kept, "\n"); got < 1000 {
+		t.Errorf("ParseFromBytes(KeepTrailingEmpty) has %d lines, want the whole used range", got)
+	}
+}
+
+// toMacroEnabledWorkbook はブックのメインパーツの種類をマクロ有効ブックに変え、VBAプロジェクトのパーツを追加する
+func toMacroEnabledWorkbook(t *testing.T, data []byte) []byte {
+	t.Helper()
+
+	files := map[string]string{}
+	for _, f := range mustZipReader(t, data).File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("Open(%s) error = %v", f.Name, err)
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("ReadAll(%s) error = %v", f.Name, err)
+		}
+		files[f.Name] = string(content)
+	}
+
+	contentTypes := strings.Replace(files["[Content_Types].xml"],
+		"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml",
+		"application/vnd.ms-excel.sheet.macroEnabled.main+xml", 1)
+	if contentTypes == files["[Content_Types].xml"] {
+		t.Fatal("workbook content type not found in [Content_Types].xml")
+	}
+	files["[Content_Types].xml"] = strings.Replace(contentTypes, "</Types>",
+		`<Default Extension="bin" ContentType="application/vnd.ms-office.vbaProject"/></Types>`, 1)
+	// 内容はパースに使われないため、OLEのシグネチャ（暗号化されたブックと誤判定される）を含まないダミーにする
+	files["xl/vbaProject.bin"] = "vba project"
+	return buildTestZip(t, files)
+}
+
+func TestFactoryParsesMacroEnabledWorkbook(t *testing.T) {
+	factory := NewDocumentParserFactory()
+	parser, err := factory.GetParser(".xlsm")
+	if err != nil {
+		t.Fatalf("GetParser(.xlsm) error = %v", err)
+	}
+	if _, ok := parser.(*ExcelParser); !ok {
+		t.Fatalf("GetParser(.xlsm) = %T, want *ExcelParser", parser)
+	}
+
+	data := toMacroEnabledWorkbook(t, buildTestWorkbook(t, []string{"Macro"}, map[string]any{
+		"Macro!A1": "name",
+		"Macro!B1": "value",
+		"Macro!A2": "total",
+		"Macro!B2": 42,
+	}))
+	got, err := factory.ParseFromBytes(".xlsm", data)
+	if err != nil {
+		t.Fatalf("ParseFromBytes(.xlsm) error = %v", err)
+	}
+	want := "# Sheet Macro\nname | value\ntotal | 42\n\n---\n\n"
+	if got != want {
+		t.Errorf("ParseFromBytes(.xlsm) = %q, want %q", got, want)
+	}
+}
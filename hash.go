@@ -0,0 +1,42 @@
+package documentParser
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// ContentHash はドキュメントから抽出したテキストを正規化し、そのSHA-256のハッシュ値（16進数の文字列）を返す
+// 作成者や更新日時などのメタデータだけが異なるファイルは同じハッシュ値になるため、取り込み時の重複の判定に使う
+// 正規化ではUnicodeをNFCに揃え、改行を含む連続する空白を1つのスペースにまとめる
+// MaxOutputBytes は適用せずに全体をハッシュする。ページの区切りや見出しなどのファクトリーの設定は結果に影響する
+func (f *DocumentParserFactory) ContentHash(ext string, reader io.ReaderAt, size int64) (string, error) {
+	defer f.acquire()()
+
+	parser, err := f.GetParser(ext)
+	if err != nil {
+		return "", fmt.Errorf("failed to get parser: %w", err)
+	}
+
+	parser = cloneParser(parser)
+	if b, ok := parser.(baseProvider); ok {
+		b.base().MaxOutputBytes = 0
+	}
+
+	content, err := parser.ParseFromReader(reader, size)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse from reader: %w", err)
+	}
+
+	sum := sha256.Sum256([]byte(normalizeForHash(content)))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// normalizeForHash はContentHashでハッシュするテキストを正規化する
+func normalizeForHash(text string) string {
+	return strings.Join(strings.Fields(norm.NFC.String(text)), " ")
+}
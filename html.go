@@ -0,0 +1,240 @@
+package documentParser
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// HTMLParser はHTMLファイルのパーサー
+// スクリプトやスタイルを除いた本文を抽出し、見出しは "#"、リストは "- "、表は行ごとのセル区切りで出力する
+type HTMLParser struct {
+	BaseParser
+}
+
+// SupportedExtensions はサポートする拡張子を返す
+func (p *HTMLParser) SupportedExtensions() []string {
+	return []string{".html", ".htm", ".xhtml"}
+}
+
+// Capabilities はパーサーが抽出できる構造の種類を返す
+func (p *HTMLParser) Capabilities() ParserCapabilities {
+	return ParserCapabilities{Tables: true}
+}
+
+// ParseFromFile はファイルパスからHTMLをパース
+func (p *HTMLParser) ParseFromFile(filePath string) (string, error) {
+	return parseFromFileCommon(p, filePath)
+}
+
+// ParseFromBytes はバイト配列からHTMLをパース
+func (p *HTMLParser) ParseFromBytes(data []byte) (string, error) {
+	return parseFromBytesCommon(p, data)
+}
+
+// ParseFromReader はio.ReaderAtからHTMLをパース
+func (p *HTMLParser) ParseFromReader(reader io.ReaderAt, size int64) (string, error) {
+	doc, err := html.Parse(io.NewSectionReader(reader, 0, size))
+	if err != nil {
+		return "", fmt.Errorf("error parsing HTML: %w", err)
+	}
+
+	r := &htmlRenderer{cellSeparator: p.cellSeparator(" | "), maxBytes: p.MaxOutputBytes}
+	r.render(doc)
+	return truncateOutput(r.String(), p.MaxOutputBytes), nil
+}
+
+// htmlSkippedElements は内容ごと除去する要素
+var htmlSkippedElements = map[atom.Atom]bool{
+	atom.Head:     true,
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Noscript: true,
+	atom.Template: true,
+	atom.Iframe:   true,
+	atom.Svg:      true,
+}
+
+// htmlBlockElements は前後で改行するブロック要素
+var htmlBlockElements = map[atom.Atom]bool{
+	atom.P: true, atom.Div: true, atom.Section: true, atom.Article: true,
+	atom.Header: true, atom.Footer: true, atom.Nav: true, atom.Aside: true,
+	atom.Main: true, atom.Ul: true, atom.Ol: true, atom.Dl: true,
+	atom.Dt: true, atom.Dd: true, atom.Blockquote: true, atom.Figure: true,
+	atom.Figcaption: true, atom.Form: true, atom.Fieldset: true, atom.Hr: true,
+	atom.Address: true, atom.Details: true, atom.Summary: true,
+}
+
+var (
+	htmlSpacePattern     = regexp.MustCompile(`\s+`)
+	htmlBlankLinePattern = regexp.MustCompile(`\n{3,}`)
+)
+
+// htmlRenderer はHTMLのノードをテキストに変換する
+type htmlRenderer struct {
+	sb            strings.Builder
+	cellSeparator string
+	inPre         bool
+	// maxBytes が正の場合、出力がこのバイト数を超えた時点で以降のノードを描画しない
+	maxBytes int
+	// pendingSpace は次のテキストの前に空白を入れる必要があることを示す
+	pendingSpace bool
+}
+
+// String は連続した空行をまとめたテキストを返す
+func (r *htmlRenderer) String() string {
+	text := htmlBlankLinePattern.ReplaceAllString(r.sb.String(), "\n\n")
+	return strings.Trim(text, "\n") + "\n"
+}
+
+// atLineStart は行の先頭かどうかを判定する
+func (r *htmlRenderer) atLineStart() bool {
+	text := r.sb.String()
+	return text == "" || text[len(text)-1] == '\n'
+}
+
+// newline は行の途中であれば改行する
+func (r *htmlRenderer) newline() {
+	if !r.atLineStart() {
+		r.sb.WriteString("\n")
+	}
+	r.pendingSpace = false
+}
+
+// writeText は空白をまとめてテキストを書き込む
+func (r *htmlRenderer) writeText(text string) {
+	if r.inPre {
+		r.sb.WriteString(text)
+		return
+	}
+
+	text = htmlSpacePattern.ReplaceAllString(text, " ")
+	trimmed := strings.Trim(text, " ")
+	if trimmed == "" {
+		r.pendingSpace = r.pendingSpace || text != ""
+		return
+	}
+	if (r.pendingSpace || strings.HasPrefix(text, " ")) && !r.atLineStart() {
+		r.sb.WriteString(" ")
+	}
+	r.sb.WriteString(trimmed)
+	r.pendingSpace = strings.HasSuffix(text, " ")
+}
+
+func (r *htmlRenderer) render(n *html.Node) {
+	if r.maxBytes > 0 && r.sb.Len() > r.maxBytes {
+		return
+	}
+	switch n.Type {
+	case html.TextNode:
+		r.writeText(n.Data)
+		return
+	case html.ElementNode:
+		if htmlSkippedElements[n.DataAtom] {
+			return
+		}
+		switch n.DataAtom {
+		case atom.Br:
+			r.sb.WriteString("\n")
+			r.pendingSpace = false
+			return
+		case atom.Table:
+			r.newline()
+			r.renderTable(n)
+			r.newline()
+			return
+		case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+			r.newline()
+			r.sb.WriteString("\n" + strings.Repeat("#", int(n.Data[1]-'0')) + " ")
+			r.renderChildren(n)
+			r.newline()
+			return
+		case atom.Li:
+			r.newline()
+			r.sb.WriteString("- ")
+			r.renderChildren(n)
+			r.newline()
+			return
+		case atom.Pre:
+			r.newline()
+			r.inPre = true
+			r.renderChildren(n)
+			r.inPre = false
+			r.newline()
+			return
+		}
+		if htmlBlockElements[n.DataAtom] {
+			r.newline()
+			r.renderChildren(n)
+			r.newline()
+			return
+		}
+	}
+	r.renderChildren(n)
+}
+
+func (r *htmlRenderer) renderChildren(n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		r.render(c)
+	}
+}
+
+// renderTable は表を1行ずつセル区切りのテキストに変換する
+// 見出しセル（th）も通常のセルと同様に出力し、全てのセルが空の行は除外する
+func (r *htmlRenderer) renderTable(table *html.Node) {
+	for _, row := range htmlTableRows(table) {
+		if row.DataAtom == atom.Caption {
+			r.sb.WriteString(r.cellText(row) + "\n")
+			continue
+		}
+
+		var cells []string
+		hasText := false
+		for c := row.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode || (c.DataAtom != atom.Td && c.DataAtom != atom.Th) {
+				continue
+			}
+			text := r.cellText(c)
+			if text != "" {
+				hasText = true
+			}
+			cells = append(cells, text)
+		}
+		if hasText {
+			r.sb.WriteString(strings.Join(cells, r.cellSeparator) + "\n")
+		}
+	}
+}
+
+// cellText はセルの内容を1行のテキストに変換する
+func (r *htmlRenderer) cellText(cell *html.Node) string {
+	sub := &htmlRenderer{cellSeparator: r.cellSeparator}
+	sub.renderChildren(cell)
+	return strings.TrimSpace(htmlSpacePattern.ReplaceAllString(sub.sb.String(), " "))
+}
+
+// htmlTableRows は表の行（thead/tbody/tfoot内を含む）とキャプションを順番に返す
+// 入れ子の表の行は含めない
+func htmlTableRows(table *html.Node) []*html.Node {
+	var rows []*html.Node
+	for c := table.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode {
+			continue
+		}
+		switch c.DataAtom {
+		case atom.Caption, atom.Tr:
+			rows = append(rows, c)
+		case atom.Thead, atom.Tbody, atom.Tfoot:
+			for tr := c.FirstChild; tr != nil; tr = tr.NextSibling {
+				if tr.Type == html.ElementNode && tr.DataAtom == atom.Tr {
+					rows = append(rows, tr)
+				}
+			}
+		}
+	}
+	return rows
+}
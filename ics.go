@@ -0,0 +1,152 @@
+package documentParser
+
+import (
+	"io"
+	"strings"
+	"time"
+)
+
+// ICSParser はiCalendarファイル（.ics）のパーサー
+// 予定（VEVENT）ごとに件名・開始・終了・場所・説明を "Summary: 件名\nStart: 2024-01-15 09:00" のブロックにして、空行で区切って出力する
+// 折り返された行（空白で始まる継続行）はRFC 5545に従って連結する。ToDo（VTODO）やアラーム（VALARM）は出力しない
+type ICSParser struct {
+	TextParser
+}
+
+// SupportedExtensions はサポートする拡張子を返す
+func (p *ICSParser) SupportedExtensions() []string {
+	return []string{".ics", ".ical"}
+}
+
+// ParseFromFile はファイルパスからiCalendarをパース
+func (p *ICSParser) ParseFromFile(filePath string) (string, error) {
+	return parseFromFileCommon(p, filePath)
+}
+
+// ParseFromBytes はバイト配列からiCalendarをパース
+func (p *ICSParser) ParseFromBytes(data []byte) (string, error) {
+	text, err := p.bytesText(data)
+	if err != nil {
+		return "", err
+	}
+	return truncateOutput(renderICSEvents(text, p.MaxOutputBytes), p.MaxOutputBytes), nil
+}
+
+// ParseFromReader はio.ReaderAtからiCalendarをパース
+func (p *ICSParser) ParseFromReader(reader io.ReaderAt, size int64) (string, error) {
+	text, err := p.readerText(reader, size)
+	if err != nil {
+		return "", err
+	}
+	return truncateOutput(renderICSEvents(text, p.MaxOutputBytes), p.MaxOutputBytes), nil
+}
+
+// icsFields は出力するプロパティと見出し（出力の順番）
+var icsFields = []struct{ name, label string }{
+	{"SUMMARY", "Summary"},
+	{"DTSTART", "Start"},
+	{"DTEND", "End"},
+	{"LOCATION", "Location"},
+	{"DESCRIPTION", "Description"},
+}
+
+// renderICSEvents はiCalendarのテキストを予定ごとのブロックに変換する
+// maxBytesが正の場合は、出力がmaxBytesを超えた時点で以降の予定を読まない
+func renderICSEvents(text string, maxBytes int) string {
+	var events []string
+	var components []string
+	var event map[string]contentLine
+	size := 0
+lines:
+	for _, line := range unfoldContentLines(text) {
+		prop, ok := parseContentLine(line)
+		if !ok {
+			continue
+		}
+		switch prop.name {
+		case "BEGIN":
+			components = append(components, strings.ToUpper(prop.value))
+			if strings.EqualFold(prop.value, "VEVENT") {
+				event = make(map[string]contentLine)
+			}
+			continue
+		case "END":
+			if len(components) > 0 {
+				components = components[:len(components)-1]
+			}
+			if strings.EqualFold(prop.value, "VEVENT") && event != nil {
+				if block := renderICSEvent(event); block != "" {
+					events = append(events, block)
+					size += len(block) + 1
+				}
+				event = nil
+				if maxBytes > 0 && size > maxBytes {
+					break lines
+				}
+			}
+			continue
+		}
+
+		// VEVENTの直下のプロパティのみを使う（VALARMの説明などは除く）
+		if event != nil && len(components) > 0 && components[len(components)-1] == "VEVENT" {
+			if _, exists := event[prop.name]; !exists {
+				event[prop.name] = prop
+			}
+		}
+	}
+	if len(events) == 0 {
+		return ""
+	}
+	return strings.Join(events, "\n") + "\n"
+}
+
+// renderICSEvent は1つの予定のプロパティを "見出し: 値" の行にする
+func renderICSEvent(event map[string]contentLine) string {
+	var sb strings.Builder
+	for _, field := range icsFields {
+		prop, ok := event[field.name]
+		if !ok {
+			continue
+		}
+		var value string
+		if field.name == "DTSTART" || field.name == "DTEND" {
+			value = formatICSTime(prop)
+		} else {
+			value = strings.TrimSpace(unescapeContentValue(prop.value))
+		}
+		if value == "" {
+			continue
+		}
+		// 説明の改行は1行に収めるため、行ごとに " / " で区切る
+		value = strings.Join(strings.FieldsFunc(value, func(r rune) bool { return r == '\n' }), " / ")
+		sb.WriteString(field.label + ": " + value + "\n")
+	}
+	return sb.String()
+}
+
+// formatICSTime は日時（"20240115T090000Z" など）を "2024-01-15 09:00" の形式にする
+// UTCの場合は " UTC"、TZIDがある場合は " (Asia/Tokyo)" を付け、日付のみの場合は "2024-01-15" とする
+// 解釈できない値はそのまま返す
+func formatICSTime(prop contentLine) string {
+	value := strings.TrimSpace(prop.value)
+	if t, err := time.Parse("20060102", value); err == nil {
+		return t.Format("2006-01-02")
+	}
+
+	utc := strings.HasSuffix(value, "Z")
+	t, err := time.Parse("20060102T150405", strings.TrimSuffix(value, "Z"))
+	if err != nil {
+		return value
+	}
+	formatted := t.Format("2006-01-02 15:04")
+	if t.Second() != 0 {
+		formatted = t.Format("2006-01-02 15:04:05")
+	}
+	switch {
+	case utc:
+		formatted += " UTC"
+	case len(prop.params["TZID"]) > 0:
+		formatted += " (" + strings.Trim(prop.params["TZID"][0], `"`) + ")"
+	}
+	return formatted
+}
@@ -0,0 +1,176 @@
+package documentParser
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// formatNames は拡張子ごとの表示用のフォーマット名
+var formatNames = map[string]string{
+	".pdf":        "PDF Document",
+	".docx":       "Word Document",
+	".doc":        "Word Document",
+	".docm":       "Word Macro-Enabled Document",
+	".dotx":       "Word Template",
+	".dotm":       "Word Macro-Enabled Template",
+	".pptx":       "PowerPoint Presentation",
+	".ppt":        "PowerPoint Presentation",
+	".pptm":       "PowerPoint Macro-Enabled Presentation",
+	".potx":       "PowerPoint Template",
+	".potm":       "PowerPoint Macro-Enabled Template",
+	".xlsx":       "Excel Workbook",
+	".xls":        "Excel Workbook",
+	".xlsm":       "Excel Macro-Enabled Workbook",
+	".xltx":       "Excel Template",
+	".xltm":       "Excel Macro-Enabled Template",
+	".md":         "Markdown Document",
+	".markdown":   "Markdown Document",
+	".rst":        "reStructuredText Document",
+	".rest":       "reStructuredText Document",
+	".adoc":       "AsciiDoc Document",
+	".asciidoc":   "AsciiDoc Document",
+	".tex":        "LaTeX Document",
+	".latex":      "LaTeX Document",
+	".ltx":        "LaTeX Document",
+	".html":       "HTML Document",
+	".htm":        "HTML Document",
+	".xhtml":      "HTML Document",
+	".mht":        "MHTML Web Archive",
+	".mhtml":      "MHTML Web Archive",
+	".json":       "JSON File",
+	".xml":        "XML Document",
+	".properties": "Java Properties File",
+	".env":        "Environment File",
+	".csv":        "CSV File",
+	".tsv":        "TSV File",
+	".dbf":        "dBASE Table",
+	".vcf":        "vCard Contacts",
+	".vcard":      "vCard Contacts",
+	".ics":        "iCalendar File",
+	".ical":       "iCalendar File",
+	".srt":        "SubRip Subtitles",
+	".vtt":        "WebVTT Subtitles",
+	".ipynb":      "Jupyter Notebook",
+	".jsonl":      "JSON Lines",
+	".ndjson":     "JSON Lines",
+	".one":        "OneNote Section",
+	".onetoc2":    "OneNote Table of Contents",
+	".gdoc":       "Google Docs Pointer",
+	".gsheet":     "Google Sheets Pointer",
+	".gslides":    "Google Slides Pointer",
+	".pages":      "Pages Document",
+	".zip":        "ZIP Archive",
+	".tar":        "Tar Archive",
+	".tar.gz":     "Gzipped Tar Archive",
+	".tgz":        "Gzipped Tar Archive",
+	".txt":        "Text File",
+	".gz":         "Gzip Compressed File",
+	".bz2":        "Bzip2 Compressed File",
+	".zst":        "Zstandard Compressed File",
+}
+
+// formatName は拡張子に対応する表示用のフォーマット名を返す
+func formatName(ext string) string {
+	if name, ok := formatNames[ext]; ok {
+		return name
+	}
+	// ".log.gz" のような圧縮されたファイルは "LOG File (Gzip Compressed File)" とする
+	if dot := strings.LastIndex(ext, "."); dot > 0 {
+		if compression, ok := formatNames[ext[dot:]]; ok {
+			return fmt.Sprintf("%s (%s)", formatName(ext[:dot]), compression)
+		}
+	}
+	return strings.ToUpper(strings.TrimPrefix(ext, ".")) + " File"
+}
+
+// IdentifyFile はファイルの内容をパースせずに、フォーマット名と対応するパーサーを返す
+// 拡張子で判定し、拡張子がない・未対応の場合はファイル先頭のマジックナンバーで判定する
+func (f *DocumentParserFactory) IdentifyFile(filePath string) (string, DocumentParser, error) {
+	if ext := normalizeExtension(f.fileExtension(filePath)); ext != "." {
+		if parser, err := f.GetParser(ext); err == nil {
+			return formatName(ext), parser, nil
+		}
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get file stats: %w", err)
+	}
+
+	ext := sniffExtension(file, stat.Size())
+	if parser, ok := f.parsers[ext]; ok {
+		return formatName(ext), parser, nil
+	}
+
+	return "", nil, fmt.Errorf("unsupported file format: %s", filePath)
+}
+
+// sniffExtension はファイル先頭のマジックナンバーから拡張子を推定する
+// ZIP形式の場合はOffice文書のディレクトリ構成から種類を判定する
+func sniffExtension(reader io.ReaderAt, size int64) string {
+	header := make([]byte, 512)
+	n, err := reader.ReadAt(header, 0)
+	if err != nil && err != io.EOF {
+		return ""
+	}
+	header = header[:n]
+
+	switch {
+	case bytes.HasPrefix(header, []byte("%PDF-")):
+		return ".pdf"
+	case bytes.HasPrefix(header, []byte{0x1f, 0x8b}):
+		// 展開した先頭がtarのヘッダーの場合のみ .tar.gz とし、それ以外はDecompressingParserに任せる
+		if isTarHeader(gzipHeader(reader, size)) {
+			return ".tar.gz"
+		}
+		return ".gz"
+	case isTarHeader(header):
+		return ".tar"
+	case bytes.HasPrefix(header, []byte("PK\x03\x04")):
+		r, err := zip.NewReader(reader, size)
+		if err != nil {
+			return ""
+		}
+		files := zipFileMap(r)
+		switch {
+		case files["word/document.xml"] != nil:
+			return ".docx"
+		case files["ppt/presentation.xml"] != nil:
+			return ".pptx"
+		case files[xlsxWorkbookPart] != nil:
+			return ".xlsx"
+		case files[pagesIndexGzip] != nil, files[pagesIWAIndex] != nil:
+			return ".pages"
+		}
+		return ".zip"
+	}
+	return ""
+}
+
+// isTarHeader はtarのヘッダー（オフセット257の "ustar"）で始まるかどうかを判定する
+func isTarHeader(header []byte) bool {
+	return len(header) >= 262 && bytes.Equal(header[257:262], []byte("ustar"))
+}
+
+// gzipHeader はgzipで圧縮されたデータを展開した先頭512バイト（tarの1ブロック分）を返す
+func gzipHeader(reader io.ReaderAt, size int64) []byte {
+	gz, err := gzip.NewReader(io.NewSectionReader(reader, 0, size))
+	if err != nil {
+		return nil
+	}
+	defer gz.Close()
+	header := make([]byte, 512)
+	n, _ := io.ReadFull(gz, header)
+	return header[:n]
+}
@@ -0,0 +1,66 @@
+package documentParser
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+// ParseInfo はParseFromFileWithInfoが返すパースの情報（フォーマットごとのメトリクスの記録などに使う）
+type ParseInfo struct {
+	// Extension はパーサーの選択に使った拡張子（例: ".pdf"）
+	Extension string
+	// Format は表示用のフォーマット名（例: "PDF Document"）
+	Format string
+	// Duration はパースにかかった時間（SetMaxConcurrencyによる待ち時間とページ数の取得は含まない）
+	Duration time.Duration
+	// Pages はページ/スライド/シート数（PageCounterを実装していないパーサーでは -1）
+	Pages int
+}
+
+// ParseFromFileWithInfo はParseFromFileと同じようにファイルをパースし、使用した拡張子・フォーマット名・所要時間・ページ数を合わせて返す
+// パースに失敗した場合も、分かっている範囲の情報（拡張子・フォーマット名・所要時間）を返す
+func (f *DocumentParserFactory) ParseFromFileWithInfo(filePath string) (string, ParseInfo, error) {
+	defer f.acquire()()
+
+	ext := normalizeExtension(f.fileExtension(filePath))
+	info := ParseInfo{Extension: ext, Format: formatName(ext), Pages: -1}
+	parser, err := f.GetParser(ext)
+	if err != nil {
+		return "", info, fmt.Errorf("failed to get parser: %w", err)
+	}
+
+	start := time.Now()
+	content, err := parser.ParseFromFile(filePath)
+	info.Duration = time.Since(start)
+	if err != nil {
+		return "", info, fmt.Errorf("failed to parse file: %w", err)
+	}
+
+	if counter, ok := parser.(PageCounter); ok {
+		if pages, err := countFilePages(counter, filePath); err == nil {
+			info.Pages = pages
+		} else {
+			log.Printf("Error counting pages of %s: %s", filePath, err)
+		}
+	}
+
+	return limitOutput(parser, content), info, nil
+}
+
+// countFilePages はファイルを開いてPageCounterでページ数を数える
+func countFilePages(counter PageCounter, filePath string) (int, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get file stats: %w", err)
+	}
+
+	return counter.PageCount(file, stat.Size())
+}
@@ -0,0 +1,150 @@
+package documentParser
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// IPYNBParser はJupyter Notebook（.ipynb）のパーサー
+// Markdownセルはそのまま、コードセルはカーネルの言語名付きのコードブロックとして出力する
+type IPYNBParser struct {
+	BaseParser
+
+	// IncludeOutputs が true の場合、コードセルの出力のうちテキスト（stream、text/plain、エラー）を
+	// コードブロックの後に追加する。画像などのバイナリ出力は含めない
+	IncludeOutputs bool
+}
+
+// SupportedExtensions はサポートする拡張子を返す
+func (p *IPYNBParser) SupportedExtensions() []string {
+	return []string{".ipynb"}
+}
+
+// ParseFromFile はファイルパスからNotebookをパース
+func (p *IPYNBParser) ParseFromFile(filePath string) (string, error) {
+	return parseFromFileCommon(p, filePath)
+}
+
+// ParseFromBytes はバイト配列からNotebookをパース
+func (p *IPYNBParser) ParseFromBytes(data []byte) (string, error) {
+	return parseFromBytesCommon(p, data)
+}
+
+// notebookText は文字列または文字列の配列で表現される複数行テキスト
+type notebookText string
+
+// UnmarshalJSON は文字列と文字列の配列の両方を受け付ける
+func (t *notebookText) UnmarshalJSON(data []byte) error {
+	var lines []string
+	if err := json.Unmarshal(data, &lines); err == nil {
+		*t = notebookText(strings.Join(lines, ""))
+		return nil
+	}
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return err
+	}
+	*t = notebookText(text)
+	return nil
+}
+
+// notebook はNotebookのJSON構造を表現する構造体
+type notebook struct {
+	Metadata struct {
+		KernelSpec struct {
+			Language string `json:"language"`
+		} `json:"kernelspec"`
+		LanguageInfo struct {
+			Name string `json:"name"`
+		} `json:"language_info"`
+	} `json:"metadata"`
+	Cells []notebookCell `json:"cells"`
+}
+
+type notebookCell struct {
+	CellType string           `json:"cell_type"`
+	Source   notebookText     `json:"source"`
+	Outputs  []notebookOutput `json:"outputs"`
+}
+
+type notebookOutput struct {
+	OutputType string                  `json:"output_type"`
+	Text       notebookText            `json:"text"`
+	Data       map[string]notebookText `json:"data"`
+	EName      string                  `json:"ename"`
+	EValue     string                  `json:"evalue"`
+}
+
+// language はコードブロックに付ける言語名を返す
+func (n *notebook) language() string {
+	if n.Metadata.LanguageInfo.Name != "" {
+		return n.Metadata.LanguageInfo.Name
+	}
+	if n.Metadata.KernelSpec.Language != "" {
+		return n.Metadata.KernelSpec.Language
+	}
+	return "python"
+}
+
+// ParseFromReader はio.ReaderAtからNotebookをパース
+func (p *IPYNBParser) ParseFromReader(reader io.ReaderAt, size int64) (string, error) {
+	var nb notebook
+	if err := json.NewDecoder(io.NewSectionReader(reader, 0, size)).Decode(&nb); err != nil {
+		return "", fmt.Errorf("error parsing notebook: %w", err)
+	}
+
+	language := nb.language()
+	var cells []string
+	total := 0
+	for _, cell := range nb.Cells {
+		if p.outputLimitReached(total) {
+			break
+		}
+		source := strings.TrimRight(string(cell.Source), "\n")
+		switch cell.CellType {
+		case "code":
+			output := ""
+			if p.IncludeOutputs {
+				output = notebookOutputText(cell.Outputs)
+			}
+			if source == "" && output == "" {
+				continue
+			}
+			text := fmt.Sprintf("```%s\n%s\n```", language, source)
+			if output != "" {
+				text += fmt.Sprintf("\n\n```text\n%s\n```", output)
+			}
+			cells = append(cells, text)
+			total += len(text) + 2
+		default:
+			if source != "" {
+				cells = append(cells, source)
+				total += len(source) + 2
+			}
+		}
+	}
+
+	return truncateOutput(strings.Join(cells, "\n\n"), p.MaxOutputBytes), nil
+}
+
+// notebookOutputText はコードセルの出力からテキストを取り出す
+func notebookOutputText(outputs []notebookOutput) string {
+	var parts []string
+	for _, output := range outputs {
+		var text string
+		switch output.OutputType {
+		case "stream":
+			text = string(output.Text)
+		case "execute_result", "display_data":
+			text = string(output.Data["text/plain"])
+		case "error":
+			text = fmt.Sprintf("%s: %s", output.EName, output.EValue)
+		}
+		if text = strings.TrimRight(text, "\n"); text != "" {
+			parts = append(parts, text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
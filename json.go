@@ -0,0 +1,88 @@
+package documentParser
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+)
+
+// JSONParser はJSONファイルのパーサー
+// デフォルトではTextParserと同様に内容をそのまま返す
+type JSONParser struct {
+	TextParser
+
+	// Flatten が true の場合、スカラー値を "server.port: 8080" のようなパス付きの行に展開する
+	// ネストしたキーは "." で、配列の要素は "db.hosts[0]" のように連結し、キーはソートして出力する
+	// JSONとして読めない場合（コメント付きの設定ファイルなど）はログに出力して内容をそのまま返す
+	Flatten bool
+}
+
+// SupportedExtensions はサポートする拡張子を返す
+func (p *JSONParser) SupportedExtensions() []string {
+	return []string{".json"}
+}
+
+// ParseFromFile はファイルパスからJSONをパース
+func (p *JSONParser) ParseFromFile(filePath string) (string, error) {
+	return parseFromFileCommon(p, filePath)
+}
+
+// ParseFromBytes はバイト配列からJSONをパース
+func (p *JSONParser) ParseFromBytes(data []byte) (string, error) {
+	if !p.Flatten {
+		return p.TextParser.ParseFromBytes(data)
+	}
+	raw := p.rawParser()
+	text, err := raw.ParseFromBytes(data)
+	if err != nil {
+		return "", err
+	}
+	return truncateOutput(flattenJSONText(text), p.MaxOutputBytes), nil
+}
+
+// ParseFromReader はio.ReaderAtからJSONをパース
+func (p *JSONParser) ParseFromReader(reader io.ReaderAt, size int64) (string, error) {
+	if !p.Flatten {
+		return p.TextParser.ParseFromReader(reader, size)
+	}
+	raw := p.rawParser()
+	text, err := raw.ParseFromReader(reader, size)
+	if err != nil {
+		return "", err
+	}
+	return truncateOutput(flattenJSONText(text), p.MaxOutputBytes), nil
+}
+
+// rawParser は展開する前の全体を読み込むためのTextParserを返す
+// 途中で切り詰めたJSONは読めないため、MaxOutputBytesは展開した後に適用し、HeadLines・TailLinesは使わない
+func (p *JSONParser) rawParser() *TextParser {
+	raw := p.TextParser
+	raw.MaxOutputBytes, raw.HeadLines, raw.TailLines = 0, 0, 0
+	return &raw
+}
+
+// flattenJSONText はJSONの文書をパス付きの "key: value" の行に展開する
+func flattenJSONText(text string) string {
+	// 数値を丸めずに出力するためjson.Numberとしてデコードする
+	decoder := json.NewDecoder(strings.NewReader(strings.TrimPrefix(text, "\ufeff")))
+	decoder.UseNumber()
+
+	var value any
+	err := decoder.Decode(&value)
+	if err == nil && decoder.More() {
+		err = fmt.Errorf("unexpected data after JSON value")
+	}
+	if err != nil {
+		log.Printf("invalid JSON, returning raw text: %v", err)
+		return text
+	}
+
+	var lines []string
+	flattenJSON("", value, &lines)
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -0,0 +1,140 @@
+package documentParser
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strings"
+)
+
+// JSONLParser は改行区切りJSON（JSON Lines / NDJSON）ファイルのパーサー
+// 各行を独立したJSONとして検証し、不正な行は行番号付きでログに出力してスキップする
+type JSONLParser struct {
+	BaseParser
+
+	// Pretty が true の場合、各レコードをインデント付きで出力する
+	Pretty bool
+
+	// Flatten が true の場合、各レコードのスカラー値を "key: value" の行に展開する
+	// ネストしたキーは "." で、配列の要素は "[0]" で連結する（Pretty より優先される）
+	Flatten bool
+}
+
+// SupportedExtensions はサポートする拡張子を返す
+func (p *JSONLParser) SupportedExtensions() []string {
+	return []string{".jsonl", ".ndjson"}
+}
+
+// ParseFromFile はファイルパスからJSON Linesをパース
+func (p *JSONLParser) ParseFromFile(filePath string) (string, error) {
+	return parseFromFileCommon(p, filePath)
+}
+
+// ParseFromBytes はバイト配列からJSON Linesをパース
+func (p *JSONLParser) ParseFromBytes(data []byte) (string, error) {
+	return parseFromBytesCommon(p, data)
+}
+
+// ParseFromReader はio.ReaderAtからJSON Linesをパース
+func (p *JSONLParser) ParseFromReader(reader io.ReaderAt, size int64) (string, error) {
+	br := bufio.NewReader(io.NewSectionReader(reader, 0, size))
+
+	var sb strings.Builder
+	records := 0
+	for lineNumber := 1; ; lineNumber++ {
+		line, err := br.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return "", fmt.Errorf("error reading JSON Lines file: %w", err)
+		}
+
+		if record := bytes.TrimSpace(line); len(record) > 0 {
+			if text, ok := p.renderRecord(record, lineNumber); ok {
+				if records > 0 && (p.Pretty || p.Flatten) {
+					sb.WriteString("\n")
+				}
+				sb.WriteString(text)
+				sb.WriteString("\n")
+				records++
+			}
+		}
+
+		if err == io.EOF || p.outputLimitReached(sb.Len()) {
+			break
+		}
+	}
+
+	return truncateOutput(sb.String(), p.MaxOutputBytes), nil
+}
+
+// renderRecord は1行分のJSONを出力形式に変換する
+func (p *JSONLParser) renderRecord(record []byte, lineNumber int) (string, bool) {
+	// 数値を丸めずに出力するためjson.Numberとしてデコードする
+	decoder := json.NewDecoder(bytes.NewReader(record))
+	decoder.UseNumber()
+
+	var value any
+	err := decoder.Decode(&value)
+	if err == nil && decoder.More() {
+		err = fmt.Errorf("unexpected data after JSON value")
+	}
+	if err != nil {
+		log.Printf("invalid JSON at line %d: %v", lineNumber, err)
+		return "", false
+	}
+
+	switch {
+	case p.Flatten:
+		var lines []string
+		flattenJSON("", value, &lines)
+		return strings.Join(lines, "\n"), true
+	case p.Pretty:
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, record, "", "  "); err != nil {
+			log.Printf("invalid JSON at line %d: %v", lineNumber, err)
+			return "", false
+		}
+		return buf.String(), true
+	default:
+		return string(record), true
+	}
+}
+
+// flattenJSON はスカラー値を "key: value" の行として追加する
+// オブジェクトのキーは出力を安定させるためソートする
+func flattenJSON(prefix string, value any, lines *[]string) {
+	switch v := value.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			name := key
+			if prefix != "" {
+				name = prefix + "." + key
+			}
+			flattenJSON(name, v[key], lines)
+		}
+	case []any:
+		for i, item := range v {
+			flattenJSON(fmt.Sprintf("%s[%d]", prefix, i), item, lines)
+		}
+	case nil:
+		*lines = append(*lines, fmt.Sprintf("%s: null", jsonKeyOrValue(prefix)))
+	default:
+		*lines = append(*lines, fmt.Sprintf("%s: %v", jsonKeyOrValue(prefix), v))
+	}
+}
+
+// jsonKeyOrValue はトップレベルがスカラー値の場合のキー名を返す
+func jsonKeyOrValue(prefix string) string {
+	if prefix == "" {
+		return "value"
+	}
+	return prefix
+}
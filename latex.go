@@ -0,0 +1,414 @@
+package documentParser
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// LaTeXParser はLaTeXのソースファイル（.tex）のパーサー
+// プリアンブル・コメント・レイアウト用のコマンドを除去し、見出しをMarkdown形式に変換した本文を返す
+// 完全なLaTeXの解釈は行わず、よく使われるコマンドと環境のみを扱う
+type LaTeXParser struct {
+	TextParser
+
+	// Raw が true の場合、記法を除去せずに内容をそのまま返す
+	Raw bool
+}
+
+// SupportedExtensions はサポートする拡張子を返す
+func (p *LaTeXParser) SupportedExtensions() []string {
+	return []string{".tex", ".latex", ".ltx"}
+}
+
+// ParseFromFile はファイルパスからLaTeXをパース
+func (p *LaTeXParser) ParseFromFile(filePath string) (string, error) {
+	return parseFromFileCommon(p, filePath)
+}
+
+// ParseFromBytes はバイト配列からLaTeXをパース
+func (p *LaTeXParser) ParseFromBytes(data []byte) (string, error) {
+	text, err := p.bytesText(data)
+	if err != nil {
+		return "", err
+	}
+	return truncateOutput(p.render(text), p.MaxOutputBytes), nil
+}
+
+// ParseFromReader はio.ReaderAtからLaTeXをパース
+func (p *LaTeXParser) ParseFromReader(reader io.ReaderAt, size int64) (string, error) {
+	text, err := p.readerText(reader, size)
+	if err != nil {
+		return "", err
+	}
+	return truncateOutput(p.render(text), p.MaxOutputBytes), nil
+}
+
+func (p *LaTeXParser) render(text string) string {
+	if p.Raw {
+		return text
+	}
+	return stripLaTeX(text)
+}
+
+var (
+	// latexVerbatimPatterns は内容をそのまま残す環境（minted は言語名の引数を持つ）
+	latexVerbatimPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`(?s)\\begin\{verbatim\*?\}\n?(.*?)\\end\{verbatim\*?\}`),
+		regexp.MustCompile(`(?s)\\begin\{lstlisting\}(?:\[[^\]]*\])?\n?(.*?)\\end\{lstlisting\}`),
+		regexp.MustCompile(`(?s)\\begin\{minted\}(?:\[[^\]]*\])?\{[^}]*\}\n?(.*?)\\end\{minted\}`),
+	}
+	// latexDroppedEnvironmentPattern は内容ごと除去する環境
+	latexDroppedEnvironmentPattern = regexp.MustCompile(`(?s)\\begin\{(comment|tikzpicture|pgfpicture)\}.*?\\end\{(comment|tikzpicture|pgfpicture)\}`)
+	latexSpacesPattern             = regexp.MustCompile(`[ \t]+`)
+)
+
+// latexHeadings は見出しのコマンドとMarkdownの見出し記号
+var latexHeadings = map[string]string{
+	"part":          "#",
+	"chapter":       "#",
+	"section":       "##",
+	"subsection":    "###",
+	"subsubsection": "####",
+	"paragraph":     "#####",
+	"subparagraph":  "######",
+}
+
+// latexDroppedCommands は引数ごと除去するコマンド
+var latexDroppedCommands = map[string]bool{
+	"label": true, "includegraphics": true, "bibliographystyle": true, "bibliography": true,
+	"usepackage": true, "documentclass": true, "newcommand": true, "renewcommand": true,
+	"vspace": true, "hspace": true, "setlength": true, "addtolength": true, "input": true,
+	"include": true, "pagestyle": true, "thispagestyle": true, "index": true, "cline": true,
+}
+
+// latexIgnoredCommands は引数を取らず、出力に影響しないレイアウト用のコマンド
+var latexIgnoredCommands = map[string]bool{
+	"maketitle": true, "tableofcontents": true, "newpage": true, "clearpage": true,
+	"noindent": true, "centering": true, "hline": true, "toprule": true, "midrule": true,
+	"bottomrule": true, "small": true, "large": true, "Large": true, "footnotesize": true,
+	"normalsize": true, "bigskip": true, "medskip": true, "smallskip": true, "par": true,
+	"linewidth": true, "textwidth": true, "bf": true, "it": true, "em": true, "tt": true,
+}
+
+// latexSymbols は記号を表すコマンドの置き換え
+var latexSymbols = map[string]string{
+	"ldots": "…", "dots": "…", "LaTeX": "LaTeX", "TeX": "TeX", "textbackslash": "\\",
+	"textendash": "–", "textemdash": "—", "S": "§", "copyright": "©", "textregistered": "®",
+	"today": "", "quad": " ", "qquad": " ",
+}
+
+// latexCiteCommands は引用のコマンド（"[キー]" として出力する）
+var latexCiteCommands = map[string]bool{
+	"cite": true, "citep": true, "citet": true, "parencite": true, "textcite": true, "autocite": true,
+}
+
+// latexRefCommands は相互参照のコマンド（参照先のラベルをそのまま出力する）
+var latexRefCommands = map[string]bool{
+	"ref": true, "eqref": true, "pageref": true, "autoref": true, "cref": true, "Cref": true,
+}
+
+// stripLaTeX はLaTeXの記法を除去してプレーンテキストに変換する
+func stripLaTeX(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+
+	// 逐語環境は以降の処理の対象外にするため、置き換え用の印に退避する
+	var verbatims []string
+	for _, pattern := range latexVerbatimPatterns {
+		text = pattern.ReplaceAllStringFunc(text, func(match string) string {
+			verbatims = append(verbatims, strings.TrimRight(pattern.FindStringSubmatch(match)[1], "\n"))
+			return fmt.Sprintf("\n\x00%d\x00\n", len(verbatims)-1)
+		})
+	}
+
+	text = stripLaTeXComments(text)
+
+	// プリアンブル（\begin{document} より前）はタイトルのみを使う
+	title := ""
+	if before, body, ok := strings.Cut(text, `\begin{document}`); ok {
+		if i := strings.Index(before, `\title`); i >= 0 {
+			if group, _, ok := latexGroup(before, i+len(`\title`), '{', '}'); ok {
+				title = strings.TrimSpace(latexInline(group))
+			}
+		}
+		text = body
+	}
+	text, _, _ = strings.Cut(text, `\end{document}`)
+	text = latexDroppedEnvironmentPattern.ReplaceAllString(text, "")
+
+	text = latexInline(text)
+	if title != "" {
+		text = "# " + title + "\n\n" + text
+	}
+
+	// 行ごとに余分な空白を除き、連続した空行を1行にまとめる
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(latexSpacesPattern.ReplaceAllString(line, " "))
+		if line == "" && (len(lines) == 0 || lines[len(lines)-1] == "") {
+			continue
+		}
+		if strings.HasPrefix(line, "\x00") && strings.HasSuffix(line, "\x00") {
+			var index int
+			if _, err := fmt.Sscanf(strings.Trim(line, "\x00"), "%d", &index); err == nil && index < len(verbatims) {
+				line = verbatims[index]
+			}
+		}
+		lines = append(lines, line)
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n")) + "\n"
+}
+
+// stripLaTeXComments はエスケープされていない "%" から行末までのコメントを除去する
+// コメントのみの行は行ごと除去する
+func stripLaTeXComments(text string) string {
+	lines := strings.Split(text, "\n")
+	result := lines[:0]
+	for _, line := range lines {
+		cut := -1
+		for i := 0; i < len(line); i++ {
+			if line[i] == '\\' {
+				i++
+				continue
+			}
+			if line[i] == '%' {
+				cut = i
+				break
+			}
+		}
+		if cut < 0 {
+			result = append(result, line)
+			continue
+		}
+		if strings.TrimSpace(line[:cut]) == "" {
+			continue
+		}
+		result = append(result, line[:cut])
+	}
+	return strings.Join(result, "\n")
+}
+
+// latexGroup はtext[start:]の空白に続く open〜close で囲まれた部分（入れ子とエスケープを考慮）を返す
+// 見つからない場合は ok が false になる
+func latexGroup(text string, start int, open, close byte) (group string, end int, ok bool) {
+	i := start
+	for i < len(text) && (text[i] == ' ' || text[i] == '\t') {
+		i++
+	}
+	if i >= len(text) || text[i] != open {
+		return "", start, false
+	}
+
+	depth := 0
+	for j := i; j < len(text); j++ {
+		switch text[j] {
+		case '\\':
+			j++
+		case open:
+			depth++
+		case close:
+			depth--
+			if depth == 0 {
+				return text[i+1 : j], j + 1, true
+			}
+		}
+	}
+	return "", start, false
+}
+
+// latexInline はコマンド・グループ・エスケープを変換する
+// 数式（$...$、\(...\)、\[...\]）は記号をそのまま残す
+func latexInline(text string) string {
+	var sb strings.Builder
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case c == '$':
+			delimiter := "$"
+			if strings.HasPrefix(text[i:], "$$") {
+				delimiter = "$$"
+			}
+			end := strings.Index(text[i+len(delimiter):], delimiter)
+			if end < 0 {
+				sb.WriteByte(c)
+				continue
+			}
+			next := i + len(delimiter) + end + len(delimiter)
+			sb.WriteString(text[i:next])
+			i = next - 1
+		case c == '\\' && i+1 < len(text) && (text[i+1] == '(' || text[i+1] == '['):
+			closer := `\)`
+			if text[i+1] == '[' {
+				closer = `\]`
+			}
+			end := strings.Index(text[i+2:], closer)
+			if end < 0 {
+				sb.WriteString(text[i : i+2])
+				i++
+				continue
+			}
+			sb.WriteString(text[i+2 : i+2+end])
+			i += 2 + end + 1
+		case c == '\\':
+			i = latexCommand(text, i, &sb)
+		case c == '{' || c == '}':
+			// 書式の範囲を示すだけのグループは括弧を除く
+		case c == '~':
+			sb.WriteByte(' ')
+		case c == '&':
+			sb.WriteString(" | ")
+		case strings.HasPrefix(text[i:], "---"):
+			sb.WriteString("—")
+			i += 2
+		case strings.HasPrefix(text[i:], "--"):
+			sb.WriteString("–")
+			i++
+		case strings.HasPrefix(text[i:], "``"), strings.HasPrefix(text[i:], "''"):
+			sb.WriteByte('"')
+			i++
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
+
+// latexCommand はtext[start]の "\" から始まるコマンドを変換してsbに書き込み、最後に読んだ位置を返す
+func latexCommand(text string, start int, sb *strings.Builder) int {
+	i := start + 1
+	if i >= len(text) {
+		return start
+	}
+
+	// 記号1文字のコマンド（エスケープ、改行、空白）
+	if !isASCIILetter(text[i]) {
+		switch text[i] {
+		case '\\':
+			// 行末の "\\" で空行にならないよう、直後の改行までを読み飛ばす
+			sb.WriteByte('\n')
+			for i+1 < len(text) && (text[i+1] == ' ' || text[i+1] == '\t') {
+				i++
+			}
+			if i+1 < len(text) && text[i+1] == '\n' {
+				i++
+			}
+		case ',', ';', ' ', ':':
+			sb.WriteByte(' ')
+		case '!', '/', '-':
+		default:
+			sb.WriteByte(text[i])
+		}
+		return i
+	}
+
+	nameEnd := i
+	for nameEnd < len(text) && isASCIILetter(text[nameEnd]) {
+		nameEnd++
+	}
+	name := text[i:nameEnd]
+	i = nameEnd
+	if i < len(text) && text[i] == '*' {
+		i++
+	}
+
+	// オプション引数（[...]）と必須引数（{...}）を読む
+	// \begin{figure}[h] のようにオプション引数が後に続く場合もあるため、引数の数に達するまで順不同で読む
+	var option string
+	hasOption := false
+	var args []string
+	n := latexArgCount(name)
+	for {
+		if group, end, ok := latexGroup(text, i, '[', ']'); ok && !hasOption {
+			option, hasOption, i = group, true, end
+			continue
+		}
+		if n >= 0 && len(args) >= n {
+			break
+		}
+		group, end, ok := latexGroup(text, i, '{', '}')
+		if !ok {
+			break
+		}
+		args = append(args, group)
+		i = end
+	}
+	arg := func(n int) string {
+		if n >= 0 && n < len(args) {
+			return args[n]
+		}
+		return ""
+	}
+
+	switch {
+	case latexHeadings[name] != "":
+		sb.WriteString("\n\n" + latexHeadings[name] + " " + strings.TrimSpace(latexInline(arg(0))) + "\n\n")
+	case name == "begin" && arg(0) == "abstract":
+		sb.WriteString("\n\n## Abstract\n\n")
+	case name == "begin" || name == "end":
+		sb.WriteByte('\n')
+	case name == "item":
+		if !latexAtLineStart(sb.String()) {
+			sb.WriteByte('\n')
+		}
+		sb.WriteString("- ")
+		if hasOption {
+			sb.WriteString(latexInline(option) + " ")
+		}
+	case name == "caption":
+		sb.WriteString("\n" + latexInline(arg(0)) + "\n")
+	case name == "href":
+		sb.WriteString(latexInline(arg(1)) + " (" + arg(0) + ")")
+	case name == "url":
+		sb.WriteString(arg(0))
+	case name == "footnote":
+		sb.WriteString(" (" + latexInline(arg(0)) + ")")
+	case latexCiteCommands[name]:
+		sb.WriteString("[" + strings.ReplaceAll(arg(0), ",", ", ") + "]")
+	case latexRefCommands[name]:
+		sb.WriteString(arg(0))
+	case name == "textcolor" || name == "colorbox":
+		sb.WriteString(latexInline(arg(len(args) - 1)))
+	case latexDroppedCommands[name], latexIgnoredCommands[name]:
+	case latexSymbols[name] != "" || name == "today":
+		sb.WriteString(latexSymbols[name])
+	default:
+		// \textbf{...} などの書式のコマンドは引数の内容のみを残す
+		for n, a := range args {
+			if n > 0 {
+				sb.WriteByte(' ')
+			}
+			sb.WriteString(latexInline(a))
+		}
+	}
+	return i - 1
+}
+
+// latexArgCount はコマンドが取る必須引数の数を返す
+// 未知のコマンドは-1を返し、直後に続く全てのグループを引数とみなす
+func latexArgCount(name string) int {
+	switch {
+	case name == "href" || name == "textcolor" || name == "colorbox":
+		return 2
+	case name == "setlength" || name == "addtolength" || name == "newcommand" || name == "renewcommand":
+		return 2
+	case name == "begin":
+		return -1
+	case name == "item" || latexIgnoredCommands[name] || latexSymbols[name] != "" || name == "today":
+		return 0
+	case latexHeadings[name] != "" || latexDroppedCommands[name] || latexCiteCommands[name] || latexRefCommands[name]:
+		return 1
+	}
+	return -1
+}
+
+// latexAtLineStart は出力の最後の行が空白のみかどうかを判定する
+func latexAtLineStart(output string) bool {
+	return strings.TrimSpace(output[strings.LastIndexByte(output, '\n')+1:]) == ""
+}
+
+// isASCIILetter はASCIIの英字かどうかを判定する
+func isASCIILetter(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
@@ -0,0 +1,32 @@
+package documentParser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLaTeXParserBareColorCommands(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"bare textcolor", `\textcolor`, ""},
+		{"bare colorbox", `before \colorbox after`, "before after"},
+		{"textcolor with text", `\textcolor{red}{warning}`, "warning"},
+		{"colorbox with text", `\colorbox{yellow}{note}`, "note"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := &LaTeXParser{}
+			got, err := parser.ParseFromBytes([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("ParseFromBytes() error = %v", err)
+			}
+			if strings.TrimSpace(got) != tt.want {
+				t.Errorf("ParseFromBytes() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package documentParser
+
+import (
+	"io"
+	"regexp"
+	"strings"
+)
+
+// MarkdownParser はMarkdownファイルのパーサー
+// デフォルトではTextParserと同様に内容をそのまま返す
+type MarkdownParser struct {
+	TextParser
+
+	// StripMarkdown が true の場合、Markdown記法を除去してプレーンテキストに変換する
+	// GFMのテーブルは区切り行を除いた " | " 区切りの行として出力する
+	StripMarkdown bool
+}
+
+// SupportedExtensions はサポートする拡張子を返す
+func (p *MarkdownParser) SupportedExtensions() []string {
+	return []string{
+		".md",       // Markdown
+		".markdown", // Markdown (別拡張子)
+		".mdown",    // Markdown (別拡張子)
+		".mkd",      // Markdown (別拡張子)
+		".mdwn",     // Markdown (別拡張子)
+		".mkdn",     // Markdown (別拡張子)
+		".mdtxt",    // Markdown (別拡張子)
+		".mdtext",   // Markdown (別拡張子)
+	}
+}
+
+// Capabilities はパーサーが抽出できる構造の種類を返す
+func (p *MarkdownParser) Capabilities() ParserCapabilities {
+	return ParserCapabilities{Tables: true}
+}
+
+// ParseFromFile はファイルパスからMarkdownをパース
+func (p *MarkdownParser) ParseFromFile(filePath string) (string, error) {
+	return parseFromFileCommon(p, filePath)
+}
+
+// ParseFromBytes はバイト配列からMarkdownをパース
+func (p *MarkdownParser) ParseFromBytes(data []byte) (string, error) {
+	text, err := p.bytesText(data)
+	if err != nil {
+		return "", err
+	}
+	return truncateOutput(p.render(text), p.MaxOutputBytes), nil
+}
+
+// ParseFromReader はio.ReaderAtからMarkdownをパース
+func (p *MarkdownParser) ParseFromReader(reader io.ReaderAt, size int64) (string, error) {
+	text, err := p.readerText(reader, size)
+	if err != nil {
+		return "", err
+	}
+	return truncateOutput(p.render(text), p.MaxOutputBytes), nil
+}
+
+func (p *MarkdownParser) render(text string) string {
+	if !p.StripMarkdown {
+		return text
+	}
+	return stripMarkdown(text)
+}
+
+var (
+	mdHeadingPattern    = regexp.MustCompile(`^\s{0,3}#{1,6}\s+`)
+	mdQuotePattern      = regexp.MustCompile(`^\s{0,3}>\s?`)
+	mdListPattern       = regexp.MustCompile(`^(\s*)[*+]\s+`)
+	mdRulePattern       = regexp.MustCompile(`^\s{0,3}([-*_])(\s*([-*_])){2,}\s*$`)
+	mdFencePattern      = regexp.MustCompile("^\\s{0,3}(```|~~~)")
+	mdImagePattern      = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	mdLinkPattern       = regexp.MustCompile(`\[([^\]]+)\]\([^)]*\)`)
+	mdBoldPattern       = regexp.MustCompile(`(\*\*|__)(.+?)(\*\*|__)`)
+	mdItalicPattern     = regexp.MustCompile(`(^|[^*\w])\*([^*\s][^*]*)\*`)
+	mdStrikePattern     = regexp.MustCompile(`~~(.+?)~~`)
+	mdCodePattern       = regexp.MustCompile("`([^`]+)`")
+	mdTableDelimPattern = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+)
+
+// stripMarkdown はMarkdown記法を除去してプレーンテキストに変換する
+func stripMarkdown(text string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+
+	var result []string
+	inFence := false
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		// コードブロックは中身をそのまま残し、フェンスのみ除去する
+		if mdFencePattern.MatchString(line) {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			result = append(result, line)
+			continue
+		}
+
+		// GFMテーブル（ヘッダー行の次が区切り行）
+		if i+1 < len(lines) && isMarkdownTableRow(line) && mdTableDelimPattern.MatchString(lines[i+1]) {
+			result = append(result, renderMarkdownTableRow(line))
+			i += 2
+			for ; i < len(lines) && isMarkdownTableRow(lines[i]); i++ {
+				result = append(result, renderMarkdownTableRow(lines[i]))
+			}
+			i--
+			continue
+		}
+
+		if mdRulePattern.MatchString(line) {
+			continue
+		}
+
+		line = mdHeadingPattern.ReplaceAllString(line, "")
+		line = mdQuotePattern.ReplaceAllString(line, "")
+		line = mdListPattern.ReplaceAllString(line, "$1- ")
+		result = append(result, stripMarkdownInline(line))
+	}
+
+	return strings.Join(result, "\n")
+}
+
+// stripMarkdownInline は行内の強調・リンク・コード記法を除去する
+func stripMarkdownInline(line string) string {
+	line = mdImagePattern.ReplaceAllString(line, "$1")
+	line = mdLinkPattern.ReplaceAllString(line, "$1")
+	line = mdCodePattern.ReplaceAllString(line, "$1")
+	line = mdBoldPattern.ReplaceAllString(line, "$2")
+	line = mdItalicPattern.ReplaceAllString(line, "$1$2")
+	line = mdStrikePattern.ReplaceAllString(line, "$1")
+	return line
+}
+
+// isMarkdownTableRow は行がテーブルの行（パイプを含む空でない行）かどうかを判定する
+func isMarkdownTableRow(line string) bool {
+	return strings.TrimSpace(line) != "" && strings.Contains(line, "|")
+}
+
+// renderMarkdownTableRow はテーブルの行を " | " 区切りのセルに変換する
+func renderMarkdownTableRow(line string) string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	line = strings.TrimSuffix(line, "|")
+
+	var cells []string
+	for _, cell := range splitMarkdownTableCells(line) {
+		cells = append(cells, stripMarkdownInline(strings.TrimSpace(cell)))
+	}
+	return strings.Join(cells, " | ")
+}
+
+// splitMarkdownTableCells はエスケープされたパイプ（\|）を考慮してセルに分割する
+func splitMarkdownTableCells(line string) []string {
+	var cells []string
+	var cell strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case line[i] == '|':
+			cells = append(cells, cell.String())
+			cell.Reset()
+		default:
+			cell.WriteByte(line[i])
+		}
+	}
+	return append(cells, cell.String())
+}
@@ -0,0 +1,25 @@
+package documentParser
+
+import (
+	"io"
+)
+
+// Metadata はドキュメントのメタデータ
+// 形式によって取得できない項目は空のままになる
+type Metadata struct {
+	Title       string   `json:"title,omitempty"`
+	Author      string   `json:"author,omitempty"`
+	Subject     string   `json:"subject,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Keywords    []string `json:"keywords,omitempty"`
+	Creator     string   `json:"creator,omitempty"`
+	Producer    string   `json:"producer,omitempty"`
+	Created     string   `json:"created,omitempty"`
+	Modified    string   `json:"modified,omitempty"`
+}
+
+// MetadataParser はメタデータを抽出できるパーサーのインターフェース
+type MetadataParser interface {
+	// ParseMetadata はio.ReaderAtからドキュメントのメタデータを抽出する
+	ParseMetadata(reader io.ReaderAt, size int64) (*Metadata, error)
+}
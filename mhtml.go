@@ -0,0 +1,139 @@
+package documentParser
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+
+	"golang.org/x/net/html/charset"
+)
+
+// MHTMLParser はWebアーカイブ（.mht / .mhtml）のパーサー
+// MIMEのマルチパートのメッセージからルートのHTMLのパーツを探し、転送エンコーディングと文字コードを戻してからHTMLParserと同じ形式のテキストにする
+// ルートはmultipart/relatedのstartパラメーターが指すパーツ、指定がない場合は最初のtext/htmlのパーツとする
+type MHTMLParser struct {
+	BaseParser
+}
+
+// errNoHTMLPart はメッセージにtext/htmlのパーツがない場合のエラー
+var errNoHTMLPart = errors.New("no text/html part found")
+
+// SupportedExtensions はサポートする拡張子を返す
+func (p *MHTMLParser) SupportedExtensions() []string {
+	return []string{".mht", ".mhtml"}
+}
+
+// Capabilities はパーサーが抽出できる構造の種類を返す
+func (p *MHTMLParser) Capabilities() ParserCapabilities {
+	return ParserCapabilities{Tables: true}
+}
+
+// ParseFromFile はファイルパスからMHTMLをパース
+func (p *MHTMLParser) ParseFromFile(filePath string) (string, error) {
+	return parseFromFileCommon(p, filePath)
+}
+
+// ParseFromBytes はバイト配列からMHTMLをパース
+func (p *MHTMLParser) ParseFromBytes(data []byte) (string, error) {
+	return parseFromBytesCommon(p, data)
+}
+
+// ParseFromReader はio.ReaderAtからMHTMLをパース
+func (p *MHTMLParser) ParseFromReader(reader io.ReaderAt, size int64) (string, error) {
+	msg, err := mail.ReadMessage(io.NewSectionReader(reader, 0, size))
+	if err != nil {
+		return "", fmt.Errorf("error reading MHTML: %w", err)
+	}
+
+	data, err := mhtmlRootHTML(textproto.MIMEHeader(msg.Header), msg.Body)
+	if err != nil {
+		return "", fmt.Errorf("error reading MHTML: %w", err)
+	}
+
+	htmlParser := &HTMLParser{BaseParser: p.BaseParser}
+	return htmlParser.ParseFromBytes(data)
+}
+
+// mhtmlRootHTML はパーツ（またはメッセージ全体）からルートのHTMLを探し、UTF-8に変換して返す
+// マルチパートの場合は子のパーツを再帰的にたどる
+func mhtmlRootHTML(header textproto.MIMEHeader, body io.Reader) ([]byte, error) {
+	contentType := header.Get("Content-Type")
+	if contentType == "" {
+		// Content-Typeのないパーツはtext/plainとして扱う
+		return nil, errNoHTMLPart
+	}
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Content-Type %q: %w", contentType, err)
+	}
+
+	switch {
+	case mediaType == "text/html":
+		return decodeMHTMLPart(header, body)
+	case !strings.HasPrefix(mediaType, "multipart/"):
+		return nil, errNoHTMLPart
+	}
+
+	start := strings.Trim(params["start"], "<>")
+	var first []byte
+	mr := multipart.NewReader(body, params["boundary"])
+	for {
+		part, err := mr.NextRawPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading MIME part: %w", err)
+		}
+
+		data, err := mhtmlRootHTML(part.Header, part)
+		if errors.Is(err, errNoHTMLPart) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if start == "" || strings.Trim(part.Header.Get("Content-ID"), "<>") == start {
+			return data, nil
+		}
+		if first == nil {
+			first = data
+		}
+	}
+	if first == nil {
+		return nil, errNoHTMLPart
+	}
+	return first, nil
+}
+
+// decodeMHTMLPart はContent-Transfer-Encoding（base64 / quoted-printable）を展開し、
+// Content-Typeのcharset（ない場合はmetaタグなどから判定した文字コード）からUTF-8に変換する
+func decodeMHTMLPart(header textproto.MIMEHeader, body io.Reader) ([]byte, error) {
+	switch strings.ToLower(strings.TrimSpace(header.Get("Content-Transfer-Encoding"))) {
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding HTML part: %w", err)
+	}
+
+	enc, name, _ := charset.DetermineEncoding(data, header.Get("Content-Type"))
+	if name == "utf-8" {
+		return data, nil
+	}
+	converted, err := enc.NewDecoder().Bytes(data)
+	if err != nil {
+		return nil, fmt.Errorf("error converting HTML from %s: %w", name, err)
+	}
+	return converted, nil
+}
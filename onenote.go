@@ -0,0 +1,508 @@
+package documentParser
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// OneNoteParser はOneNoteのセクションファイル（.one）のパーサー
+// MS-ONESTOREのリビジョンストアをたどり、ページ（オブジェクト空間）ごとにリッチテキストの内容を抽出する
+// 段落の順番はオブジェクトの宣言順で近似するため、OneNote上の表示順と異なる場合がある
+// 画像・手書き・埋め込みファイルは対象外
+type OneNoteParser struct {
+	BaseParser
+}
+
+// ErrOneNoteTableOfContents はノートブックの目次ファイル（.onetoc2）の場合に返すエラー
+// 目次ファイルはセクションの一覧のみを持ち、ページの内容を含まない
+var ErrOneNoteTableOfContents = errors.New("onetoc2 is a notebook table of contents and contains no page content")
+
+// OneNoteのファイル種別を示すGUID（ヘッダーのguidFileType、リトルエンディアン）
+var (
+	// oneSectionFileType は {7B5C52E4-D88C-4DA7-AEB1-5378D02996D3}
+	oneSectionFileType = []byte{0xE4, 0x52, 0x5C, 0x7B, 0x8C, 0xD8, 0xA7, 0x4D, 0xAE, 0xB1, 0x53, 0x78, 0xD0, 0x29, 0x96, 0xD3}
+	// oneTOCFileType は {43FF2FA1-EFD9-4C76-9EE2-10EA5722765F}
+	oneTOCFileType = []byte{0xA1, 0x2F, 0xFF, 0x43, 0xD9, 0xEF, 0x76, 0x4C, 0x9E, 0xE2, 0x10, 0xEA, 0x57, 0x22, 0x76, 0x5F}
+)
+
+const (
+	// oneHeaderSize はファイル先頭のヘッダーのサイズ
+	oneHeaderSize = 1024
+	// oneRootListOffset はヘッダー内のfcrFileNodeListRootの位置
+	oneRootListOffset = 172
+
+	oneFragmentHeaderMagic = 0xA4567AB1F5F7F4C4
+	// oneFragmentFooterSize はフラグメント末尾のnextFragmentとフッターのサイズ
+	oneFragmentFooterSize = 20
+	// oneMaxFragments は1つのファイルノードリストでたどるフラグメントの上限（循環参照の対策）
+	oneMaxFragments = 10000
+)
+
+// ファイルノードのID
+const (
+	oneChunkTerminator            = 0x0FF
+	oneObjectSpaceManifestListRef = 0x008
+	oneRevisionManifestListRef    = 0x010
+	oneGlobalIDTableStart         = 0x021
+	oneGlobalIDTableStart2        = 0x022
+	oneGlobalIDTableEntry         = 0x024
+	oneObjectDeclaration          = 0x02D
+	oneObjectDeclarationLarge     = 0x02E
+	oneObjectDeclaration2         = 0x0A4
+	oneObjectDeclaration2Large    = 0x0A5
+	oneObjectGroupListRef         = 0x0B0
+	oneReadOnlyDeclaration2       = 0x0C4
+	oneReadOnlyDeclaration2Large  = 0x0C5
+)
+
+// テキストを持つプロパティのID
+const (
+	onePropRichEditTextUnicode = 0x1C001C22
+	onePropTextExtendedASCII   = 0x1C003498
+)
+
+// SupportedExtensions はサポートする拡張子を返す
+func (p *OneNoteParser) SupportedExtensions() []string {
+	return []string{".one", ".onetoc2"}
+}
+
+// Capabilities はパーサーが抽出できる構造の種類を返す
+func (p *OneNoteParser) Capabilities() ParserCapabilities {
+	return ParserCapabilities{Pages: true}
+}
+
+// ParseFromFile はファイルパスからOneNoteのセクションをパース
+func (p *OneNoteParser) ParseFromFile(filePath string) (string, error) {
+	return parseFromFileCommon(p, filePath)
+}
+
+// ParseFromBytes はバイト配列からOneNoteのセクションをパース
+func (p *OneNoteParser) ParseFromBytes(data []byte) (string, error) {
+	return parseFromBytesCommon(p, data)
+}
+
+// ParseFromReader はio.ReaderAtからOneNoteのセクションをパースし、"## Page N" の見出し付きで返す
+func (p *OneNoteParser) ParseFromReader(reader io.ReaderAt, size int64) (string, error) {
+	pages, err := p.extractPages(reader, size)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	for i, page := range pages {
+		p.writePageSeparator(&sb, i)
+		sb.WriteString(p.boundaryMarker(heading("## ", p.labels().PageHeader, strconv.Itoa(page.Number), "\n"), "PAGE", strconv.Itoa(page.Number)))
+		sb.WriteString(page.Content)
+		sb.WriteString("\n\n")
+		if p.outputLimitReached(sb.Len()) {
+			break
+		}
+	}
+	return truncateOutput(sb.String(), p.MaxOutputBytes), nil
+}
+
+// parsePages はページごとのテキストを返す
+func (p *OneNoteParser) parsePages(reader io.ReaderAt, size int64) ([]Page, error) {
+	return p.extractPages(reader, size)
+}
+
+// extractPages はテキストを持つオブジェクト空間をページとして返す
+func (p *OneNoteParser) extractPages(reader io.ReaderAt, size int64) ([]Page, error) {
+	store := &oneStore{reader: reader, size: size}
+
+	header, err := store.read(0, oneHeaderSize)
+	if err != nil {
+		return nil, fmt.Errorf("error reading OneNote header: %w", err)
+	}
+	switch {
+	case bytes.Equal(header[:16], oneTOCFileType):
+		return nil, ErrOneNoteTableOfContents
+	case !bytes.Equal(header[:16], oneSectionFileType):
+		return nil, fmt.Errorf("not a OneNote section file")
+	}
+
+	rootStp := binary.LittleEndian.Uint64(header[oneRootListOffset:])
+	rootCb := uint64(binary.LittleEndian.Uint32(header[oneRootListOffset+8:]))
+	rootNodes, err := store.readFileNodeList(rootStp, rootCb)
+	if err != nil {
+		return nil, fmt.Errorf("error reading OneNote root file node list: %w", err)
+	}
+
+	var pages []Page
+	for _, node := range rootNodes {
+		if node.id != oneObjectSpaceManifestListRef {
+			continue
+		}
+		stp, cb, _, ok := node.ref()
+		if !ok {
+			continue
+		}
+		texts, err := store.objectSpaceTexts(stp, cb)
+		if err != nil {
+			return nil, fmt.Errorf("error reading OneNote object space: %w", err)
+		}
+		if len(texts) == 0 {
+			continue
+		}
+
+		number := len(pages) + 1
+		pages = append(pages, Page{
+			Number:  number,
+			Name:    fmt.Sprintf("Page %d", number),
+			Content: strings.Join(texts, "\n"),
+		})
+	}
+
+	if len(pages) == 0 {
+		return nil, fmt.Errorf("no text found in OneNote section")
+	}
+	return pages, nil
+}
+
+// oneStore はOneNoteのリビジョンストアのファイル
+type oneStore struct {
+	reader io.ReaderAt
+	size   int64
+}
+
+// read はファイルのstpからcbバイトを読み込む
+func (s *oneStore) read(stp, cb uint64) ([]byte, error) {
+	if stp > uint64(s.size) || cb > uint64(s.size)-stp {
+		return nil, fmt.Errorf("reference out of range: offset %d, size %d", stp, cb)
+	}
+	data := make([]byte, cb)
+	if _, err := s.reader.ReadAt(data, int64(stp)); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// oneFileNode はファイルノードリスト内の1つのノード
+type oneFileNode struct {
+	id        uint32
+	stpFormat uint32
+	cbFormat  uint32
+	baseType  uint32
+	// data はノードのヘッダーを除いた内容
+	data []byte
+}
+
+// ref はノードの先頭にある参照（FileNodeChunkReference）と、それ以降の内容を返す
+func (n oneFileNode) ref() (stp, cb uint64, rest []byte, ok bool) {
+	if n.baseType != 1 && n.baseType != 2 {
+		return 0, 0, nil, false
+	}
+	data := n.data
+
+	stpSizes := [4]int{8, 4, 2, 4}
+	cbSizes := [4]int{4, 8, 1, 2}
+	stpSize, cbSize := stpSizes[n.stpFormat], cbSizes[n.cbFormat]
+	if len(data) < stpSize+cbSize {
+		return 0, 0, nil, false
+	}
+
+	stp = readUintLE(data[:stpSize])
+	if n.stpFormat >= 2 {
+		// 圧縮形式は8倍した値
+		stp *= 8
+	}
+	cb = readUintLE(data[stpSize : stpSize+cbSize])
+	if n.cbFormat >= 2 {
+		cb *= 8
+	}
+	return stp, cb, data[stpSize+cbSize:], true
+}
+
+// readUintLE はリトルエンディアンの符号なし整数を読み込む
+func readUintLE(b []byte) uint64 {
+	var v uint64
+	for i := len(b) - 1; i >= 0; i-- {
+		v = v<<8 | uint64(b[i])
+	}
+	return v
+}
+
+// readFileNodeList はフラグメントをたどってファイルノードリストの全てのノードを返す
+func (s *oneStore) readFileNodeList(stp, cb uint64) ([]oneFileNode, error) {
+	var nodes []oneFileNode
+	visited := make(map[uint64]bool)
+
+	for fragments := 0; fragments < oneMaxFragments; fragments++ {
+		if visited[stp] {
+			break
+		}
+		visited[stp] = true
+
+		data, err := s.read(stp, cb)
+		if err != nil {
+			return nil, err
+		}
+		if len(data) < 16+oneFragmentFooterSize || binary.LittleEndian.Uint64(data) != oneFragmentHeaderMagic {
+			return nil, fmt.Errorf("invalid file node list fragment at offset %d", stp)
+		}
+
+		end := len(data) - oneFragmentFooterSize
+		for pos := 16; pos+4 <= end; {
+			header := binary.LittleEndian.Uint32(data[pos:])
+			id := header & 0x3FF
+			size := int(header >> 10 & 0x1FFF)
+			if id == oneChunkTerminator || id == 0 || size < 4 || pos+size > end {
+				break
+			}
+			nodes = append(nodes, oneFileNode{
+				id:        id,
+				stpFormat: header >> 23 & 0x3,
+				cbFormat:  header >> 25 & 0x3,
+				baseType:  header >> 27 & 0xF,
+				data:      data[pos+4 : pos+size],
+			})
+			pos += size
+		}
+
+		nextStp := binary.LittleEndian.Uint64(data[end:])
+		nextCb := uint64(binary.LittleEndian.Uint32(data[end+8:]))
+		if nextStp == ^uint64(0) || nextCb == 0 {
+			break
+		}
+		stp, cb = nextStp, nextCb
+	}
+
+	return nodes, nil
+}
+
+// objectSpaceTexts はオブジェクト空間の全てのリビジョンからテキストを持つオブジェクトを集める
+// 同じオブジェクトが後のリビジョンで再宣言された場合は新しい内容で置き換え、順番は最初に宣言された位置を使う
+func (s *oneStore) objectSpaceTexts(stp, cb uint64) ([]string, error) {
+	spaceNodes, err := s.readFileNodeList(stp, cb)
+	if err != nil {
+		return nil, err
+	}
+
+	objects := make(map[string][]string)
+	var order []string
+	collect := func(nodes []oneFileNode) error {
+		guids := make(map[uint32][]byte)
+		for _, node := range nodes {
+			switch node.id {
+			case oneGlobalIDTableStart, oneGlobalIDTableStart2:
+				guids = make(map[uint32][]byte)
+			case oneGlobalIDTableEntry:
+				if len(node.data) >= 20 {
+					guids[binary.LittleEndian.Uint32(node.data)] = node.data[4:20]
+				}
+			case oneObjectDeclaration, oneObjectDeclarationLarge,
+				oneObjectDeclaration2, oneObjectDeclaration2Large, oneReadOnlyDeclaration2, oneReadOnlyDeclaration2Large:
+				propStp, propCb, body, ok := node.ref()
+				if !ok || len(body) < 4 {
+					continue
+				}
+				// オブジェクトはCompactID（GUIDのインデックスと番号）で識別する
+				oid := binary.LittleEndian.Uint32(body)
+				key := fmt.Sprintf("%x:%d", guids[oid>>8], oid&0xFF)
+
+				data, err := s.read(propStp, propCb)
+				if err != nil {
+					return err
+				}
+				texts, err := onePropertySetTexts(data)
+				if err != nil {
+					return err
+				}
+				if len(texts) == 0 {
+					continue
+				}
+				if _, seen := objects[key]; !seen {
+					order = append(order, key)
+				}
+				objects[key] = texts
+			}
+		}
+		return nil
+	}
+
+	for _, node := range spaceNodes {
+		if node.id != oneRevisionManifestListRef {
+			continue
+		}
+		revStp, revCb, _, ok := node.ref()
+		if !ok {
+			continue
+		}
+		revisionNodes, err := s.readFileNodeList(revStp, revCb)
+		if err != nil {
+			return nil, err
+		}
+
+		// 古い形式ではリビジョンにオブジェクトが直接宣言されている
+		if err := collect(revisionNodes); err != nil {
+			return nil, err
+		}
+		for _, revisionNode := range revisionNodes {
+			if revisionNode.id != oneObjectGroupListRef {
+				continue
+			}
+			groupStp, groupCb, _, ok := revisionNode.ref()
+			if !ok {
+				continue
+			}
+			groupNodes, err := s.readFileNodeList(groupStp, groupCb)
+			if err != nil {
+				return nil, err
+			}
+			if err := collect(groupNodes); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var texts []string
+	for _, key := range order {
+		texts = append(texts, objects[key]...)
+	}
+	return texts, nil
+}
+
+// onePropertySetTexts はObjectSpaceObjectPropSetからテキストのプロパティを取り出す
+func onePropertySetTexts(data []byte) ([]string, error) {
+	// 先頭のOIDs・OSIDs・ContextIDsのストリームを読み飛ばす
+	pos := 0
+	skipStream := func() (uint32, error) {
+		if pos+4 > len(data) {
+			return 0, fmt.Errorf("truncated object stream")
+		}
+		header := binary.LittleEndian.Uint32(data[pos:])
+		pos += 4 + int(header&0xFFFFFF)*4
+		if pos > len(data) {
+			return 0, fmt.Errorf("truncated object stream")
+		}
+		return header, nil
+	}
+
+	oids, err := skipStream()
+	if err != nil {
+		return nil, err
+	}
+	if oids>>31 == 0 {
+		osids, err := skipStream()
+		if err != nil {
+			return nil, err
+		}
+		if osids>>30&1 == 1 {
+			if _, err := skipStream(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var texts []string
+	if _, err := readOnePropertySet(data, pos, &texts, 0); err != nil {
+		return nil, err
+	}
+	return texts, nil
+}
+
+// readOnePropertySet はPropertySetを読み、テキストのプロパティをtextsに追加して次の位置を返す
+func readOnePropertySet(data []byte, pos int, texts *[]string, depth int) (int, error) {
+	errTruncated := fmt.Errorf("truncated property set")
+	if depth > 32 {
+		return 0, fmt.Errorf("property sets nested too deeply")
+	}
+	if pos+2 > len(data) {
+		return 0, errTruncated
+	}
+	count := int(binary.LittleEndian.Uint16(data[pos:]))
+	pos += 2
+	if pos+count*4 > len(data) {
+		return 0, errTruncated
+	}
+	prids := make([]uint32, count)
+	for i := range prids {
+		prids[i] = binary.LittleEndian.Uint32(data[pos+i*4:])
+	}
+	pos += count * 4
+
+	for _, prid := range prids {
+		var err error
+		switch prid >> 26 & 0x1F {
+		case 0x1, 0x2, 0x8, 0xA, 0xC:
+			// データを持たない（真偽値はPropertyIDに含まれ、IDは別のストリームにある）
+		case 0x3:
+			pos++
+		case 0x4:
+			pos += 2
+		case 0x5, 0x9, 0xB, 0xD:
+			pos += 4
+		case 0x6:
+			pos += 8
+		case 0x7:
+			if pos+4 > len(data) {
+				return 0, errTruncated
+			}
+			size := int(binary.LittleEndian.Uint32(data[pos:]))
+			pos += 4
+			if size > len(data)-pos {
+				return 0, errTruncated
+			}
+			if text := onePropertyText(prid, data[pos:pos+size]); text != "" {
+				*texts = append(*texts, text)
+			}
+			pos += size
+		case 0x10:
+			if pos+4 > len(data) {
+				return 0, errTruncated
+			}
+			n := int(binary.LittleEndian.Uint32(data[pos:]))
+			pos += 4
+			if n > 0 {
+				// 要素のPropertyIDに続いてPropertySetがn個並ぶ
+				pos += 4
+				for i := 0; i < n && err == nil; i++ {
+					pos, err = readOnePropertySet(data, pos, texts, depth+1)
+				}
+			}
+		case 0x11:
+			pos, err = readOnePropertySet(data, pos, texts, depth+1)
+		default:
+			return 0, fmt.Errorf("unknown property type 0x%x", prid>>26&0x1F)
+		}
+		if err != nil {
+			return 0, err
+		}
+		if pos > len(data) {
+			return 0, errTruncated
+		}
+	}
+	return pos, nil
+}
+
+// onePropertyText はテキストのプロパティの値を文字列に変換する（テキスト以外のプロパティは空文字列）
+func onePropertyText(prid uint32, value []byte) string {
+	var text string
+	switch prid {
+	case onePropRichEditTextUnicode:
+		units := make([]uint16, len(value)/2)
+		for i := range units {
+			units[i] = binary.LittleEndian.Uint16(value[i*2:])
+		}
+		text = string(utf16.Decode(units))
+	case onePropTextExtendedASCII:
+		runes := make([]rune, len(value))
+		for i, b := range value {
+			runes[i] = rune(b)
+		}
+		text = string(runes)
+	default:
+		return ""
+	}
+
+	// 段落内の改行（垂直タブ）と段落区切りを改行に揃える
+	text = strings.TrimRight(text, "\x00")
+	text = strings.NewReplacer("\r\n", "\n", "\r", "\n", "\v", "\n").Replace(text)
+	return strings.TrimSpace(text)
+}
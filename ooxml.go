@@ -0,0 +1,215 @@
+package documentParser
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"strings"
+)
+
+// Relationship はOOXMLの.relsファイル内の1つのリレーションシップ
+type Relationship struct {
+	ID         string `xml:"Id,attr"`
+	Type       string `xml:"Type,attr"`
+	Target     string `xml:"Target,attr"`
+	TargetMode string `xml:"TargetMode,attr"`
+}
+
+type relationships struct {
+	Relationships []Relationship `xml:"Relationship"`
+}
+
+// zipFileMap はzip内のファイルを名前で引けるマップに変換する
+func zipFileMap(r *zip.Reader) map[string]*zip.File {
+	files := make(map[string]*zip.File, len(r.File))
+	for _, f := range r.File {
+		files[f.Name] = f
+	}
+	return files
+}
+
+// maxZipPartSize はパッケージ内の1つのパーツを展開する際の上限（128MB）
+// 圧縮率の極端に高いパーツ（zip爆弾）でメモリを使い果たさないようにする
+const maxZipPartSize = 128 * 1024 * 1024
+
+// openZipPart はzip内のファイルを開く
+// 展開後のサイズがmaxZipPartSizeを超えるパーツはエラーにする
+// （archive/zipは宣言されたサイズを超えて読み込まないため、ヘッダーのサイズを確認すれば十分）
+func openZipPart(f *zip.File) (io.ReadCloser, error) {
+	if f.UncompressedSize64 > maxZipPartSize {
+		return nil, fmt.Errorf("file %s exceeds maximum uncompressed size of %d bytes", f.Name, maxZipPartSize)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("error opening file %s: %w", f.Name, err)
+	}
+	return rc, nil
+}
+
+// readZipFile はzip内のファイルを全て読み込む
+func readZipFile(f *zip.File) ([]byte, error) {
+	rc, err := openZipPart(f)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file %s: %w", f.Name, err)
+	}
+	return data, nil
+}
+
+// contentTypes は[Content_Types].xmlの定義
+type contentTypes struct {
+	Defaults []struct {
+		Extension   string `xml:"Extension,attr"`
+		ContentType string `xml:"ContentType,attr"`
+	} `xml:"Default"`
+	Overrides []struct {
+		PartName    string `xml:"PartName,attr"`
+		ContentType string `xml:"ContentType,attr"`
+	} `xml:"Override"`
+}
+
+// readContentTypes は[Content_Types].xmlを読み込む
+// ファイルが存在しない場合は空の定義を返す
+func readContentTypes(files map[string]*zip.File) (*contentTypes, error) {
+	types := &contentTypes{}
+	f, ok := files["[Content_Types].xml"]
+	if !ok {
+		return types, nil
+	}
+
+	data, err := readZipFile(f)
+	if err != nil {
+		return nil, err
+	}
+	if err := xml.Unmarshal(data, types); err != nil {
+		return nil, fmt.Errorf("error parsing content types: %w", err)
+	}
+	return types, nil
+}
+
+// lookup はパーツのコンテンツタイプを返す（Overrideを優先し、なければ拡張子のDefault）
+func (t *contentTypes) lookup(partName string) string {
+	for _, override := range t.Overrides {
+		if strings.TrimPrefix(override.PartName, "/") == partName {
+			return override.ContentType
+		}
+	}
+	ext := strings.TrimPrefix(path.Ext(partName), ".")
+	for _, def := range t.Defaults {
+		if strings.EqualFold(def.Extension, ext) {
+			return def.ContentType
+		}
+	}
+	return ""
+}
+
+// relsPath はパーツに対応する.relsファイルのパスを返す
+// 例: ppt/slides/slide1.xml -> ppt/slides/_rels/slide1.xml.rels
+// partNameが空の場合はパッケージ自体のリレーションシップ（_rels/.rels）を返す
+func relsPath(partName string) string {
+	if partName == "" {
+		return "_rels/.rels"
+	}
+	return path.Join(path.Dir(partName), "_rels", path.Base(partName)+".rels")
+}
+
+// readRelationships はパーツのリレーションシップを読み込む
+// .relsファイルが存在しない場合は空のスライスを返す
+func readRelationships(files map[string]*zip.File, partName string) ([]Relationship, error) {
+	f, ok := files[relsPath(partName)]
+	if !ok {
+		return nil, nil
+	}
+
+	data, err := readZipFile(f)
+	if err != nil {
+		return nil, err
+	}
+
+	var rels relationships
+	if err := xml.Unmarshal(data, &rels); err != nil {
+		return nil, fmt.Errorf("error parsing relationships for %s: %w", partName, err)
+	}
+	return rels.Relationships, nil
+}
+
+// relatedParts は指定した種類のリレーションシップが指すパーツのパスを返す
+func relatedParts(files map[string]*zip.File, partName, relType string) []string {
+	rels, err := readRelationships(files, partName)
+	if err != nil {
+		log.Printf("Error reading relationships for %s: %s", partName, err)
+		return nil
+	}
+	var parts []string
+	for _, rel := range rels {
+		if strings.HasSuffix(rel.Type, relType) {
+			parts = append(parts, resolveTarget(partName, rel.Target))
+		}
+	}
+	return parts
+}
+
+// resolveTarget はリレーションシップのターゲットをzip内の絶対パスに解決する
+func resolveTarget(partName, target string) string {
+	if strings.HasPrefix(target, "/") {
+		return strings.TrimPrefix(target, "/")
+	}
+	return path.Join(path.Dir(partName), target)
+}
+
+// coreProperties はコアプロパティ（docProps/core.xml）のうち、PrependMetadataで出力する項目
+type coreProperties struct {
+	Title   string `xml:"title"`
+	Creator string `xml:"creator"`
+	Created string `xml:"created"`
+}
+
+// coreRelType はパッケージからコアプロパティへのリレーションシップの種類
+const coreRelType = "/metadata/core-properties"
+
+// ooxmlMetadataHeader はコアプロパティのタイトル・作成者・作成日時を "Title: 値" の行にしたブロック（末尾に空行）を返す
+// 空の項目は出力せず、全て空の場合やコアプロパティを読めない場合は空文字列を返す
+func ooxmlMetadataHeader(reader io.ReaderAt, size int64) string {
+	r, err := zip.NewReader(reader, size)
+	if err != nil {
+		return ""
+	}
+	files := zipFileMap(r)
+
+	partName := "docProps/core.xml"
+	if parts := relatedParts(files, "", coreRelType); len(parts) > 0 {
+		partName = parts[0]
+	}
+	if _, ok := files[partName]; !ok {
+		return ""
+	}
+	var props coreProperties
+	if err := readXMLPart(files, partName, &props); err != nil {
+		log.Printf("Error reading core properties: %s", err)
+		return ""
+	}
+
+	var sb strings.Builder
+	for _, field := range []struct{ label, value string }{
+		{"Title", props.Title},
+		{"Author", props.Creator},
+		{"Created", props.Created},
+	} {
+		if value := strings.Join(strings.Fields(field.value), " "); value != "" {
+			sb.WriteString(field.label + ": " + value + "\n")
+		}
+	}
+	if sb.Len() == 0 {
+		return ""
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
@@ -0,0 +1,179 @@
+package documentParser
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// Option はParseFromReaderWithOptionsで1回のパースにのみ適用するオプション
+// 拡張子に対応するパーサーが持たないオプションは無視される
+type Option func(*parseOptions)
+
+// parseOptions はOptionで指定された値（nilのものは指定されていない）
+type parseOptions struct {
+	pageRange      *[2]int
+	pageLabelRange *[2]string
+	password       *string
+	sanitize       *SanitizeOptions
+	encoding       *string
+	maxOutputBytes *int
+}
+
+// SanitizeOptions はテキストの整形に関するオプション
+type SanitizeOptions struct {
+	// PreserveWhitespace が true の場合、改行や空白をできるだけ保持する
+	// （PDFParserのPreserveWhitespace、DOCXParserのPreserveLineBreaksに対応）
+	PreserveWhitespace bool
+
+	// DehyphenateLineBreaks が true の場合、行末のハイフンで分割された単語を結合する（PDFのみ）
+	DehyphenateLineBreaks bool
+
+	// DedupeRepeatedLines が true の場合、ヘッダー・フッターなどの繰り返し行を除去する（PDFのみ）
+	DedupeRepeatedLines bool
+
+	// ConcatenateRuns が true の場合、テキスト片をスペースで区切らずにそのまま連結する（PDFのみ）
+	ConcatenateRuns bool
+}
+
+// WithPageRange は抽出するページの範囲（1始まり、両端を含む）を指定する（PDFのみ）
+// 0の場合はそれぞれ先頭・末尾のページまでを対象とする
+func WithPageRange(first, last int) Option {
+	return func(o *parseOptions) {
+		o.pageRange = &[2]int{first, last}
+	}
+}
+
+// WithPageLabelRange は抽出するページの範囲をページラベル（"iv"、"A-1" など）で指定する（PDFのみ）
+// 空文字列の場合はそれぞれ先頭・末尾のページまでを対象とする
+func WithPageLabelRange(first, last string) Option {
+	return func(o *parseOptions) {
+		o.pageLabelRange = &[2]string{first, last}
+	}
+}
+
+// WithPassword は暗号化されたドキュメントを開くためのパスワードを指定する（PDFのみ）
+func WithPassword(password string) Option {
+	return func(o *parseOptions) {
+		o.password = &password
+	}
+}
+
+// WithSanitizeOptions はテキストの整形に関するオプションを指定する（PDF・DOCX）
+func WithSanitizeOptions(sanitize SanitizeOptions) Option {
+	return func(o *parseOptions) {
+		o.sanitize = &sanitize
+	}
+}
+
+// WithEncoding は入力の文字コード（"shift_jis"、"cp932"、"euc-jp" など）を指定する（CSV・テキストのみ）
+func WithEncoding(encoding string) Option {
+	return func(o *parseOptions) {
+		o.encoding = &encoding
+	}
+}
+
+// WithMaxOutputBytes はパース結果の最大バイト数を指定する（0以下は無制限）
+func WithMaxOutputBytes(n int) Option {
+	return func(o *parseOptions) {
+		o.maxOutputBytes = &n
+	}
+}
+
+// pageRanger はページの範囲を指定できるパーサー
+type pageRanger interface {
+	setPageRange(first, last int)
+}
+
+// pageLabelRanger はページラベルでページの範囲を指定できるパーサー
+type pageLabelRanger interface {
+	setPageLabelRange(first, last string)
+}
+
+// passwordSetter はパスワードを指定できるパーサー
+type passwordSetter interface {
+	setPassword(password string)
+}
+
+// sanitizeConfigurer はテキストの整形に関するオプションを持つパーサー
+type sanitizeConfigurer interface {
+	setSanitizeOptions(sanitize SanitizeOptions)
+}
+
+// encodingSetter は入力の文字コードを指定できるパーサー
+type encodingSetter interface {
+	setEncoding(encoding string)
+}
+
+// ParseFromReaderWithOptions はio.ReaderAtからドキュメントをパースする
+// オプションはファクトリーに登録されたパーサーの複製に適用されるため、他の呼び出しには影響しない
+func (f *DocumentParserFactory) ParseFromReaderWithOptions(ext string, reader io.ReaderAt, size int64, opts ...Option) (string, error) {
+	defer f.acquire()()
+
+	parser, err := f.GetParser(ext)
+	if err != nil {
+		return "", fmt.Errorf("failed to get parser: %w", err)
+	}
+
+	var options parseOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	parser = options.apply(parser)
+
+	content, err := parser.ParseFromReader(reader, size)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse from reader: %w", err)
+	}
+
+	return limitOutput(parser, content), nil
+}
+
+// apply はパーサーを複製し、対応しているオプションを設定して返す
+// オプションが指定されていない場合は元のパーサーをそのまま返す
+func (o parseOptions) apply(parser DocumentParser) DocumentParser {
+	if o == (parseOptions{}) {
+		return parser
+	}
+
+	parser = cloneParser(parser)
+	if p, ok := parser.(pageRanger); ok && o.pageRange != nil {
+		p.setPageRange(o.pageRange[0], o.pageRange[1])
+	}
+	if p, ok := parser.(pageLabelRanger); ok && o.pageLabelRange != nil {
+		p.setPageLabelRange(o.pageLabelRange[0], o.pageLabelRange[1])
+	}
+	if p, ok := parser.(passwordSetter); ok && o.password != nil {
+		p.setPassword(*o.password)
+	}
+	if o.sanitize != nil {
+		if p, ok := parser.(formattingPreserver); ok {
+			p.setPreserveFormatting(o.sanitize.PreserveWhitespace)
+		}
+		if p, ok := parser.(sanitizeConfigurer); ok {
+			p.setSanitizeOptions(*o.sanitize)
+		}
+	}
+	if p, ok := parser.(encodingSetter); ok && o.encoding != nil {
+		p.setEncoding(*o.encoding)
+	}
+	if b, ok := parser.(baseProvider); ok && o.maxOutputBytes != nil {
+		b.base().MaxOutputBytes = *o.maxOutputBytes
+	}
+	return parser
+}
+
+// cloneParser は構造体へのポインターであるパーサーの浅いコピーを返す
+// それ以外のパーサー（オプションを設定できない）はそのまま返す
+func cloneParser(parser DocumentParser) DocumentParser {
+	v := reflect.ValueOf(parser)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return parser
+	}
+	clone := reflect.New(v.Elem().Type())
+	clone.Elem().Set(v.Elem())
+	if p, ok := clone.Interface().(DocumentParser); ok {
+		return p
+	}
+	return parser
+}
@@ -0,0 +1,192 @@
+package documentParser
+
+import (
+	"archive/zip"
+	"compress/gzip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// ErrPagesIWA はPages '13以降のIWA形式（Index/Document.iwa）のドキュメントの場合に返すエラー
+// 旧形式（Pages '09以前）のXMLのドキュメントのみに対応している
+var ErrPagesIWA = errors.New("pages documents in the iWork 2013+ (IWA) format are not supported")
+
+// PagesParser はApple Pagesのドキュメントのパーサー
+// Pages '09以前の旧形式（パッケージ内のindex.xml.gz、またはindex.xml）から本文の段落を抽出する
+// ヘッダー・フッター・脚注・テキストボックス・表は対象外
+type PagesParser struct {
+	BaseParser
+}
+
+// Pagesの旧形式のパッケージ内のエントリ名
+const (
+	pagesIndexGzip = "index.xml.gz"
+	pagesIndexXML  = "index.xml"
+	pagesIWAIndex  = "Index/Document.iwa"
+)
+
+// SupportedExtensions はサポートする拡張子を返す
+func (p *PagesParser) SupportedExtensions() []string {
+	return []string{".pages"}
+}
+
+// ParseFromFile はファイルパスからPagesのドキュメントをパース
+func (p *PagesParser) ParseFromFile(filePath string) (string, error) {
+	return parseFromFileCommon(p, filePath)
+}
+
+// ParseFromBytes はバイト配列からPagesのドキュメントをパース
+func (p *PagesParser) ParseFromBytes(data []byte) (string, error) {
+	return parseFromBytesCommon(p, data)
+}
+
+// ParseFromReader はio.ReaderAtからPagesのドキュメントをパースし、本文の段落を1行ずつ返す
+func (p *PagesParser) ParseFromReader(reader io.ReaderAt, size int64) (string, error) {
+	r, err := zip.NewReader(reader, size)
+	if err != nil {
+		return "", fmt.Errorf("error reading Pages file: %w", err)
+	}
+
+	index, err := openPagesIndex(r)
+	if err != nil {
+		return "", err
+	}
+	defer index.Close()
+
+	paragraphs, err := pagesParagraphs(xml.NewDecoder(index), p.MaxOutputBytes)
+	if err != nil {
+		return "", fmt.Errorf("error parsing Pages document: %w", err)
+	}
+	if len(paragraphs) == 0 {
+		return "", ErrEmptyBody
+	}
+	return truncateOutput(strings.Join(paragraphs, "\n")+"\n", p.MaxOutputBytes), nil
+}
+
+// openPagesIndex はパッケージ内の旧形式のXML（index.xml.gz または index.xml）を開く
+// バンドルをそのまま圧縮したファイルでは "Document.pages/index.xml.gz" のようにフォルダが付くため、ファイル名で探す
+func openPagesIndex(r *zip.Reader) (io.ReadCloser, error) {
+	var gzipped, plain *zip.File
+	for _, f := range r.File {
+		switch {
+		case path.Base(f.Name) == pagesIndexGzip && gzipped == nil:
+			gzipped = f
+		case path.Base(f.Name) == pagesIndexXML && plain == nil:
+			plain = f
+		case strings.HasSuffix(f.Name, pagesIWAIndex):
+			return nil, ErrPagesIWA
+		}
+	}
+
+	switch {
+	case gzipped != nil:
+		rc, err := openZipPart(gzipped)
+		if err != nil {
+			return nil, err
+		}
+		zr, err := gzip.NewReader(rc)
+		if err != nil {
+			rc.Close()
+			return nil, fmt.Errorf("error decompressing %s: %w", gzipped.Name, err)
+		}
+		// 展開後のサイズもパーツの上限までに制限する
+		return &pagesIndexReader{Reader: io.LimitReader(zr, maxZipPartSize), closer: rc}, nil
+	case plain != nil:
+		return openZipPart(plain)
+	}
+	return nil, fmt.Errorf("index.xml not found in Pages package")
+}
+
+// pagesIndexReader は展開したindex.xml.gzを読み込み、Closeで元のzipエントリを閉じる
+type pagesIndexReader struct {
+	io.Reader
+	closer io.Closer
+}
+
+// Close は元のzipエントリを閉じる
+func (r *pagesIndexReader) Close() error {
+	return r.closer.Close()
+}
+
+// pagesParagraphs は本文（sf:kind="body" のsf:text-storage）のsf:text-body内の段落のテキストを返す
+// 変更履歴で削除されたテキスト（sf:delete）や、本文中に置かれたテキストボックス等の別のtext-storageは含めない
+func pagesParagraphs(decoder *xml.Decoder, maxBytes int) ([]string, error) {
+	var paragraphs []string
+	var sb strings.Builder
+	size := 0
+	// kinds はデコード中のsf:text-storageの種類（sf:kind）を外側から順に保持する
+	var kinds []string
+	inBody, inParagraph := false, false
+
+	for {
+		t, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch se := t.(type) {
+		case xml.StartElement:
+			if !inBody {
+				switch se.Name.Local {
+				case "text-storage":
+					kind := ""
+					for _, attr := range se.Attr {
+						if attr.Name.Local == "kind" {
+							kind = attr.Value
+						}
+					}
+					kinds = append(kinds, kind)
+				case "text-body":
+					inBody = len(kinds) > 0 && kinds[len(kinds)-1] == "body"
+				}
+				continue
+			}
+
+			switch se.Name.Local {
+			case "p":
+				inParagraph = true
+				sb.Reset()
+			case "tab":
+				sb.WriteString("\t")
+			case "br", "lnbr", "pgbr", "sectbr", "layoutbr", "contbr":
+				sb.WriteString("\n")
+			case "delete", "text-storage", "footnote-mark":
+				if err := decoder.Skip(); err != nil {
+					return nil, err
+				}
+			}
+		case xml.EndElement:
+			switch se.Name.Local {
+			case "text-storage":
+				if len(kinds) > 0 {
+					kinds = kinds[:len(kinds)-1]
+				}
+			case "text-body":
+				inBody = false
+			case "p":
+				if inParagraph {
+					inParagraph = false
+					if text := strings.TrimSpace(sb.String()); text != "" {
+						paragraphs = append(paragraphs, text)
+						size += len(text) + 1
+					}
+					if maxBytes > 0 && size > maxBytes {
+						return paragraphs, nil
+					}
+				}
+			}
+		case xml.CharData:
+			if inParagraph {
+				sb.Write(se)
+			}
+		}
+	}
+	return paragraphs, nil
+}
@@ -0,0 +1,60 @@
+package documentParser
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrPartNotFound はパッケージに指定した名前のパーツが存在しない場合に返すエラー
+var ErrPartNotFound = errors.New("part not found")
+
+// PartReader はOOXMLのパッケージ（zip）内のパーツの生のデータを読み込めるパーサーのインターフェース
+// ライブラリが対応していない情報（word/styles.xml のスタイル定義など）を独自に処理する場合に使う
+type PartReader interface {
+	// ReadPart はio.ReaderAtからpartName（"word/styles.xml" など）のパーツの内容を展開して返す
+	// パーツが存在しない場合はErrPartNotFoundを返す
+	ReadPart(reader io.ReaderAt, size int64, partName string) ([]byte, error)
+}
+
+// readOOXMLPart はOOXMLのパッケージ内のパーツの内容を返す
+// パーツ名の先頭の "/"（"/word/document.xml" のような[Content_Types].xmlでの表記）は除き、
+// OPCのパーツ名は大文字と小文字を区別しないため、完全に一致するものがなければ区別せずに探す
+func readOOXMLPart(reader io.ReaderAt, size int64, partName string) ([]byte, error) {
+	r, err := zip.NewReader(reader, size)
+	if err != nil {
+		return nil, fmt.Errorf("error reading OOXML package: %w", err)
+	}
+
+	name := strings.TrimPrefix(partName, "/")
+	f := zipFileMap(r)[name]
+	if f == nil {
+		for _, candidate := range r.File {
+			if strings.EqualFold(candidate.Name, name) {
+				f = candidate
+				break
+			}
+		}
+	}
+	if f == nil {
+		return nil, fmt.Errorf("%w: %s", ErrPartNotFound, partName)
+	}
+	return readZipFile(f)
+}
+
+// ReadPart はパッケージ内のパーツ（"word/styles.xml" など）の生のデータを返す
+func (p *DOCXParser) ReadPart(reader io.ReaderAt, size int64, partName string) ([]byte, error) {
+	return readOOXMLPart(reader, size, partName)
+}
+
+// ReadPart はパッケージ内のパーツ（"ppt/slides/slide1.xml" など）の生のデータを返す
+func (p *PPTXParser) ReadPart(reader io.ReaderAt, size int64, partName string) ([]byte, error) {
+	return readOOXMLPart(reader, size, partName)
+}
+
+// ReadPart はパッケージ内のパーツ（"xl/styles.xml" など）の生のデータを返す
+func (p *ExcelParser) ReadPart(reader io.ReaderAt, size int64, partName string) ([]byte, error) {
+	return readOOXMLPart(reader, size, partName)
+}
@@ -1,10 +1,19 @@
 package documentParser
 
 import (
+	"bytes"
+	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"unicode"
 
 	"github.com/ledongthuc/pdf"
 )
@@ -12,6 +21,60 @@ import (
 // PDFParser はPDFファイルのパーサー
 type PDFParser struct {
 	BaseParser
+
+	// DehyphenateLineBreaks が true の場合、ページを行単位で組み立て、
+	// 行末のハイフンで分割された単語（inter-\nnational）を結合する
+	DehyphenateLineBreaks bool
+
+	// Concurrency はページを並列に抽出するワーカー数（0または1の場合は逐次処理）
+	// MaxOutputBytes が設定されている場合は上限に達した時点で打ち切るため逐次処理になる
+	Concurrency int
+
+	// DedupeRepeatedLines が true の場合、過半数のページで同じ位置に現れる行（ランニングヘッダー、
+	// フッター、ページ番号）を本文から除去する
+	DedupeRepeatedLines bool
+
+	// UsePageLabels が true の場合、"## Page N" の見出しに物理的なページ番号ではなく
+	// PDFに定義されたページラベル（"iv"、"A-1" など）を使う
+	UsePageLabels bool
+
+	// PreserveWhitespace が true の場合、ページを行単位で組み立てて改行を残し、
+	// 全角英数字の変換・タブの置換・連続スペースの圧縮を行わない
+	PreserveWhitespace bool
+
+	// UseStructureTree が true の場合、タグ付きPDFの論理構造（StructTreeRoot）があれば、その順番で本文を組み立て、
+	// 図（Figure）は代替テキストを "[Figure: 代替テキスト]" として出力する。アーティファクト（ヘッダー・フッターなど）は除外される
+	// 構造を持たないPDFや、構造からテキストを取り出せないページは通常の抽出にフォールバックする
+	UseStructureTree bool
+
+	// FirstPage と LastPage は抽出するページの範囲（1始まり、両端を含む）
+	// 0の場合はそれぞれ先頭・末尾のページまでを対象とする
+	FirstPage int
+	LastPage  int
+
+	// FirstPageLabel と LastPageLabel はページラベル（"iv"、"A-1" など）で指定する抽出するページの範囲（両端を含む）
+	// 空でない場合はFirstPage・LastPageより優先する。ラベルが見つからない場合はErrPageLabelNotFoundを返す
+	// 同じラベルのページが複数ある場合、FirstPageLabelは最初のページ、LastPageLabelは範囲の開始以降の最初のページとする
+	FirstPageLabel string
+	LastPageLabel  string
+
+	// KeepInvisibleChars が true の場合、ソフトハイフン（U+00AD）・ゼロ幅スペース（U+200B）・
+	// BOM（U+FEFF）などの不可視文字を除去せずに残す（デフォルトは検索の妨げになるため除去する）
+	KeepInvisibleChars bool
+
+	// Password は暗号化されたPDFを開くためのパスワード（空の場合は暗号化されていないものとして開く）
+	Password string
+
+	// ConcatenateRuns が true の場合、テキスト片を前後の空白を除かずにそのまま連結する（改行される位置にはスペースを入れる）
+	// デフォルトはテキスト片ごとに前後の空白を除いてスペースで区切るが、単語の途中で分割されたテキスト片を持つPDFでは
+	// "Hel lo" のように余分なスペースが入る。単語間の空白を独立したテキスト片として出力するPDFではこちらが正しく区切られる
+	// DehyphenateLineBreaks・PreserveWhitespace で行単位に組み立てる場合は使われない
+	ConcatenateRuns bool
+
+	// AttemptRecovery が true の場合、相互参照表（xref）が壊れていて開けないPDFは、ファイル全体から
+	// 間接オブジェクトの開始（"12 0 obj"）を探して相互参照表を作り直してから開き直す（ファイル全体を読み込むため遅い）
+	// 作り直しても開けない場合は元のエラーを返す。オブジェクトストリーム内に圧縮されたオブジェクトは復元できない
+	AttemptRecovery bool
 }
 
 // SupportedExtensions はサポートする拡張子を返す
@@ -19,6 +82,39 @@ func (p *PDFParser) SupportedExtensions() []string {
 	return []string{".pdf"}
 }
 
+// Capabilities はパーサーが抽出できる構造の種類を返す
+func (p *PDFParser) Capabilities() ParserCapabilities {
+	return ParserCapabilities{Pages: true, Metadata: true, Thumbnail: true}
+}
+
+// setPageRange はファクトリーのWithPageRangeをFirstPage・LastPageに反映する
+func (p *PDFParser) setPageRange(first, last int) {
+	p.FirstPage, p.LastPage = first, last
+}
+
+// setPageLabelRange はファクトリーのWithPageLabelRangeをFirstPageLabel・LastPageLabelに反映する
+func (p *PDFParser) setPageLabelRange(first, last string) {
+	p.FirstPageLabel, p.LastPageLabel = first, last
+}
+
+// setPassword はファクトリーのWithPasswordをPasswordに反映する
+func (p *PDFParser) setPassword(password string) {
+	p.Password = password
+}
+
+// setSanitizeOptions はファクトリーのWithSanitizeOptionsを各オプションに反映する
+func (p *PDFParser) setSanitizeOptions(sanitize SanitizeOptions) {
+	p.PreserveWhitespace = sanitize.PreserveWhitespace
+	p.DehyphenateLineBreaks = sanitize.DehyphenateLineBreaks
+	p.DedupeRepeatedLines = sanitize.DedupeRepeatedLines
+	p.ConcatenateRuns = sanitize.ConcatenateRuns
+}
+
+// setPreserveFormatting はファクトリーのSetPreserveFormattingをPreserveWhitespaceに反映する
+func (p *PDFParser) setPreserveFormatting(preserve bool) {
+	p.PreserveWhitespace = preserve
+}
+
 // ParseFromFile はファイルパスからPDFをパース
 func (p *PDFParser) ParseFromFile(filePath string) (string, error) {
 	return parseFromFileCommon(p, filePath)
@@ -31,14 +127,917 @@ func (p *PDFParser) ParseFromBytes(data []byte) (string, error) {
 
 // ParseFromReader はio.ReaderAtからPDFをパース
 func (p *PDFParser) ParseFromReader(reader io.ReaderAt, size int64) (string, error) {
-	pdfReader, err := pdf.NewReader(reader, size)
+	pages, err := p.extractPages(reader, size)
 	if err != nil {
-		return "", fmt.Errorf("error reading PDF: %w", err)
+		return "", err
 	}
 
+	return truncateOutput(p.formatPages(pages), p.MaxOutputBytes), nil
+}
+
+// formatPages はページごとのテキストをページ番号の見出し付きで結合する
+func (p *PDFParser) formatPages(pages []pdfPageText) string {
 	var result strings.Builder
+	for i, page := range pages {
+		p.writePageSeparator(&result, i)
+
+		// ページ番号を追加
+		result.WriteString(p.boundaryMarker(heading("## ", p.labels().PageHeader, page.name(), "\n\n"), "PAGE", page.name()))
+		result.WriteString(page.text)
+		result.WriteString("\n\n")
+	}
+
+	return result.String()
+}
+
+// parsePages はページごとのテキストを返す
+func (p *PDFParser) parsePages(reader io.ReaderAt, size int64) ([]Page, error) {
+	pages, err := p.extractPages(reader, size)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Page, len(pages))
+	for i, page := range pages {
+		result[i] = Page{Number: page.number, Name: "Page " + page.name(), Content: page.text}
+	}
+	return result, nil
+}
+
+// pdfPageText は1ページ分の抽出結果
+type pdfPageText struct {
+	number int
+	text   string
+	// lines はDedupeRepeatedLinesが有効な場合のみ使う位置付きの行
+	lines []pdfLine
+	// label はUsePageLabelsが有効な場合のページラベル
+	label string
+	// chars・badChars は品質スコアのための、サニタイズ前の空白以外の文字数と、そのうちの置換文字・制御文字の数
+	chars, badChars int
+}
+
+// name は見出しに使うページの名前を返す
+func (page pdfPageText) name() string {
+	if page.label != "" {
+		return page.label
+	}
+	return strconv.Itoa(page.number)
+}
+
+// extractPages は全てのページからテキストを抽出してサニタイズする
+func (p *PDFParser) extractPages(reader io.ReaderAt, size int64) ([]pdfPageText, error) {
+	pdfReader, err := p.newReader(reader, size)
+	if err != nil {
+		return nil, fmt.Errorf("error reading PDF: %w", err)
+	}
+
+	var pages []pdfPageText
+	first, last, err := p.pageRange(pdfReader)
+	if err != nil {
+		return nil, err
+	}
+	if p.Concurrency > 1 && last > first && p.MaxOutputBytes <= 0 {
+		pages, err = p.extractPagesConcurrently(reader, size, first, last)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		total := 0
+		for i := first; i <= last; i++ {
+			if page, ok := p.extractPage(pdfReader, i); ok {
+				pages = append(pages, page)
+				total += len(page.text)
+			}
+			// 繰り返し行の除去は全ページが必要なため打ち切らない
+			if !p.DedupeRepeatedLines && p.outputLimitReached(total) {
+				break
+			}
+		}
+	}
+
+	if p.DedupeRepeatedLines {
+		p.dedupeRepeatedLines(pages)
+	}
+
+	if p.UseStructureTree {
+		structured := pdfStructureTexts(pdfReader)
+		for i := range pages {
+			if text, ok := structured[pages[i].number]; ok {
+				pages[i].text = p.sanitizeLines(text)
+			}
+		}
+	}
+
+	if p.UsePageLabels {
+		labels := pdfPageLabels(pdfReader)
+		for i := range pages {
+			pages[i].label = labels[pages[i].number-1]
+		}
+	}
+
+	return pages, nil
+}
+
+// newReader はPDFを開く（Passwordが設定されている場合は暗号化されたPDFの復号に使う）
+// AttemptRecovery が true の場合、開けないかカタログを読めないPDFは相互参照表を作り直して開き直す
+func (p *PDFParser) newReader(reader io.ReaderAt, size int64) (*pdf.Reader, error) {
+	pdfReader, err := p.openReader(reader, size)
+	if !p.AttemptRecovery || err == nil && pdfCatalogReadable(pdfReader) {
+		return pdfReader, err
+	}
+
+	if repaired, rebuildErr := rebuildPDFXref(reader, size); rebuildErr == nil {
+		recovered, recoverErr := p.openReader(bytes.NewReader(repaired), int64(len(repaired)))
+		if recoverErr == nil && pdfCatalogReadable(recovered) {
+			return recovered, nil
+		}
+	}
+	return pdfReader, err
+}
+
+// openReader はPDFを開く
+func (p *PDFParser) openReader(reader io.ReaderAt, size int64) (*pdf.Reader, error) {
+	if p.Password == "" {
+		return pdf.NewReader(reader, size)
+	}
+
+	// pdfライブラリは空文字列が返るまでパスワードを問い合わせ続けるため、1回だけ返す
+	tried := false
+	return pdf.NewReaderEncrypted(reader, size, func() string {
+		if tried {
+			return ""
+		}
+		tried = true
+		return p.Password
+	})
+}
+
+// pdfCatalogReadable は相互参照表からカタログ（/Root）とページツリーを読めるかどうかを返す
+// 相互参照表のオフセットがずれているPDFは開けても、オブジェクトを読む時点でpanicになる
+func pdfCatalogReadable(pdfReader *pdf.Reader) (ok bool) {
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+	return pdfReader.Trailer().Key("Root").Key("Pages").Kind() == pdf.Dict
+}
+
+var (
+	// pdfObjectPattern は間接オブジェクトの開始（"12 0 obj"）
+	pdfObjectPattern = regexp.MustCompile(`(?:^|\s)(\d{1,7})\s+(\d{1,5})\s+obj\b`)
+	// pdfCatalogPattern はカタログの辞書の "/Type /Catalog"
+	pdfCatalogPattern = regexp.MustCompile(`/Type\s*/Catalog\b`)
+	// pdfTrailerPatterns はトレーラー（または相互参照ストリームの辞書）から引き継ぐエントリ
+	pdfTrailerPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`/Info\s+\d+\s+\d+\s+R`),
+		regexp.MustCompile(`/Encrypt\s+\d+\s+\d+\s+R`),
+		regexp.MustCompile(`/ID\s*\[\s*<[0-9A-Fa-f]*>\s*<[0-9A-Fa-f]*>\s*\]`),
+	}
+	pdfRootPattern = regexp.MustCompile(`/Root\s+(\d+)\s+(\d+)\s+R`)
+)
+
+// pdfMaxObjectNumber は相互参照表を作り直す際に受け付けるオブジェクト番号の上限（PDFの実装上の制限の8,388,607）
+// 壊れたデータの中の大きな番号で相互参照表が巨大にならないように、これを超える番号のオブジェクトは無視する
+const pdfMaxObjectNumber = 1<<23 - 1
+
+// pdfObjectOffset は作り直す相互参照表の1つのエントリ
+type pdfObjectOffset struct {
+	offset     int
+	generation int
+}
+
+// rebuildPDFXref はファイル全体から間接オブジェクトの位置を探し、作り直した相互参照表とトレーラーを末尾に追加したデータを返す
+// 同じ番号のオブジェクトが複数ある場合（増分更新）は後のものを使う。カタログは最後のトレーラーの /Root を使い、
+// その番号のオブジェクトがない場合は "/Type /Catalog" を持つ最後のオブジェクトとする
+func rebuildPDFXref(reader io.ReaderAt, size int64) ([]byte, error) {
+	data, err := io.ReadAll(io.NewSectionReader(reader, 0, size))
+	if err != nil {
+		return nil, fmt.Errorf("error reading PDF: %w", err)
+	}
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		return nil, fmt.Errorf("not a PDF file: invalid header")
+	}
+
+	objects := make(map[int]pdfObjectOffset)
+	maxNumber, catalog := 0, -1
+	matches := pdfObjectPattern.FindAllSubmatchIndex(data, -1)
+	for i, m := range matches {
+		number, err := strconv.Atoi(string(data[m[2]:m[3]]))
+		if err != nil {
+			continue
+		}
+		if number > pdfMaxObjectNumber {
+			continue
+		}
+		generation, _ := strconv.Atoi(string(data[m[4]:m[5]]))
+		objects[number] = pdfObjectOffset{offset: m[2], generation: generation}
+		maxNumber = max(maxNumber, number)
+
+		// オブジェクトの辞書（endobj・stream・次のオブジェクトまで）がカタログかどうかを調べる
+		body := data[m[1]:]
+		if i+1 < len(matches) {
+			body = data[m[1]:matches[i+1][0]]
+		}
+		for _, keyword := range []string{"endobj", "stream"} {
+			if end := bytes.Index(body, []byte(keyword)); end >= 0 {
+				body = body[:end]
+			}
+		}
+		if pdfCatalogPattern.Match(body) {
+			catalog = number
+		}
+	}
+
+	if m := lastSubmatch(pdfRootPattern, data); m != nil {
+		if number, err := strconv.Atoi(string(m[1])); err == nil {
+			if _, ok := objects[number]; ok {
+				catalog = number
+			}
+		}
+	}
+	if catalog < 0 {
+		return nil, fmt.Errorf("no catalog object found")
+	}
+
+	var buf bytes.Buffer
+	buf.Write(data)
+	if !bytes.HasSuffix(data, []byte("\n")) {
+		buf.WriteString("\n")
+	}
+	xrefOffset := buf.Len()
+	// 見つかったオブジェクトのみを番号の連続する範囲ごとのサブセクションとして書く
+	numbers := make([]int, 0, len(objects))
+	for number := range objects {
+		numbers = append(numbers, number)
+	}
+	sort.Ints(numbers)
+	buf.WriteString("xref\n0 1\n0000000000 65535 f\r\n")
+	for start := 0; start < len(numbers); {
+		end := start + 1
+		for end < len(numbers) && numbers[end] == numbers[end-1]+1 {
+			end++
+		}
+		fmt.Fprintf(&buf, "%d %d\n", numbers[start], end-start)
+		for _, number := range numbers[start:end] {
+			fmt.Fprintf(&buf, "%010d %05d n\r\n", objects[number].offset, objects[number].generation)
+		}
+		start = end
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root %d %d R", maxNumber+1, catalog, objects[catalog].generation)
+	for _, pattern := range pdfTrailerPatterns {
+		if m := lastSubmatch(pattern, data); m != nil {
+			buf.WriteString(" ")
+			buf.Write(m[0])
+		}
+	}
+	fmt.Fprintf(&buf, " >>\nstartxref\n%d\n%%%%EOF\n", xrefOffset)
+	return buf.Bytes(), nil
+}
+
+// lastSubmatch はdataの中でpatternに一致する最後の箇所のサブマッチを返す（一致しない場合はnil）
+func lastSubmatch(pattern *regexp.Regexp, data []byte) [][]byte {
+	matches := pattern.FindAllSubmatch(data, -1)
+	if len(matches) == 0 {
+		return nil
+	}
+	return matches[len(matches)-1]
+}
 
-	// 全てのページからテキストを抽出
+// pageRange はFirstPage・LastPage（ページラベルが指定されている場合はFirstPageLabel・LastPageLabel）を
+// ページ数の範囲に収めて返す
+func (p *PDFParser) pageRange(pdfReader *pdf.Reader) (first, last int, err error) {
+	numPages := pdfReader.NumPage()
+	first, last = max(p.FirstPage, 1), numPages
+	if p.LastPage > 0 {
+		last = min(p.LastPage, numPages)
+	}
+	if p.FirstPageLabel == "" && p.LastPageLabel == "" {
+		return first, last, nil
+	}
+
+	labels := pdfPageLabels(pdfReader)
+	find := func(label string, from int) (int, error) {
+		for i := from; i <= len(labels); i++ {
+			if labels[i-1] == label {
+				return i, nil
+			}
+		}
+		return 0, fmt.Errorf("%w: %q", ErrPageLabelNotFound, label)
+	}
+	if p.FirstPageLabel != "" {
+		if first, err = find(p.FirstPageLabel, 1); err != nil {
+			return 0, 0, err
+		}
+	}
+	if p.LastPageLabel != "" {
+		if last, err = find(p.LastPageLabel, first); err != nil {
+			return 0, 0, err
+		}
+	}
+	return first, last, nil
+}
+
+// extractPage は1ページ分のテキストを抽出してサニタイズする
+// ページが存在しない場合は false を返す
+func (p *PDFParser) extractPage(pdfReader *pdf.Reader, number int) (pdfPageText, bool) {
+	page := pdfReader.Page(number)
+	if page.V.IsNull() {
+		return pdfPageText{}, false
+	}
+
+	texts := page.Content().Text
+	chars, badChars := countQualityChars(texts)
+
+	// 繰り返し行の除去は全ページの行が揃ってから行う
+	if p.DedupeRepeatedLines {
+		return pdfPageText{number: number, lines: pdfPositionedLines(texts), chars: chars, badChars: badChars}, true
+	}
+
+	// ページ内のテキストを結合してサニタイズ
+	text := ""
+	if pageContent := p.extractPageText(texts); pageContent != "" {
+		text = p.sanitize(pageContent)
+	}
+	return pdfPageText{number: number, text: text, chars: chars, badChars: badChars}, true
+}
+
+// pdfDigitsPattern は繰り返し行の比較で同一視する数字の並び
+var pdfDigitsPattern = regexp.MustCompile(`\d+`)
+
+// repeatedLineTolerance は同じ位置とみなすベースラインのY座標の差（ポイント）
+const repeatedLineTolerance = 5.0
+
+// dedupeRepeatedLines は過半数のページで同じ位置に現れる行（ヘッダー・フッター・ページ番号）を除去し、
+// 残りの行からページのテキストを組み立てる
+// 数字の並びは "#" に置き換えて比較するため、"Page 3 of 200" のようなページ番号も繰り返し行として扱う
+func (p *PDFParser) dedupeRepeatedLines(pages []pdfPageText) {
+	key := func(line pdfLine) string {
+		normalized := pdfDigitsPattern.ReplaceAllString(line.text, "#")
+		return fmt.Sprintf("%s@%d", normalized, int(math.Round(line.y/repeatedLineTolerance)))
+	}
+
+	counts := make(map[string]int)
+	for _, page := range pages {
+		seen := make(map[string]bool)
+		for _, line := range page.lines {
+			k := key(line)
+			if !seen[k] {
+				seen[k] = true
+				counts[k]++
+			}
+		}
+	}
+
+	for i := range pages {
+		var lines []string
+		for _, line := range pages[i].lines {
+			if n := counts[key(line)]; n >= 2 && n*2 > len(pages) {
+				continue
+			}
+			lines = append(lines, line.text)
+		}
+
+		text := strings.Join(lines, " ")
+		if p.DehyphenateLineBreaks || p.PreserveWhitespace {
+			text = strings.Join(lines, "\n")
+		}
+		if p.DehyphenateLineBreaks {
+			text = dehyphenateLineBreaks(text)
+		}
+		pages[i].text = p.sanitize(text)
+		pages[i].lines = nil
+	}
+}
+
+// extractPagesConcurrently はConcurrency個のワーカーでfirstからlastまでのページを並列に抽出し、ページ順に並べて返す
+// pdf.Readerはスレッドセーフではないため、ワーカーごとに別のReaderを開く
+func (p *PDFParser) extractPagesConcurrently(reader io.ReaderAt, size int64, first, last int) ([]pdfPageText, error) {
+	type pageResult struct {
+		page pdfPageText
+		ok   bool
+	}
+
+	results := make([]pageResult, last-first+1)
+	jobs := make(chan int)
+	errs := make(chan error, p.Concurrency)
+
+	var wg sync.WaitGroup
+	for w := 0; w < min(p.Concurrency, len(results)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := func() (err error) {
+				defer recoverPDFPanic(&err)
+
+				pdfReader, err := p.newReader(reader, size)
+				if err != nil {
+					return fmt.Errorf("error reading PDF: %w", err)
+				}
+				for number := range jobs {
+					page, ok := p.extractPage(pdfReader, number)
+					results[number-first] = pageResult{page: page, ok: ok}
+				}
+				return nil
+			}()
+			if err != nil {
+				errs <- err
+			}
+		}()
+	}
+
+	var firstErr error
+	for number := first; number <= last && firstErr == nil; number++ {
+		select {
+		case jobs <- number:
+		case firstErr = <-errs:
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	close(errs)
+
+	if firstErr == nil {
+		firstErr = <-errs
+	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	var pages []pdfPageText
+	for _, result := range results {
+		if result.ok {
+			pages = append(pages, result.page)
+		}
+	}
+	return pages, nil
+}
+
+// ParseWithQuality はテキストと抽出品質のスコア（0.0〜1.0）を返す
+// スコアは置換文字（U+FFFD）や制御文字以外の文字の割合と、テキストを含むページの割合の積で、
+// スキャンPDFや文字化けしたPDFでは低くなる。OCRへのフォールバック判定に利用できる
+func (p *PDFParser) ParseWithQuality(reader io.ReaderAt, size int64) (string, float64, error) {
+	pages, err := p.extractPages(reader, size)
+	if err != nil {
+		return "", 0, err
+	}
+
+	return p.formatPages(pages), extractionQuality(pages), nil
+}
+
+// extractionQuality は抽出結果の品質スコアを計算する
+// 文字の割合はサニタイズで置換文字を除去する前のテキスト片から数えた値を使う
+func extractionQuality(pages []pdfPageText) float64 {
+	if len(pages) == 0 {
+		return 0
+	}
+
+	total, bad, pagesWithText := 0, 0, 0
+	for _, page := range pages {
+		if strings.TrimSpace(page.text) != "" {
+			pagesWithText++
+		}
+		total += page.chars
+		bad += page.badChars
+	}
+
+	if total == 0 {
+		return 0
+	}
+
+	charRatio := float64(total-bad) / float64(total)
+	pageRatio := float64(pagesWithText) / float64(len(pages))
+	return charRatio * pageRatio
+}
+
+// countQualityChars はテキスト片の空白以外の文字数と、そのうちの置換文字（U+FFFD）・制御文字の数を返す
+func countQualityChars(texts []pdf.Text) (total, bad int) {
+	for _, text := range texts {
+		for _, r := range text.S {
+			if unicode.IsSpace(r) {
+				continue
+			}
+			total++
+			if r == unicode.ReplacementChar || unicode.IsControl(r) {
+				bad++
+			}
+		}
+	}
+	return total, bad
+}
+
+// extractPageText はページ内のテキスト片を連結する
+func (p *PDFParser) extractPageText(texts []pdf.Text) string {
+	if p.DehyphenateLineBreaks {
+		return dehyphenateLineBreaks(strings.Join(pdfLines(texts), "\n"))
+	}
+	if p.PreserveWhitespace {
+		return strings.Join(pdfLines(texts), "\n")
+	}
+
+	if p.ConcatenateRuns {
+		// ベースラインが変わる（改行される）テキスト片の間にはスペースを入れる
+		var sb strings.Builder
+		for i, text := range texts {
+			if i > 0 && math.Abs(text.Y-texts[i-1].Y) >= max(text.FontSize, texts[i-1].FontSize)*0.5 {
+				sb.WriteByte(' ')
+			}
+			sb.WriteString(cleanPDFRun(text.S))
+		}
+		return sb.String()
+	}
+
+	var pageTexts []string
+	for _, text := range texts {
+		cleanedText := strings.TrimSpace(text.S)
+		if cleanedText != "" {
+			pageTexts = append(pageTexts, cleanedText)
+		}
+	}
+	return strings.Join(pageTexts, " ")
+}
+
+// cleanPDFRun はConcatenateRunsで連結するテキスト片の制御文字を除く
+// 前後の空白を除かない代わりに、改行・タブなどの空白の制御文字はスペースにし、それ以外の制御文字は削除する
+func cleanPDFRun(s string) string {
+	return strings.Map(func(r rune) rune {
+		if !unicode.IsControl(r) {
+			return r
+		}
+		if unicode.IsSpace(r) {
+			return ' '
+		}
+		return -1
+	}, s)
+}
+
+// pdfLine はベースラインの位置でまとめた1行分のテキスト
+type pdfLine struct {
+	text string
+	y    float64
+}
+
+// pdfLines はテキスト片をベースラインの位置で行にまとめる
+func pdfLines(texts []pdf.Text) []string {
+	var lines []string
+	for _, line := range pdfPositionedLines(texts) {
+		lines = append(lines, line.text)
+	}
+	return lines
+}
+
+// pdfPositionedLines はテキスト片を行にまとめ、行ごとのベースラインのY座標とともに返す
+func pdfPositionedLines(texts []pdf.Text) []pdfLine {
+	var lines []pdfLine
+	var line []string
+	var lastY, lastH float64
+	for _, span := range mergeTextSpans(texts) {
+		text := strings.TrimSpace(span.Text)
+		if text == "" {
+			continue
+		}
+		if len(line) > 0 && math.Abs(span.Y-lastY) >= max(span.H, lastH)*0.5 {
+			lines = append(lines, pdfLine{text: strings.Join(line, " "), y: lastY})
+			line = nil
+		}
+		line = append(line, text)
+		lastY, lastH = span.Y, span.H
+	}
+	if len(line) > 0 {
+		lines = append(lines, pdfLine{text: strings.Join(line, " "), y: lastY})
+	}
+	return lines
+}
+
+// hyphenatedLineBreakPattern は行末のハイフンで分割された単語にマッチする
+// 次の行が小文字で始まる場合のみ対象とし、行中のハイフン付き複合語は対象外
+var hyphenatedLineBreakPattern = regexp.MustCompile(`(\pL)-[ \t]*\n[ \t]*(\p{Ll})`)
+
+// dehyphenateLineBreaks は行末のハイフンで分割された単語を結合する
+func dehyphenateLineBreaks(text string) string {
+	return hyphenatedLineBreakPattern.ReplaceAllString(text, "$1$2")
+}
+
+// sanitizeLines は行ごとにサニタイズし、空行を除いて改行で結合する
+func (p *PDFParser) sanitizeLines(text string) string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = p.sanitize(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// pdfInlineStructureTypes は段落の中に現れるインライン要素の構造型
+// これ以外の要素（P、H1、LI、TD など）は前後で改行する
+var pdfInlineStructureTypes = map[string]bool{
+	"Span": true, "Link": true, "Quote": true, "Note": true, "Reference": true, "BibEntry": true,
+	"Code": true, "Lbl": true, "Annot": true, "Ruby": true, "RB": true, "RT": true, "RP": true,
+	"Warichu": true, "WT": true, "WP": true, "Em": true, "Strong": true, "Sub": true,
+}
+
+// pdfStructureWalker はタグ付きPDFの構造ツリーをたどってページごとのテキストを組み立てる
+type pdfStructureWalker struct {
+	// pageNumbers はページの辞書の内容の文字列からページ番号への対応
+	// 全く同じ辞書を持つページは区別できないため、最初のページになる
+	pageNumbers map[string]int
+	// contents はページ番号ごとの、マーク付きコンテンツID（MCID）ごとのテキスト
+	contents map[int]map[int]string
+	pages    map[int]*pdf.Page
+	roleMap  pdf.Value
+	texts    map[int]*strings.Builder
+	// visited はたどった構造要素（共有された子を何度もたどらないようにする）
+	visited *pdfTreeWalk
+	// lastPage は最後にテキストを書き込んだページ（ページを持たない改行の書き込み先）
+	lastPage int
+}
+
+// pdfStructureTexts はタグ付きPDFの構造ツリーの順番で組み立てたページごとのテキストを返す
+// 構造ツリーがない、または読み取れない場合はnilを返す
+func pdfStructureTexts(pdfReader *pdf.Reader) (texts map[int]string) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("failed to read PDF structure tree: %v", r)
+			texts = nil
+		}
+	}()
+
+	root := pdfReader.Trailer().Key("Root").Key("StructTreeRoot")
+	if root.IsNull() {
+		return nil
+	}
+
+	w := &pdfStructureWalker{
+		pageNumbers: make(map[string]int),
+		contents:    make(map[int]map[int]string),
+		pages:       make(map[int]*pdf.Page),
+		roleMap:     root.Key("RoleMap"),
+		texts:       make(map[int]*strings.Builder),
+		visited:     newPDFTreeWalk(),
+	}
+	for i := 1; i <= pdfReader.NumPage(); i++ {
+		page := pdfReader.Page(i)
+		if _, ok := w.pageNumbers[page.V.String()]; !ok {
+			w.pageNumbers[page.V.String()] = i
+		}
+		w.pages[i] = &page
+	}
+
+	w.walk(root.Key("K"), 0, 0)
+
+	texts = make(map[int]string)
+	for number, sb := range w.texts {
+		if text := strings.TrimSpace(sb.String()); text != "" {
+			texts[number] = text
+		}
+	}
+	return texts
+}
+
+// walk は構造要素（またはその子の配列・MCID）をたどる。parentはnodeを含む値、pageは継承されたページ番号
+// 同じ間接オブジェクトは1度だけたどる（子を共有する不正なツリーで指数的に時間がかからないようにする）
+func (w *pdfStructureWalker) walk(node pdf.Value, page int, depth int) {
+	if depth > 256 || !w.visited.visit(node, page) {
+		return
+	}
+
+	switch node.Kind() {
+	case pdf.Array:
+		for i := 0; i < node.Len(); i++ {
+			w.walk(node.Index(i), page, depth+1)
+		}
+		return
+	case pdf.Integer:
+		w.writeContent(page, int(node.Int64()))
+		return
+	case pdf.Dict:
+	default:
+		return
+	}
+
+	if pg := node.Key("Pg"); !pg.IsNull() {
+		page = w.pageNumbers[pg.String()]
+	}
+
+	switch node.Key("Type").Name() {
+	case "MCR":
+		w.writeContent(page, int(node.Key("MCID").Int64()))
+		return
+	case "OBJR":
+		return
+	}
+
+	structType := w.role(node.Key("S").Name())
+	if actual := node.Key("ActualText"); !actual.IsNull() {
+		w.write(page, actual.Text())
+		return
+	}
+	if structType == "Figure" {
+		if alt := strings.TrimSpace(node.Key("Alt").Text()); alt != "" {
+			w.writeBreak(page)
+			w.write(page, fmt.Sprintf("[Figure: %s]", alt))
+			w.writeBreak(page)
+		}
+		return
+	}
+	if structType == "Artifact" {
+		return
+	}
+
+	block := !pdfInlineStructureTypes[structType]
+	if block {
+		w.writeBreak(page)
+	}
+	w.walk(node.Key("K"), page, depth+1)
+	if block {
+		w.writeBreak(page)
+	}
+}
+
+// role はRoleMapで独自の構造型を標準の構造型に変換する
+func (w *pdfStructureWalker) role(structType string) string {
+	for i := 0; i < 8; i++ {
+		mapped := w.roleMap.Key(structType).Name()
+		if mapped == "" || mapped == structType {
+			break
+		}
+		structType = mapped
+	}
+	return structType
+}
+
+// writeContent はページのMCIDのテキストを書き込む
+func (w *pdfStructureWalker) writeContent(page, mcid int) {
+	if page == 0 {
+		return
+	}
+	contents, ok := w.contents[page]
+	if !ok {
+		contents = pdfMarkedContents(*w.pages[page])
+		w.contents[page] = contents
+	}
+	text := contents[mcid]
+	if text == "" {
+		return
+	}
+	// 同じ要素内の複数のMCID（行ごとに分かれた段落など）はスペースで区切る
+	if sb, ok := w.texts[page]; ok && sb.Len() > 0 && !strings.HasSuffix(sb.String(), "\n") {
+		text = " " + text
+	}
+	w.write(page, text)
+}
+
+// write はページのテキストに追記する
+func (w *pdfStructureWalker) write(page int, text string) {
+	if page == 0 || text == "" {
+		return
+	}
+	sb, ok := w.texts[page]
+	if !ok {
+		sb = &strings.Builder{}
+		w.texts[page] = sb
+	}
+	sb.WriteString(text)
+	w.lastPage = page
+}
+
+// writeBreak はブロック要素の区切りの改行を書き込む
+func (w *pdfStructureWalker) writeBreak(page int) {
+	if page == 0 {
+		page = w.lastPage
+	}
+	if sb, ok := w.texts[page]; ok && sb.Len() > 0 && !strings.HasSuffix(sb.String(), "\n") {
+		sb.WriteString("\n")
+	}
+}
+
+// pdfMarkedContents はページのコンテンツストリームを解釈し、MCIDごとのテキストを返す
+// MCIDを持つマーク付きコンテンツの外側のテキスト（アーティファクトなど）は含めない
+func pdfMarkedContents(page pdf.Page) map[int]string {
+	contents := make(map[int]*strings.Builder)
+	if page.V.Key("Contents").IsNull() {
+		return nil
+	}
+
+	// stack はBDC/BMCの入れ子ごとのMCID（MCIDを持たない場合は-1）
+	var stack []int
+	current := func() *strings.Builder {
+		for i := len(stack) - 1; i >= 0; i-- {
+			if stack[i] >= 0 {
+				sb, ok := contents[stack[i]]
+				if !ok {
+					sb = &strings.Builder{}
+					contents[stack[i]] = sb
+				}
+				return sb
+			}
+		}
+		return nil
+	}
+	separate := func() {
+		if sb := current(); sb != nil && sb.Len() > 0 && !strings.HasSuffix(sb.String(), " ") {
+			sb.WriteString(" ")
+		}
+	}
+
+	var enc pdf.TextEncoding
+	show := func(raw string) {
+		sb := current()
+		if sb == nil {
+			return
+		}
+		if enc == nil {
+			sb.WriteString(raw)
+			return
+		}
+		sb.WriteString(enc.Decode(raw))
+	}
+
+	pdf.Interpret(page.V.Key("Contents"), func(stk *pdf.Stack, op string) {
+		n := stk.Len()
+		args := make([]pdf.Value, n)
+		for i := n - 1; i >= 0; i-- {
+			args[i] = stk.Pop()
+		}
+
+		switch op {
+		case "BMC":
+			stack = append(stack, -1)
+		case "BDC":
+			mcid := -1
+			if len(args) == 2 {
+				props := args[1]
+				if props.Kind() == pdf.Name {
+					props = page.Resources().Key("Properties").Key(props.Name())
+				}
+				if v := props.Key("MCID"); v.Kind() == pdf.Integer {
+					mcid = int(v.Int64())
+				}
+			}
+			stack = append(stack, mcid)
+		case "EMC":
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		case "Tf":
+			if len(args) == 2 {
+				enc = page.Font(args[0].Name()).Encoder()
+			}
+		case "Td", "TD", "T*", "Tm":
+			separate()
+		case "'", "\"":
+			separate()
+			if len(args) > 0 {
+				show(args[len(args)-1].RawString())
+			}
+		case "Tj":
+			if len(args) == 1 {
+				show(args[0].RawString())
+			}
+		case "TJ":
+			if len(args) != 1 {
+				return
+			}
+			for i := 0; i < args[0].Len(); i++ {
+				x := args[0].Index(i)
+				if x.Kind() == pdf.String {
+					show(x.RawString())
+				} else if x.Float64() < -250 {
+					// 大きな字間の調整は単語の区切りとみなす
+					separate()
+				}
+			}
+		}
+	})
+
+	result := make(map[int]string, len(contents))
+	for mcid, sb := range contents {
+		result[mcid] = strings.TrimSpace(sb.String())
+	}
+	return result
+}
+
+// TextSpan はPDFページ上のテキストとその位置を表す
+// 座標はポイント単位で、Yはページ下端からのベースラインの位置
+type TextSpan struct {
+	Page int
+	Text string
+	X    float64
+	Y    float64
+	W    float64
+	H    float64
+}
+
+// ParsePagePositions はページごとのテキストを位置情報付きで返す
+// 同じ行で隣接する文字は1つのTextSpanにまとめられる
+func (p *PDFParser) ParsePagePositions(reader io.ReaderAt, size int64) ([]TextSpan, error) {
+	pdfReader, err := p.newReader(reader, size)
+	if err != nil {
+		return nil, fmt.Errorf("error reading PDF: %w", err)
+	}
+
+	var spans []TextSpan
 	numPages := pdfReader.NumPage()
 	for i := 1; i <= numPages; i++ {
 		page := pdfReader.Page(i)
@@ -46,30 +1045,419 @@ func (p *PDFParser) ParseFromReader(reader io.ReaderAt, size int64) (string, err
 			continue
 		}
 
-		// ページ番号を追加
-		result.WriteString(fmt.Sprintf("## Page %d\n\n", i))
+		for _, span := range mergeTextSpans(page.Content().Text) {
+			if strings.TrimSpace(span.Text) == "" {
+				continue
+			}
+			span.Page = i
+			spans = append(spans, span)
+		}
+	}
+
+	return spans, nil
+}
 
-		// ページからテキストを抽出し、連結
-		var pageTexts []string
-		texts := page.Content().Text
-		for _, text := range texts {
-			cleanedText := strings.TrimSpace(text.S)
-			if cleanedText != "" {
-				pageTexts = append(pageTexts, cleanedText)
+// mergeTextSpans は同じ行・同じフォントで隣接するテキスト片を1つのTextSpanにまとめる
+func mergeTextSpans(texts []pdf.Text) []TextSpan {
+	var spans []TextSpan
+	var font string
+	for _, text := range texts {
+		if len(spans) > 0 {
+			last := &spans[len(spans)-1]
+			gap := text.X - (last.X + last.W)
+			sameLine := text.Font == font &&
+				text.FontSize == last.H &&
+				math.Abs(text.Y-last.Y) < text.FontSize*0.2
+			if sameLine && gap > -text.FontSize*0.2 && gap < text.FontSize {
+				// 明示的な空白がない単語間の隙間は空白として扱う
+				if gap > text.FontSize*0.25 && !strings.HasSuffix(last.Text, " ") && !strings.HasPrefix(text.S, " ") {
+					last.Text += " "
+				}
+				last.Text += text.S
+				last.W = text.X + text.W - last.X
+				continue
 			}
 		}
 
-		// ページ内のテキストを結合してサニタイズ
-		if len(pageTexts) > 0 {
-			pageContent := strings.Join(pageTexts, " ")
-			sanitizedContent := sanitizeText(pageContent)
-			result.WriteString(sanitizedContent)
+		font = text.Font
+		spans = append(spans, TextSpan{
+			Text: text.S,
+			X:    text.X,
+			Y:    text.Y,
+			W:    text.W,
+			H:    text.FontSize,
+		})
+	}
+	return spans
+}
+
+// ParseMetadata はPDFのInfo辞書とXMPメタデータ（/Metadataストリーム）からメタデータを抽出する
+// XMPのdc:title、dc:description、キーワードはInfo辞書に値がない場合の補完に使われる
+func (p *PDFParser) ParseMetadata(reader io.ReaderAt, size int64) (metadata *Metadata, err error) {
+	defer recoverPDFPanic(&err)
+
+	pdfReader, err := p.newReader(reader, size)
+	if err != nil {
+		return nil, fmt.Errorf("error reading PDF: %w", err)
+	}
+
+	metadata = &Metadata{}
+	trailer := pdfReader.Trailer()
+
+	info := trailer.Key("Info")
+	metadata.Title = info.Key("Title").Text()
+	metadata.Author = info.Key("Author").Text()
+	metadata.Subject = info.Key("Subject").Text()
+	metadata.Creator = info.Key("Creator").Text()
+	metadata.Producer = info.Key("Producer").Text()
+	metadata.Created = formatPDFDate(info.Key("CreationDate").Text())
+	metadata.Modified = formatPDFDate(info.Key("ModDate").Text())
+	metadata.Keywords = splitKeywords(info.Key("Keywords").Text())
+
+	stream := trailer.Key("Root").Key("Metadata")
+	if stream.Kind() != pdf.Stream {
+		return metadata, nil
+	}
+
+	rc := stream.Reader()
+	defer rc.Close()
+	xmp, err := parseXMP(rc)
+	if err != nil {
+		log.Printf("Error parsing XMP metadata: %s", err)
+		return metadata, nil
+	}
+
+	if metadata.Title == "" {
+		metadata.Title = xmp.title
+	}
+	if metadata.Author == "" {
+		metadata.Author = strings.Join(xmp.creators, ", ")
+	}
+	metadata.Description = xmp.description
+	if len(metadata.Keywords) == 0 {
+		metadata.Keywords = xmp.keywords
+	}
+
+	return metadata, nil
+}
+
+// xmpMetadata はXMPから取り出した項目
+type xmpMetadata struct {
+	title       string
+	description string
+	creators    []string
+	keywords    []string
+}
+
+// parseXMP はXMPパケットからdc:title、dc:description、dc:creator、キーワードを取り出す
+// 要素形式（rdf:Alt/rdf:Bag内のrdf:li）と属性形式の両方に対応する
+func parseXMP(r io.Reader) (*xmpMetadata, error) {
+	xmp := &xmpMetadata{}
+	decoder := xml.NewDecoder(r)
+
+	var stack []string
+	for {
+		t, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
 		}
 
-		result.WriteString("\n\n")
+		switch se := t.(type) {
+		case xml.StartElement:
+			stack = append(stack, se.Name.Local)
+			if se.Name.Local == "Description" {
+				for _, attr := range se.Attr {
+					switch attr.Name.Local {
+					case "title":
+						xmp.title = attr.Value
+					case "description":
+						xmp.description = attr.Value
+					case "Keywords":
+						xmp.keywords = append(xmp.keywords, splitKeywords(attr.Value)...)
+					}
+				}
+			}
+		case xml.EndElement:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		case xml.CharData:
+			text := strings.TrimSpace(string(se))
+			if text == "" || len(stack) == 0 {
+				continue
+			}
+			switch xmpProperty(stack) {
+			case "title":
+				if xmp.title == "" {
+					xmp.title = text
+				}
+			case "description":
+				if xmp.description == "" {
+					xmp.description = text
+				}
+			case "creator":
+				xmp.creators = append(xmp.creators, text)
+			case "subject":
+				xmp.keywords = append(xmp.keywords, text)
+			case "Keywords":
+				xmp.keywords = append(xmp.keywords, splitKeywords(text)...)
+			}
+		}
 	}
 
-	return result.String(), nil
+	return xmp, nil
+}
+
+// xmpProperty は現在の要素が属するXMPプロパティ名を返す
+// rdf:Alt/rdf:Bag/rdf:Seq とその rdf:li は読み飛ばす
+func xmpProperty(stack []string) string {
+	for i := len(stack) - 1; i >= 0; i-- {
+		switch stack[i] {
+		case "li", "Alt", "Bag", "Seq":
+			continue
+		}
+		return stack[i]
+	}
+	return ""
+}
+
+// splitKeywords はカンマまたはセミコロン区切りのキーワードを分割する
+func splitKeywords(text string) []string {
+	var keywords []string
+	for _, keyword := range strings.FieldsFunc(text, func(r rune) bool { return r == ',' || r == ';' }) {
+		if keyword = strings.TrimSpace(keyword); keyword != "" {
+			keywords = append(keywords, keyword)
+		}
+	}
+	return keywords
+}
+
+// formatPDFDate はPDFの日付文字列（D:YYYYMMDDHHmmSS）を "YYYY-MM-DD HH:MM:SS" 形式に変換する
+// 解釈できない場合は元の文字列を返す
+func formatPDFDate(date string) string {
+	d := strings.TrimPrefix(date, "D:")
+	if len(d) < 8 {
+		return date
+	}
+	for _, r := range d[:8] {
+		if r < '0' || r > '9' {
+			return date
+		}
+	}
+	formatted := d[0:4] + "-" + d[4:6] + "-" + d[6:8]
+	if len(d) >= 14 {
+		formatted += " " + d[8:10] + ":" + d[10:12] + ":" + d[12:14]
+	}
+	return formatted
+}
+
+// ErrPageLabelNotFound はParsePageByLabel・FirstPageLabel・LastPageLabelで指定したページラベルがPDFにない場合に返すエラー
+var ErrPageLabelNotFound = errors.New("page label not found")
+
+// PageLabels はページごとのページラベル（"i"、"ii"、"A-1" など）を物理的なページ順に返す
+// ページラベルが定義されていないPDFでは "1"、"2"... を返す
+func (p *PDFParser) PageLabels(reader io.ReaderAt, size int64) (labels []string, err error) {
+	defer recoverPDFPanic(&err)
+
+	pdfReader, err := p.newReader(reader, size)
+	if err != nil {
+		return nil, fmt.Errorf("error reading PDF: %w", err)
+	}
+	return pdfPageLabels(pdfReader), nil
+}
+
+// ParsePageByLabel はページラベルが一致するページのみをパースする
+func (p *PDFParser) ParsePageByLabel(reader io.ReaderAt, size int64, label string) (text string, err error) {
+	defer recoverPDFPanic(&err)
+
+	pdfReader, err := p.newReader(reader, size)
+	if err != nil {
+		return "", fmt.Errorf("error reading PDF: %w", err)
+	}
+
+	for i, pageLabel := range pdfPageLabels(pdfReader) {
+		if pageLabel != label {
+			continue
+		}
+		page, ok := p.extractPage(pdfReader, i+1)
+		if !ok {
+			break
+		}
+		pages := []pdfPageText{page}
+		if p.DedupeRepeatedLines {
+			p.dedupeRepeatedLines(pages)
+		}
+		pages[0].label = label
+		return p.formatPages(pages), nil
+	}
+
+	return "", fmt.Errorf("%w: %q", ErrPageLabelNotFound, label)
+}
+
+// pdfPageLabels はカタログの/PageLabels（数値ツリー）からページラベルを組み立てる
+func pdfPageLabels(pdfReader *pdf.Reader) []string {
+	numPages := pdfReader.NumPage()
+	labels := make([]string, numPages)
+	for i := range labels {
+		labels[i] = strconv.Itoa(i + 1)
+	}
+
+	ranges := make(map[int]pdf.Value)
+	root := pdfReader.Trailer().Key("Root")
+	collectPageLabelRanges(root.Key("PageLabels"), ranges, newPDFTreeWalk(), 0)
+	if len(ranges) == 0 {
+		return labels
+	}
+
+	starts := make([]int, 0, len(ranges))
+	for start := range ranges {
+		starts = append(starts, start)
+	}
+	sort.Ints(starts)
+
+	for i, start := range starts {
+		end := numPages
+		if i+1 < len(starts) {
+			end = min(starts[i+1], numPages)
+		}
+
+		spec := ranges[start]
+		style := spec.Key("S").Name()
+		prefix := spec.Key("P").Text()
+		first := 1
+		if st := spec.Key("St"); !st.IsNull() {
+			first = int(st.Int64())
+		}
+
+		for index := max(start, 0); index < end; index++ {
+			labels[index] = prefix + formatPageNumber(first+index-start, style)
+		}
+	}
+
+	return labels
+}
+
+// collectPageLabelRanges は数値ツリーのNums配列（開始ページ, ラベル定義 の組）を再帰的に集める
+// 循環したツリーでも止まるように、たどった辞書を記録し、深さをpdfMaxTreeDepthまでとする
+func collectPageLabelRanges(node pdf.Value, ranges map[int]pdf.Value, visited *pdfTreeWalk, depth int) {
+	if node.IsNull() || depth > pdfMaxTreeDepth || !visited.visit(node, 0) {
+		return
+	}
+	nums := node.Key("Nums")
+	for i := 0; i+1 < nums.Len(); i += 2 {
+		ranges[int(nums.Index(i).Int64())] = nums.Index(i + 1)
+	}
+	kids := node.Key("Kids")
+	for i := 0; i < kids.Len(); i++ {
+		collectPageLabelRanges(kids.Index(i), ranges, visited, depth+1)
+	}
+}
+
+// pdfMaxTreeDepth はページラベルの数値ツリーなどをたどる深さの上限
+const pdfMaxTreeDepth = 64
+
+// pdfMaxTreeNodes はツリーをたどるノード数の上限（子を共有・循環する不正なツリーでも止まるようにする）
+const pdfMaxTreeNodes = 1 << 20
+
+// pdfTreeWalk はオブジェクトのツリーをたどる際に、たどった辞書・配列とノード数を記録する
+// pdf.Valueは間接オブジェクトの参照を公開していないため、辞書・配列は内容の文字列（子の間接オブジェクトは参照のまま出力される）で識別する
+type pdfTreeWalk struct {
+	visited map[string]bool
+	nodes   int
+}
+
+func newPDFTreeWalk() *pdfTreeWalk {
+	return &pdfTreeWalk{visited: make(map[string]bool)}
+}
+
+// visit はnodeをたどる場合に true を返す
+// 同じ内容の辞書・配列はscope（構造ツリーでは継承されたページ番号）ごとに1度だけたどり、たどるノードの数はpdfMaxTreeNodesまでとする
+func (w *pdfTreeWalk) visit(node pdf.Value, scope int) bool {
+	if w.nodes >= pdfMaxTreeNodes {
+		return false
+	}
+	w.nodes++
+	if kind := node.Kind(); kind != pdf.Dict && kind != pdf.Array {
+		return true
+	}
+	key := strconv.Itoa(scope) + " " + node.String()
+	if w.visited[key] {
+		return false
+	}
+	w.visited[key] = true
+	return true
+}
+
+// formatPageNumber はページラベルの番号をスタイルに従って整形する
+// D: 10進数、R/r: ローマ数字（大文字/小文字）、A/a: アルファベット（Z の次は AA）、スタイルなし: 番号なし
+func formatPageNumber(n int, style string) string {
+	switch style {
+	case "D":
+		return strconv.Itoa(n)
+	case "R":
+		return toRoman(n)
+	case "r":
+		return strings.ToLower(toRoman(n))
+	case "A":
+		return toAlphabetic(n)
+	case "a":
+		return strings.ToLower(toAlphabetic(n))
+	}
+	return ""
+}
+
+// maxRomanNumber はローマ数字で表す数値の上限（これを超える場合は10進数にする）
+const maxRomanNumber = 3999
+
+// maxAlphabeticNumber はアルファベットで表す数値の上限（同じ文字を100個まで並べる。これを超える場合は10進数にする）
+const maxAlphabeticNumber = 26 * 100
+
+// toRoman は数値を大文字のローマ数字に変換する
+// 0以下とmaxRomanNumberを超える数値は10進数で返す
+func toRoman(n int) string {
+	if n <= 0 || n > maxRomanNumber {
+		return strconv.Itoa(n)
+	}
+	values := []int{1000, 900, 500, 400, 100, 90, 50, 40, 10, 9, 5, 4, 1}
+	symbols := []string{"M", "CM", "D", "CD", "C", "XC", "L", "XL", "X", "IX", "V", "IV", "I"}
+
+	var sb strings.Builder
+	for i, value := range values {
+		for n >= value {
+			sb.WriteString(symbols[i])
+			n -= value
+		}
+	}
+	return sb.String()
+}
+
+// toAlphabetic は数値をPDFのページラベル形式のアルファベット（A..Z, AA..ZZ, AAA..）に変換する
+// 0以下とmaxAlphabeticNumberを超える数値は10進数で返す
+func toAlphabetic(n int) string {
+	if n <= 0 || n > maxAlphabeticNumber {
+		return strconv.Itoa(n)
+	}
+	letter := string(rune('A' + (n-1)%26))
+	return strings.Repeat(letter, (n-1)/26+1)
+}
+
+// recoverPDFPanic はPDFライブラリが不正なファイルに対して起こすpanicをエラーに変換する
+func recoverPDFPanic(err *error) {
+	if r := recover(); r != nil {
+		*err = fmt.Errorf("error reading PDF: %v", r)
+	}
+}
+
+// PageCount はPDFのページ数を返す
+func (p *PDFParser) PageCount(reader io.ReaderAt, size int64) (int, error) {
+	pdfReader, err := p.newReader(reader, size)
+	if err != nil {
+		return 0, fmt.Errorf("error reading PDF: %w", err)
+	}
+	return pdfReader.NumPage(), nil
 }
 
 // ParsePdfToString は後方互換性のための既存メソッド
@@ -82,11 +1470,38 @@ func ParsePdfToString(pdfFilePath string) string {
 	return result
 }
 
+// sanitize はPreserveWhitespaceに応じてページのテキストを正規化する
+func (p *PDFParser) sanitize(text string) string {
+	if p.PreserveWhitespace {
+		return sanitizeTextPreservingWhitespace(text, p.KeepInvisibleChars)
+	}
+	return sanitizeText(text, p.KeepInvisibleChars)
+}
+
+// sanitizeTextPreservingWhitespace は文字化け文字・不可視文字の除去と改行の統一のみを行い、空白はそのまま残す
+// 日本語文字間のスペースはテキスト片の結合で挿入されたものなので除去する
+func sanitizeTextPreservingWhitespace(text string, keepInvisibleChars bool) string {
+	text = strings.ReplaceAll(text, "�", "")
+	if !keepInvisibleChars {
+		text = stripInvisibleChars(text)
+	}
+	text = removeJapaneseSpaces(text)
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+	return strings.Trim(text, "\n")
+}
+
 // sanitizeText はテキストから余分な空白文字を除去し、正規化する
-func sanitizeText(text string) string {
+// keepInvisibleCharsがfalseの場合は不可視文字（invisibleCharsReplacerの対象）も除去する
+func sanitizeText(text string, keepInvisibleChars bool) string {
 	// 文字化け文字（置換文字）を除去
 	text = strings.ReplaceAll(text, "�", "")
 
+	// ソフトハイフン・ゼロ幅スペースなどの不可視文字を除去
+	if !keepInvisibleChars {
+		text = stripInvisibleChars(text)
+	}
+
 	// 全角英数字を半角に変換
 	text = convertFullWidthToHalfWidth(text)
 
@@ -107,6 +1522,21 @@ func sanitizeText(text string) string {
 	return strings.TrimSpace(text)
 }
 
+// invisibleCharsReplacer は検索の妨げになる不可視文字を除去する
+// ソフトハイフン（U+00AD）、ゼロ幅スペース（U+200B）、ワードジョイナー（U+2060）、BOM / ゼロ幅ノーブレークスペース（U+FEFF）が対象
+// ゼロ幅接合子（U+200D）と非接合子（U+200C）は絵文字や一部の文字体系の表示に必要なため残す
+var invisibleCharsReplacer = strings.NewReplacer(
+	"\u00AD", "",
+	"\u200B", "",
+	"\u2060", "",
+	"\uFEFF", "",
+)
+
+// stripInvisibleChars はテキストから不可視文字を除去する
+func stripInvisibleChars(text string) string {
+	return invisibleCharsReplacer.Replace(text)
+}
+
 // removeJapaneseSpaces は日本語文字間の不要なスペースを除去する
 func removeJapaneseSpaces(text string) string {
 	var result strings.Builder
@@ -0,0 +1,398 @@
+package documentParser
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// buildTestPDF はオブジェクトの本体（番号は1から順）から相互参照表付きのPDFを作る
+// 1番目のオブジェクトをカタログとする
+func buildTestPDF(objects ...string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.7\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f\r\n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n\r\n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return buf.Bytes()
+}
+
+// testPDFStream はコンテンツストリームのオブジェクトの本体を返す
+func testPDFStream(content string) string {
+	return fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content)
+}
+
+// buildTestTextPDF は1ページに1つのコンテンツストリームを持つPDFを作る
+// extraCatalogはカタログの辞書に追加するエントリ、extraObjectsはページの後に追加するオブジェクト（番号は 3+ページ数*2 から）
+func buildTestTextPDF(contents []string, extraCatalog string, extraObjects ...string) []byte {
+	fontNumber := 3 + len(contents)*2
+	var kids []string
+	objects := []string{"", ""}
+	for i, content := range contents {
+		pageNumber := 3 + i*2
+		kids = append(kids, fmt.Sprintf("%d 0 R", pageNumber))
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /Font << /F1 %d 0 R >> >> /Contents %d 0 R >>", fontNumber, pageNumber+1),
+			testPDFStream(content),
+		)
+	}
+	objects[0] = "<< /Type /Catalog /Pages 2 0 R " + extraCatalog + " >>"
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(contents))
+	objects = append(objects, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	objects = append(objects, extraObjects...)
+	return buildTestPDF(objects...)
+}
+
+func TestRebuildPDFXrefOnlyFoundObjects(t *testing.T) {
+	data := []byte("%PDF-1.4\n1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\n5000000 0 obj\n<< >>\nendobj\n")
+
+	rebuilt, err := rebuildPDFXref(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("rebuildPDFXref() error = %v", err)
+	}
+	if len(rebuilt) > len(data)+200 {
+		t.Errorf("rebuildPDFXref() output is %d bytes, want a few entries only", len(rebuilt))
+	}
+	for _, want := range []string{"xref\n0 1\n", "1 1\n", "5000000 1\n"} {
+		if !bytes.Contains(rebuilt, []byte(want)) {
+			t.Errorf("rebuildPDFXref() output does not contain subsection %q", want)
+		}
+	}
+}
+
+func TestRebuildPDFXrefIgnoresHugeObjectNumbers(t *testing.T) {
+	data := []byte("%PDF-1.4\n1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\n9999999999 0 obj\n<< >>\nendobj\n")
+
+	rebuilt, err := rebuildPDFXref(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("rebuildPDFXref() error = %v", err)
+	}
+	if !bytes.Contains(rebuilt, []byte("/Size 2 ")) {
+		t.Errorf("rebuildPDFXref() trailer does not use the largest valid object number: %q", rebuilt[len(data):])
+	}
+}
+
+func TestPDFParserAttemptRecovery(t *testing.T) {
+	data := buildTestTextPDF([]string{"BT /F1 12 Tf 72 720 Td (Recovered text) Tj ET"}, "")
+	// 相互参照表のオフセットをずらして壊す
+	broken := bytes.Replace(data, []byte("%PDF-1.7\n"), []byte("%PDF-1.7\n%padding to shift every object offset\n"), 1)
+
+	parser := &PDFParser{AttemptRecovery: true}
+	got, err := parser.ParseFromBytes(broken)
+	if err != nil {
+		t.Fatalf("ParseFromBytes() error = %v", err)
+	}
+	// Widthsのない標準フォントは1文字ずつのテキスト片になるため、空白を除いて比較する
+	if !strings.Contains(strings.ReplaceAll(got, " ", ""), "Recoveredtext") {
+		t.Errorf("ParseFromBytes() = %q, want recovered page text", got)
+	}
+}
+
+// buildTestLabeledPDF は "Page one" などのテキストを持つ3ページと、ページラベルの数値ツリー（オブジェクト10以降）を持つPDFを作る
+func buildTestLabeledPDF(labelObjects ...string) []byte {
+	contents := []string{
+		"BT /F1 12 Tf 72 720 Td (one) Tj ET",
+		"BT /F1 12 Tf 72 720 Td (two) Tj ET",
+		"BT /F1 12 Tf 72 720 Td (three) Tj ET",
+	}
+	return buildTestTextPDF(contents, "/PageLabels 10 0 R", labelObjects...)
+}
+
+func TestPDFParserPageLabelsCyclicTree(t *testing.T) {
+	data := buildTestLabeledPDF(
+		"<< /Kids [11 0 R] >>",
+		"<< /Nums [0 << /S /r >> 2 << /S /D /P (A-) >>] /Kids [10 0 R 11 0 R] >>",
+	)
+
+	parser := &PDFParser{}
+	labels, err := parser.PageLabels(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("PageLabels() error = %v", err)
+	}
+	want := []string{"i", "ii", "A-1"}
+	if strings.Join(labels, ",") != strings.Join(want, ",") {
+		t.Errorf("PageLabels() = %v, want %v", labels, want)
+	}
+}
+
+func TestPDFParserPageLabelsHugeStart(t *testing.T) {
+	tests := []struct {
+		name  string
+		style string
+		want  []string
+	}{
+		{"alphabetic", "/A", []string{"2147483000", "2147483001", "2147483002"}},
+		{"roman", "/R", []string{"2147483000", "2147483001", "2147483002"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := buildTestLabeledPDF("<< /Nums [0 << /S " + tt.style + " /St 2147483000 >>] >>")
+
+			parser := &PDFParser{}
+			labels, err := parser.PageLabels(bytes.NewReader(data), int64(len(data)))
+			if err != nil {
+				t.Fatalf("PageLabels() error = %v", err)
+			}
+			if strings.Join(labels, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("PageLabels() = %v, want %v", labels, tt.want)
+			}
+		})
+	}
+}
+
+func TestPDFParserPageLabelRange(t *testing.T) {
+	data := buildTestLabeledPDF("<< /Nums [0 << /S /r >> 2 << /S /D /P (A-) >>] >>")
+
+	parser := &PDFParser{FirstPageLabel: "ii", LastPageLabel: "A-1", ConcatenateRuns: true}
+	got, err := parser.ParseFromBytes(data)
+	if err != nil {
+		t.Fatalf("ParseFromBytes() error = %v", err)
+	}
+	if strings.Contains(got, "one") || !strings.Contains(got, "two") || !strings.Contains(got, "three") {
+		t.Errorf("ParseFromBytes() = %q, want pages ii to A-1 only", got)
+	}
+
+	factory := NewDocumentParserFactory()
+	got, err = factory.ParseFromReaderWithOptions(".pdf", bytes.NewReader(data), int64(len(data)), WithPageLabelRange("i", "i"))
+	if err != nil {
+		t.Fatalf("ParseFromReaderWithOptions() error = %v", err)
+	}
+	if !strings.Contains(strings.ReplaceAll(got, " ", ""), "one") || strings.Contains(strings.ReplaceAll(got, " ", ""), "two") {
+		t.Errorf("ParseFromReaderWithOptions() = %q, want page i only", got)
+	}
+
+	parser = &PDFParser{FirstPageLabel: "x"}
+	if _, err := parser.ParseFromBytes(data); !errors.Is(err, ErrPageLabelNotFound) {
+		t.Errorf("ParseFromBytes() error = %v, want ErrPageLabelNotFound", err)
+	}
+}
+
+// buildTestTaggedPDF は同じコンテンツストリームを持つ2ページと、structElemsを構造ツリーのルートの子（オブジェクト8以降）とするタグ付きPDFを作る
+func buildTestTaggedPDF(rootKids string, structElems ...string) []byte {
+	page := "<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d 792] /Resources << /Font << /F1 7 0 R >> >> /Contents 5 0 R >>"
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R /StructTreeRoot 6 0 R /MarkInfo << /Marked true >> >>",
+		"<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 2 >>",
+		fmt.Sprintf(page, 612),
+		fmt.Sprintf(page, 595),
+		testPDFStream("BT /F1 12 Tf 72 720 Td (x) Tj ET"),
+		"<< /Type /StructTreeRoot /K [" + rootKids + "] >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+	}
+	return buildTestPDF(append(objects, structElems...)...)
+}
+
+func TestPDFParserStructureTreeSharedContents(t *testing.T) {
+	data := buildTestTaggedPDF("8 0 R 9 0 R",
+		"<< /Type /StructElem /S /P /Pg 3 0 R /ActualText (Alpha) >>",
+		"<< /Type /StructElem /S /P /Pg 4 0 R /ActualText (Beta) >>",
+	)
+
+	parser := &PDFParser{UseStructureTree: true}
+	pages, err := parser.parsePages(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("parsePages() error = %v", err)
+	}
+	if len(pages) != 2 || pages[0].Content != "Alpha" || pages[1].Content != "Beta" {
+		t.Errorf("parsePages() = %+v, want Alpha on page 1 and Beta on page 2", pages)
+	}
+}
+
+func TestPDFParserStructureTreeSharedChildren(t *testing.T) {
+	// 各階層が次の階層の要素を2回参照するため、共有された子を毎回たどると2^depth回の訪問になる
+	const depth = 40
+	var elems []string
+	for i := 0; i < depth; i++ {
+		next := 8 + i + 1
+		elems = append(elems, fmt.Sprintf("<< /Type /StructElem /S /Div /K [%d 0 R %d 0 R] >>", next, next))
+	}
+	elems = append(elems, "<< /Type /StructElem /S /P /Pg 3 0 R /ActualText (Leaf) >>")
+	data := buildTestTaggedPDF("8 0 R", elems...)
+
+	parser := &PDFParser{UseStructureTree: true}
+	pages, err := parser.parsePages(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("parsePages() error = %v", err)
+	}
+	if len(pages) == 0 || pages[0].Content != "Leaf" {
+		t.Errorf("parsePages() = %+v, want %q on page 1", pages, "Leaf")
+	}
+}
+
+func TestPDFParserStructureTreeCyclicArray(t *testing.T) {
+	// 自身を2回参照する配列は、重複を除かないと2^depth回の訪問になる
+	data := buildTestTaggedPDF("8 0 R", "[8 0 R 8 0 R 9 0 R]", "<< /Type /StructElem /S /P /Pg 3 0 R /ActualText (Leaf) >>")
+
+	parser := &PDFParser{UseStructureTree: true}
+	pages, err := parser.parsePages(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("parsePages() error = %v", err)
+	}
+	if len(pages) == 0 || pages[0].Content != "Leaf" {
+		t.Errorf("parsePages() = %+v, want %q on page 1", pages, "Leaf")
+	}
+}
+
+func TestPDFParserParseWithQualityCountsReplacementChars(t *testing.T) {
+	clean := buildTestTextPDF([]string{"BT /F1 12 Tf 72 720 Td (clean) Tj ET"}, "")
+	// WinAnsiEncodingで未定義の0x81は置換文字（U+FFFD）になる
+	garbled := buildTestTextPDF([]string{`BT /F1 12 Tf 72 720 Td (\201\201\201\201ok) Tj ET`}, "")
+
+	parser := &PDFParser{}
+	_, cleanScore, err := parser.ParseWithQuality(bytes.NewReader(clean), int64(len(clean)))
+	if err != nil {
+		t.Fatalf("ParseWithQuality() error = %v", err)
+	}
+	text, garbledScore, err := parser.ParseWithQuality(bytes.NewReader(garbled), int64(len(garbled)))
+	if err != nil {
+		t.Fatalf("ParseWithQuality() error = %v", err)
+	}
+
+	if cleanScore != 1 {
+		t.Errorf("ParseWithQuality() score = %v for clean text, want 1", cleanScore)
+	}
+	if strings.ContainsRune(text, '�') {
+		t.Errorf("ParseWithQuality() text = %q, want replacement characters removed", text)
+	}
+	if garbledScore > 0.5 {
+		t.Errorf("ParseWithQuality() score = %v for mostly replacement characters, want <= 0.5", garbledScore)
+	}
+}
+
+func TestPDFParserConcatenateRuns(t *testing.T) {
+	// 幅の情報がないHelveticaでは1文字ごとにテキスト片が分かれ、単語間の空白も独立したテキスト片になる
+	data := buildTestTextPDF([]string{"BT /F1 12 Tf 72 720 Td (Hello world) Tj 0 -20 Td (next line) Tj ET"}, "")
+
+	tests := []struct {
+		name   string
+		parser *PDFParser
+		want   string
+	}{
+		{"default", &PDFParser{}, "H e l l o w o r l d n e x t l i n e"},
+		{"concatenate runs", &PDFParser{ConcatenateRuns: true}, "Hello world next line"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pages, err := tt.parser.parsePages(bytes.NewReader(data), int64(len(data)))
+			if err != nil {
+				t.Fatalf("parsePages() error = %v", err)
+			}
+			if len(pages) != 1 || pages[0].Content != tt.want {
+				t.Errorf("parsePages() = %+v, want one page with %q", pages, tt.want)
+			}
+		})
+	}
+}
+
+func TestPDFParserConcatenateRunsStripsControlChars(t *testing.T) {
+	data := buildTestTextPDF([]string{`BT /F1 12 Tf 72 720 Td (tab\tnew\nline\013vt\001ctl) Tj ET`}, "")
+
+	parser := &PDFParser{ConcatenateRuns: true}
+	pages, err := parser.parsePages(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("parsePages() error = %v", err)
+	}
+	want := "tab new line vtctl"
+	if len(pages) != 1 || pages[0].Content != want {
+		t.Errorf("parsePages() = %+v, want one page with %q", pages, want)
+	}
+}
+
+func TestSanitizeTextInvisibleChars(t *testing.T) {
+	text := "soft\u00ADhyphen zero\u200Bwidth \uFEFFbom"
+
+	tests := []struct {
+		name               string
+		keepInvisibleChars bool
+		want               string
+		wantPreserving     string
+	}{
+		{"strip", false, "softhyphen zerowidth bom", "softhyphen zerowidth bom"},
+		{"keep", true, text, text},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sanitizeText(text, tt.keepInvisibleChars); got != tt.want {
+				t.Errorf("sanitizeText() = %q, want %q", got, tt.want)
+			}
+			if got := sanitizeTextPreservingWhitespace(text, tt.keepInvisibleChars); got != tt.wantPreserving {
+				t.Errorf("sanitizeTextPreservingWhitespace() = %q, want %q", got, tt.wantPreserving)
+			}
+		})
+	}
+}
+
+func TestPDFParserInvisibleChars(t *testing.T) {
+	// WinAnsiEncodingの0xADはソフトハイフン（U+00AD）
+	data := buildTestTextPDF([]string{`BT /F1 12 Tf 72 720 Td (soft\255hyphen) Tj ET`}, "")
+
+	tests := []struct {
+		name   string
+		parser *PDFParser
+		want   string
+	}{
+		{"default", &PDFParser{ConcatenateRuns: true}, "softhyphen"},
+		{"keep", &PDFParser{ConcatenateRuns: true, KeepInvisibleChars: true}, "soft\u00ADhyphen"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pages, err := tt.parser.parsePages(bytes.NewReader(data), int64(len(data)))
+			if err != nil {
+				t.Fatalf("parsePages() error = %v", err)
+			}
+			if len(pages) != 1 || pages[0].Content != tt.want {
+				t.Errorf("parsePages() = %+v, want one page with %q", pages, tt.want)
+			}
+		})
+	}
+}
+
+func TestPDFParserThumbnailLimits(t *testing.T) {
+	// 2x1のDeviceGrayのサムネイル
+	buildThumbPDF := func(thumb string) []byte {
+		return buildTestPDF(
+			"<< /Type /Catalog /Pages 2 0 R >>",
+			"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+			"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Thumb 4 0 R >>",
+			thumb,
+		)
+	}
+
+	parser := &PDFParser{}
+	data := buildThumbPDF("<< /Width 2 /Height 1 /BitsPerComponent 8 /ColorSpace /DeviceGray /Length 2 >>\nstream\nAB\nendstream")
+	if _, contentType, err := parser.ParseThumbnail(bytes.NewReader(data), int64(len(data))); err != nil || contentType != "image/png" {
+		t.Fatalf("ParseThumbnail() = %q, %v, want image/png", contentType, err)
+	}
+
+	tests := []struct {
+		name  string
+		thumb string
+	}{
+		{"huge size", "<< /Width 100000 /Height 100000 /BitsPerComponent 8 /ColorSpace /DeviceRGB /Length 2 >>\nstream\nAB\nendstream"},
+		{"over limit", "<< /Width 1025 /Height 1 /BitsPerComponent 8 /ColorSpace /DeviceGray /Length 2 >>\nstream\nAB\nendstream"},
+		{"short stream", "<< /Width 1024 /Height 1024 /BitsPerComponent 8 /ColorSpace /DeviceRGB /Length 2 >>\nstream\nAB\nendstream"},
+		{"huge JPEG", "<< /Width 100000 /Height 100000 /Filter /DCTDecode /Length 2 >>\nstream\nAB\nendstream"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := buildThumbPDF(tt.thumb)
+			if _, _, err := parser.ParseThumbnail(bytes.NewReader(data), int64(len(data))); err == nil || errors.Is(err, ErrNoThumbnail) {
+				t.Errorf("ParseThumbnail() error = %v, want invalid thumbnail error", err)
+			}
+		})
+	}
+}
@@ -3,20 +3,53 @@ package documentParser
 import (
 	"archive/zip"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"sort"
+	"strconv"
 	"strings"
 )
 
 // PPTXParser はPowerPointファイルのパーサー
 type PPTXParser struct {
 	BaseParser
+
+	// IncludeCharts が true の場合、スライドに埋め込まれたグラフのデータを表形式で追加する
+	IncludeCharts bool
+
+	// IncludeLayoutText が true の場合、スライドが参照するレイアウト（ppt/slideLayouts/）のプレースホルダーの
+	// テキストを "### Layout" の見出し付きで追加する（通常は定型文のためデフォルトは無効）
+	IncludeLayoutText bool
+
+	// MarkdownHyperlinks が true の場合、ハイパーリンク（a:hlinkClick）の付いたテキストを
+	// スライドのリレーションシップで解決したURLを使ってMarkdown形式（"[テキスト](URL)"）で出力する
+	MarkdownHyperlinks bool
+
+	// PlaceholderFilter が空でない場合、スライドの図形のうちプレースホルダーの種類（<p:ph type="...">）が
+	// この一覧に含まれるものだけを抽出する（"title"・"ctrTitle" でタイトルのみのアウトラインにするなど）
+	// type属性のないプレースホルダーは "obj" として扱い、プレースホルダーでない図形とSmartArtは抽出しない
+	PlaceholderFilter []string
+
+	// PrependMetadata が true の場合、出力の先頭にコアプロパティ（docProps/core.xml）のタイトル・作成者・作成日時を
+	// "Title: 値" の行として追加する（空の項目は出力しない）
+	PrependMetadata bool
 }
 
 // SupportedExtensions はサポートする拡張子を返す
 func (p *PPTXParser) SupportedExtensions() []string {
-	return []string{".pptx", ".ppt"}
+	return []string{
+		".pptx", ".ppt",
+		".pptm", // マクロ有効プレゼンテーション
+		".potx", // テンプレート
+		".potm", // マクロ有効テンプレート
+	}
+}
+
+// Capabilities はパーサーが抽出できる構造の種類を返す
+func (p *PPTXParser) Capabilities() ParserCapabilities {
+	return ParserCapabilities{Pages: true, Thumbnail: true}
 }
 
 // ParseFromFile はファイルパスからPPTXをパース
@@ -31,61 +64,143 @@ func (p *PPTXParser) ParseFromBytes(data []byte) (string, error) {
 
 // ParseFromReader はio.ReaderAtからPPTXをパース
 func (p *PPTXParser) ParseFromReader(reader io.ReaderAt, size int64) (string, error) {
-	r, err := zip.NewReader(reader, size)
+	slides, err := p.extractSlides(reader, size)
 	if err != nil {
-		return "", fmt.Errorf("error reading PowerPoint: %w", err)
+		return "", err
 	}
 
 	var allText strings.Builder
-	slideNum := 1
+	if p.PrependMetadata {
+		allText.WriteString(ooxmlMetadataHeader(reader, size))
+	}
+	for i, slide := range slides {
+		// スライド番号とテキストを追加
+		p.writePageSeparator(&allText, i)
+		allText.WriteString(p.boundaryMarker(heading("## ", p.labels().SlideHeader, strconv.Itoa(slide.Number), "\n"), "SLIDE", strconv.Itoa(slide.Number)))
+		allText.WriteString(slide.Content)
+		allText.WriteString("\n\n")
+	}
+
+	return truncateOutput(allText.String(), p.MaxOutputBytes), nil
+}
+
+// ErrNoSlides はスライド（ppt/slides/slideN.xml）を1枚も含まないプレゼンテーションの場合に返すエラー
+// レイアウトやマスターのみのファイルが該当する
+var ErrNoSlides = errors.New("no slides found")
+
+// extractSlides はスライドごとのテキストを抽出する
+// テキストのないスライドはLabels.EmptySlide（デフォルトは "(No text found)"）とする
+func (p *PPTXParser) extractSlides(reader io.ReaderAt, size int64) ([]Page, error) {
+	r, err := zip.NewReader(reader, size)
+	if err != nil {
+		return nil, fmt.Errorf("error reading PowerPoint: %w", err)
+	}
+
+	var slides []Page
+	files := zipFileMap(r)
+	total := 0
+	// slideParts はスライドのパーツの数、lastErr は最後に読み取れなかったスライドのエラー
+	slideParts := 0
+	var lastErr error
 
 	// 各ファイルをチェック
 	for _, f := range r.File {
 		// スライドファイルのみを処理
-		if strings.HasPrefix(f.Name, "ppt/slides/slide") &&
-			strings.HasSuffix(f.Name, ".xml") &&
-			!strings.Contains(f.Name, "Layout") &&
-			!strings.Contains(f.Name, "Master") {
-
-			rc, err := f.Open()
-			if err != nil {
-				log.Printf("Error opening file %s: %s", f.Name, err)
-				continue
-			}
+		if !isSlidePart(f.Name) {
+			continue
+		}
+		slideParts++
 
-			// XMLをパース
-			content, err := io.ReadAll(rc)
-			if err != nil {
-				log.Printf("Error reading file %s: %s", f.Name, err)
-				rc.Close()
-				continue
-			}
-			rc.Close()
+		// XMLをパース
+		content, err := readZipFile(f)
+		if err != nil {
+			log.Printf("Error reading file %s: %s", f.Name, err)
+			lastErr = fmt.Errorf("error reading %s: %w", f.Name, err)
+			continue
+		}
 
-			var slide Slide
-			err = xml.Unmarshal(content, &slide)
-			if err != nil {
-				log.Printf("Error parsing XML for %s: %s", f.Name, err)
-				continue
+		var slide Slide
+		err = xml.Unmarshal(content, &slide)
+		if err != nil {
+			log.Printf("Error parsing XML for %s: %s", f.Name, err)
+			lastErr = fmt.Errorf("error parsing XML for %s: %w", f.Name, err)
+			continue
+		}
+
+		// テキストを抽出
+		var links map[string]string
+		if p.MarkdownHyperlinks {
+			links = slideHyperlinks(files, f.Name)
+		}
+		extractedText := extractTextFromSlide(slide, links, p.PlaceholderFilter)
+		if smartArt := extractSmartArtFromSlide(files, f.Name); smartArt != "" && len(p.PlaceholderFilter) == 0 {
+			if extractedText != "" {
+				extractedText += "\n"
 			}
+			extractedText += smartArt
+		}
+		if len(extractedText) == 0 {
+			extractedText = p.labels().EmptySlide
+		}
+		if p.IncludeLayoutText {
+			extractedText += extractLayoutFromSlide(files, f.Name)
+		}
+		if p.IncludeCharts {
+			extractedText += extractChartsFromSlide(files, f.Name)
+		}
 
-			// テキストを抽出
-			extractedText := extractTextFromSlide(slide)
+		number := len(slides) + 1
+		slides = append(slides, Page{
+			Number:  number,
+			Name:    fmt.Sprintf("Slide %d", number),
+			Content: extractedText,
+		})
 
-			// スライド番号とテキストを追加
-			allText.WriteString(fmt.Sprintf("## Slide %d\n", slideNum))
-			if len(extractedText) > 0 {
-				allText.WriteString(extractedText)
-			} else {
-				allText.WriteString("(No text found)")
-			}
-			allText.WriteString("\n\n")
+		// MaxOutputBytesを超えた場合は以降のスライドを読まない
+		total += len(extractedText)
+		if p.outputLimitReached(total) {
+			break
+		}
+	}
+
+	if slideParts == 0 {
+		return nil, ErrNoSlides
+	}
+	// スライドのパーツがあるのに1枚も読み取れなかった場合は、空のプレゼンテーションと区別してパースのエラーを返す
+	if len(slides) == 0 {
+		return nil, lastErr
+	}
+
+	return slides, nil
+}
 
-			slideNum++
+// parsePages はスライドをページとして返す
+func (p *PPTXParser) parsePages(reader io.ReaderAt, size int64) ([]Page, error) {
+	return p.extractSlides(reader, size)
+}
+
+// PageCount はスライド数を返す
+func (p *PPTXParser) PageCount(reader io.ReaderAt, size int64) (int, error) {
+	r, err := zip.NewReader(reader, size)
+	if err != nil {
+		return 0, fmt.Errorf("error reading PowerPoint: %w", err)
+	}
+
+	count := 0
+	for _, f := range r.File {
+		if isSlidePart(f.Name) {
+			count++
 		}
 	}
+	return count, nil
+}
 
-	return allText.String(), nil
+// isSlidePart はzip内のファイルがスライドのXMLかどうかを判定する
+func isSlidePart(name string) bool {
+	return strings.HasPrefix(name, "ppt/slides/slide") &&
+		strings.HasSuffix(name, ".xml") &&
+		!strings.Contains(name, "Layout") &&
+		!strings.Contains(name, "Master")
 }
 
 // const (
@@ -95,7 +210,16 @@ func (p *PPTXParser) ParseFromReader(reader io.ReaderAt, size int64) (string, er
 
 // PowerPointのXML構造を表現する構造体
 type TextRun struct {
-	Text string `xml:"t"`
+	Text       string            `xml:"t"`
+	Properties TextRunProperties `xml:"rPr"`
+}
+
+// TextRunProperties はランのプロパティ
+// hlinkClickのr:idは名前空間を指定せずに読むことで、Strict OOXMLの名前空間（http://purl.oclc.org/ooxml/）にも対応する
+type TextRunProperties struct {
+	HyperlinkClick struct {
+		ID string `xml:"id,attr"`
+	} `xml:"hlinkClick"`
 }
 
 type Paragraph struct {
@@ -107,7 +231,27 @@ type TextBody struct {
 }
 
 type Shape struct {
-	TextBody TextBody `xml:"txBody"`
+	// Placeholder はプレースホルダーの図形の場合のみ設定される
+	Placeholder *ShapePlaceholder `xml:"nvSpPr>nvPr>ph"`
+	TextBody    TextBody          `xml:"txBody"`
+}
+
+// ShapePlaceholder は図形のプレースホルダーの情報（<p:ph>）
+type ShapePlaceholder struct {
+	// Type はプレースホルダーの種類（"title"、"ctrTitle"、"subTitle"、"body" など）
+	Type string `xml:"type,attr"`
+}
+
+// PlaceholderType は図形のプレースホルダーの種類を返す
+// type属性が省略されたプレースホルダーは "obj"、プレースホルダーでない図形は空文字列になる
+func (s Shape) PlaceholderType() string {
+	if s.Placeholder == nil {
+		return ""
+	}
+	if s.Placeholder.Type == "" {
+		return "obj"
+	}
+	return s.Placeholder.Type
 }
 
 type SlideData struct {
@@ -128,16 +272,36 @@ func ParsePptxToString(pptxFilePath string) string {
 	return result
 }
 
-func extractTextFromSlide(slide Slide) string {
+// extractTextFromSlide はスライドのテキストを段落ごとに改行で結合して返す
+// linksが指定されている場合、ハイパーリンクの付いたランを "[テキスト](URL)" として出力する
+// （同じリンクが続くランは1つのリンクにまとめる）
+// placeholdersが空でない場合、プレースホルダーの種類がこれに含まれる図形のみを対象とする
+func extractTextFromSlide(slide Slide, links map[string]string, placeholders []string) string {
 	var result []string
 
 	for _, shape := range slide.SlideData.Shapes {
+		if len(placeholders) > 0 && !hasPlaceholderType(placeholders, shape.PlaceholderType()) {
+			continue
+		}
 		for _, paragraph := range shape.TextBody.Paragraphs {
 			var paragraphText strings.Builder
-			for _, run := range paragraph.Runs {
-				if run.Text != "" {
+			for i := 0; i < len(paragraph.Runs); i++ {
+				run := paragraph.Runs[i]
+				url := links[run.Properties.HyperlinkClick.ID]
+				if url == "" {
 					paragraphText.WriteString(run.Text)
+					continue
+				}
+
+				text := run.Text
+				for i+1 < len(paragraph.Runs) && paragraph.Runs[i+1].Properties.HyperlinkClick.ID == run.Properties.HyperlinkClick.ID {
+					i++
+					text += paragraph.Runs[i].Text
+				}
+				if text == "" {
+					continue
 				}
+				paragraphText.WriteString(fmt.Sprintf("[%s](%s)", text, url))
 			}
 			if paragraphText.Len() > 0 {
 				result = append(result, paragraphText.String())
@@ -147,3 +311,280 @@ func extractTextFromSlide(slide Slide) string {
 
 	return strings.Join(result, "\n")
 }
+
+// hasPlaceholderType はプレースホルダーの種類がtypesに含まれるかどうかを大文字と小文字を区別せずに返す
+func hasPlaceholderType(types []string, placeholderType string) bool {
+	if placeholderType == "" {
+		return false
+	}
+	for _, t := range types {
+		if strings.EqualFold(t, placeholderType) {
+			return true
+		}
+	}
+	return false
+}
+
+// slideHyperlinks はスライドのリレーションシップのうち、ハイパーリンクのIDとURLの対応を返す
+func slideHyperlinks(files map[string]*zip.File, slideName string) map[string]string {
+	rels, err := readRelationships(files, slideName)
+	if err != nil {
+		log.Printf("Error reading relationships for %s: %s", slideName, err)
+		return nil
+	}
+
+	links := make(map[string]string)
+	for _, rel := range rels {
+		if strings.HasSuffix(rel.Type, "/hyperlink") && rel.Target != "" {
+			links[rel.ID] = rel.Target
+		}
+	}
+	return links
+}
+
+// PowerPointのグラフXML構造を表現する構造体
+type chartSpace struct {
+	Chart struct {
+		Title    chartRichText `xml:"title"`
+		PlotArea struct {
+			Groups []chartGroup `xml:",any"`
+		} `xml:"plotArea"`
+	} `xml:"chart"`
+}
+
+type chartRichText struct {
+	Texts []string `xml:"tx>rich>p>r>t"`
+}
+
+type chartGroup struct {
+	Series []chartSeries `xml:"ser"`
+}
+
+type chartSeries struct {
+	Name chartData `xml:"tx"`
+	Cat  chartData `xml:"cat"`
+	Val  chartData `xml:"val"`
+}
+
+type chartData struct {
+	StrCache chartCache `xml:"strRef>strCache"`
+	NumCache chartCache `xml:"numRef>numCache"`
+	StrLit   chartCache `xml:"strLit"`
+	NumLit   chartCache `xml:"numLit"`
+	V        string     `xml:"v"`
+}
+
+type chartCache struct {
+	Points []chartPoint `xml:"pt"`
+}
+
+type chartPoint struct {
+	Idx int    `xml:"idx,attr"`
+	V   string `xml:"v"`
+}
+
+// points はキャッシュされたデータ点を返す（文字列キャッシュを優先）
+func (d chartData) points() []chartPoint {
+	for _, c := range []chartCache{d.StrCache, d.NumCache, d.StrLit, d.NumLit} {
+		if len(c.Points) > 0 {
+			return c.Points
+		}
+	}
+	return nil
+}
+
+// name は系列名を返す
+func (d chartData) name() string {
+	if pts := d.points(); len(pts) > 0 {
+		return pts[0].V
+	}
+	return d.V
+}
+
+// extractLayoutFromSlide はスライドが参照するレイアウトのテキストを返す
+func extractLayoutFromSlide(files map[string]*zip.File, slideName string) string {
+	var sb strings.Builder
+	for _, layoutName := range relatedParts(files, slideName, "/slideLayout") {
+		f, ok := files[layoutName]
+		if !ok {
+			continue
+		}
+
+		content, err := readZipFile(f)
+		if err != nil {
+			log.Printf("Error reading layout %s: %s", layoutName, err)
+			continue
+		}
+
+		var layout Slide
+		if err := xml.Unmarshal(content, &layout); err != nil {
+			log.Printf("Error parsing XML for %s: %s", layoutName, err)
+			continue
+		}
+
+		if text := extractTextFromSlide(layout, nil, nil); text != "" {
+			sb.WriteString("\n\n### Layout\n")
+			sb.WriteString(text)
+		}
+	}
+	return sb.String()
+}
+
+// diagramDataModel はSmartArtのデータモデル（ppt/diagrams/dataN.xml）
+type diagramDataModel struct {
+	Points []diagramPoint `xml:"ptLst>pt"`
+}
+
+// diagramPoint はSmartArtの要素（ノード、接続、表示用の要素）
+type diagramPoint struct {
+	Type     string   `xml:"type,attr"`
+	TextBody TextBody `xml:"t"`
+}
+
+// extractSmartArtFromSlide はスライドが参照するSmartArtのデータモデルから、ノードのテキストを段落ごとに改行で結合して返す
+// スライドのXMLにはSmartArtのテキストがなく、グラフィックフレームからの参照のみのため、リレーションシップをたどって読む
+// 接続（parTrans / sibTrans）や表示用の要素（pres）はテキストを持たないため除く
+func extractSmartArtFromSlide(files map[string]*zip.File, slideName string) string {
+	var result []string
+	for _, dataName := range relatedParts(files, slideName, "/diagramData") {
+		f, ok := files[dataName]
+		if !ok {
+			continue
+		}
+
+		content, err := readZipFile(f)
+		if err != nil {
+			log.Printf("Error reading diagram %s: %s", dataName, err)
+			continue
+		}
+
+		var model diagramDataModel
+		if err := xml.Unmarshal(content, &model); err != nil {
+			log.Printf("Error parsing XML for %s: %s", dataName, err)
+			continue
+		}
+
+		for _, point := range model.Points {
+			// type属性がない場合はノード（"node"）
+			if point.Type != "" && point.Type != "node" && point.Type != "asst" {
+				continue
+			}
+			for _, paragraph := range point.TextBody.Paragraphs {
+				var paragraphText strings.Builder
+				for _, run := range paragraph.Runs {
+					paragraphText.WriteString(run.Text)
+				}
+				if paragraphText.Len() > 0 {
+					result = append(result, paragraphText.String())
+				}
+			}
+		}
+	}
+	return strings.Join(result, "\n")
+}
+
+// extractChartsFromSlide はスライドが参照するグラフのキャッシュデータをタブ区切りの表として返す
+func extractChartsFromSlide(files map[string]*zip.File, slideName string) string {
+	rels, err := readRelationships(files, slideName)
+	if err != nil {
+		log.Printf("Error reading relationships for %s: %s", slideName, err)
+		return ""
+	}
+
+	var sb strings.Builder
+	chartNum := 1
+	for _, rel := range rels {
+		if !strings.HasSuffix(rel.Type, "/chart") {
+			continue
+		}
+
+		chartName := resolveTarget(slideName, rel.Target)
+		f, ok := files[chartName]
+		if !ok {
+			continue
+		}
+
+		content, err := readZipFile(f)
+		if err != nil {
+			log.Printf("Error reading chart %s: %s", chartName, err)
+			continue
+		}
+
+		var chart chartSpace
+		if err := xml.Unmarshal(content, &chart); err != nil {
+			log.Printf("Error parsing XML for %s: %s", chartName, err)
+			continue
+		}
+
+		table := renderChartTable(chart)
+		if table == "" {
+			// キャッシュデータのないグラフはスキップ
+			continue
+		}
+
+		sb.WriteString(fmt.Sprintf("\n\n### Chart %d", chartNum))
+		if title := strings.Join(chart.Chart.Title.Texts, ""); title != "" {
+			sb.WriteString(": " + title)
+		}
+		sb.WriteString("\n")
+		sb.WriteString(table)
+		chartNum++
+	}
+
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// renderChartTable はグラフの系列をカテゴリごとの行に変換する
+func renderChartTable(chart chartSpace) string {
+	var series []chartSeries
+	for _, group := range chart.Chart.PlotArea.Groups {
+		for _, ser := range group.Series {
+			if len(ser.Val.points()) > 0 {
+				series = append(series, ser)
+			}
+		}
+	}
+	if len(series) == 0 {
+		return ""
+	}
+
+	// カテゴリは最初の系列のものを使用
+	categories := make(map[int]string)
+	indexSet := make(map[int]bool)
+	for _, pt := range series[0].Cat.points() {
+		categories[pt.Idx] = pt.V
+		indexSet[pt.Idx] = true
+	}
+
+	values := make([]map[int]string, len(series))
+	header := []string{""}
+	for i, ser := range series {
+		values[i] = make(map[int]string)
+		for _, pt := range ser.Val.points() {
+			values[i][pt.Idx] = pt.V
+			indexSet[pt.Idx] = true
+		}
+		name := ser.Name.name()
+		if name == "" {
+			name = fmt.Sprintf("Series %d", i+1)
+		}
+		header = append(header, name)
+	}
+
+	indexes := make([]int, 0, len(indexSet))
+	for idx := range indexSet {
+		indexes = append(indexes, idx)
+	}
+	sort.Ints(indexes)
+
+	var sb strings.Builder
+	sb.WriteString(strings.Join(header, "\t") + "\n")
+	for _, idx := range indexes {
+		row := []string{categories[idx]}
+		for i := range series {
+			row = append(row, values[i][idx])
+		}
+		sb.WriteString(strings.Join(row, "\t") + "\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,53 @@
+package documentParser
+
+import (
+	"errors"
+	"testing"
+)
+
+// testPPTXSlide はp:spTreeの中身からスライドのXMLを作る
+func testPPTXSlide(shapes string) string {
+	return `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<p:sld xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main" ` +
+		`xmlns:p="http://schemas.openxmlformats.org/presentationml/2006/main">` +
+		`<p:cSld><p:spTree>` + shapes + `</p:spTree></p:cSld></p:sld>`
+}
+
+func FuzzPPTX(f *testing.F) {
+	f.Add(testPPTXSlide(`<p:sp><p:txBody><a:p><a:r><a:t>title</a:t></a:r></a:p></p:txBody></p:sp>`))
+	f.Add(testPPTXSlide(`<p:grpSp><p:grpSp><p:sp><p:nvSpPr><p:nvPr><p:ph type="body"/></p:nvPr></p:nvSpPr>` +
+		`<p:txBody><a:p><a:pPr lvl="9"/><a:r><a:t>nested</a:t></a:r></a:p></p:txBody></p:sp></p:grpSp></p:grpSp>`))
+	f.Add(testPPTXSlide(`<p:graphicFrame><a:graphic><a:graphicData><a:tbl><a:tr><a:tc><a:txBody><a:p><a:r><a:t>cell</a:t></a:r></a:p>` +
+		`</a:txBody></a:tc></a:tr></a:tbl></a:graphicData></a:graphic></p:graphicFrame>`))
+	f.Add(`<p:sld xmlns:p="http://schemas.openxmlformats.org/presentationml/2006/main"><p:cSld><p:spTree>`)
+
+	f.Fuzz(func(t *testing.T, slide string) {
+		data := buildTestZip(t, map[string]string{"ppt/slides/slide1.xml": slide})
+		parser := &PPTXParser{}
+		// エラーは許容し、パニックしないことのみを確認する
+		parser.ParseFromBytes(data)
+	})
+}
+
+func TestPPTXParserNoSlides(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		noSlides bool
+	}{
+		{"layouts only", map[string]string{"ppt/slideLayouts/slideLayout1.xml": testPPTXSlide("")}, true},
+		{"unparsable slides", map[string]string{"ppt/slides/slide1.xml": `<p:sld xmlns:p="http://schemas.openxmlformats.org/presentationml/2006/main"><p:cSld>`}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := &PPTXParser{}
+			_, err := parser.ParseFromBytes(buildTestZip(t, tt.files))
+			if err == nil {
+				t.Fatal("ParseFromBytes() error = nil, want error")
+			}
+			if errors.Is(err, ErrNoSlides) != tt.noSlides {
+				t.Errorf("ParseFromBytes() error = %v, want errors.Is(err, ErrNoSlides) = %v", err, tt.noSlides)
+			}
+		})
+	}
+}
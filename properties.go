@@ -0,0 +1,214 @@
+package documentParser
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// PropertiesParser はJavaプロパティファイル（.properties）と環境変数ファイル（.env）のパーサー
+// デフォルトではTextParserと同様に内容をそのまま返す
+type PropertiesParser struct {
+	TextParser
+
+	// Normalize が true の場合、キーと値を解析して "key: value" の行として出力する
+	// コメント（#、!）を除去し、行継続（末尾の \）と、DotEnvの場合は引用符で囲まれた複数行の値を1行にまとめる
+	Normalize bool
+
+	// DotEnv が true の場合、.env形式として解析する（SupportedExtensionsは .env のみになる）
+	// 値を囲む引用符、値の後ろの " #" からのインラインコメント、行頭の "export " は.env形式でのみ扱い、
+	// .properties形式では引用符や "#" も値の一部とする。ファクトリーは .env にDotEnvを有効にしたパーサーを登録する
+	DotEnv bool
+}
+
+// SupportedExtensions はサポートする拡張子を返す
+func (p *PropertiesParser) SupportedExtensions() []string {
+	if p.DotEnv {
+		return []string{".env"}
+	}
+	return []string{".properties"}
+}
+
+// ParseFromFile はファイルパスから設定ファイルをパース
+func (p *PropertiesParser) ParseFromFile(filePath string) (string, error) {
+	return parseFromFileCommon(p, filePath)
+}
+
+// ParseFromBytes はバイト配列から設定ファイルをパース
+func (p *PropertiesParser) ParseFromBytes(data []byte) (string, error) {
+	text, err := p.bytesText(data)
+	if err != nil {
+		return "", err
+	}
+	return truncateOutput(p.render(text), p.MaxOutputBytes), nil
+}
+
+// ParseFromReader はio.ReaderAtから設定ファイルをパース
+func (p *PropertiesParser) ParseFromReader(reader io.ReaderAt, size int64) (string, error) {
+	text, err := p.readerText(reader, size)
+	if err != nil {
+		return "", err
+	}
+	return truncateOutput(p.render(text), p.MaxOutputBytes), nil
+}
+
+func (p *PropertiesParser) render(text string) string {
+	if !p.Normalize {
+		return text
+	}
+
+	var sb strings.Builder
+	for _, entry := range parseProperties(text, p.DotEnv) {
+		// 値に含まれる改行は1行に収めるためエスケープする
+		value := strings.ReplaceAll(entry.value, "\n", `\n`)
+		sb.WriteString(fmt.Sprintf("%s: %s\n", entry.key, value))
+		if p.outputLimitReached(sb.Len()) {
+			break
+		}
+	}
+	return sb.String()
+}
+
+// propertyEntry はキーと値の組
+type propertyEntry struct {
+	key, value string
+}
+
+// parseProperties は.properties形式（dotEnvがtrueの場合は.env形式）のテキストをキーと値の組に分解する
+func parseProperties(text string, dotEnv bool) []propertyEntry {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+
+	var entries []propertyEntry
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimLeft(lines[i], " \t\f")
+		if line == "" || line[0] == '#' || line[0] == '!' {
+			continue
+		}
+
+		// .envの "export KEY=value"
+		if dotEnv {
+			line = strings.TrimPrefix(line, "export ")
+		}
+
+		key, rest := splitPropertyKey(line)
+		rest = strings.TrimLeft(rest, " \t\f")
+
+		var value string
+		if dotEnv && rest != "" && (rest[0] == '"' || rest[0] == '\'') {
+			// 引用符で囲まれた値は閉じ引用符まで複数行にわたる
+			quote := rest[0]
+			value = rest[1:]
+			for !hasClosingQuote(value, quote) && i+1 < len(lines) {
+				i++
+				value += "\n" + lines[i]
+			}
+			if end := closingQuoteIndex(value, quote); end >= 0 {
+				value = value[:end]
+			}
+			if quote == '"' {
+				value = unescapePropertyValue(value)
+			}
+		} else {
+			// 末尾が奇数個のバックスラッシュの場合は次の行に継続する
+			for isContinuedLine(rest) && i+1 < len(lines) {
+				i++
+				rest = rest[:len(rest)-1] + strings.TrimLeft(lines[i], " \t\f")
+			}
+			rest = strings.TrimSuffix(rest, `\`)
+			// .envの値の後ろのインラインコメント
+			if idx := strings.Index(rest, " #"); dotEnv && idx >= 0 {
+				rest = rest[:idx]
+			}
+			value = unescapePropertyValue(strings.TrimSpace(rest))
+		}
+
+		entries = append(entries, propertyEntry{key: unescapePropertyValue(key), value: value})
+	}
+	return entries
+}
+
+// splitPropertyKey は行をキーと残りに分割する
+// 区切りはエスケープされていない最初の "="、":" または空白
+func splitPropertyKey(line string) (string, string) {
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '=', ':':
+			return strings.TrimSpace(line[:i]), line[i+1:]
+		case ' ', '\t', '\f':
+			key := line[:i]
+			rest := strings.TrimLeft(line[i:], " \t\f")
+			if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+				rest = rest[1:]
+			}
+			return key, rest
+		}
+	}
+	return line, ""
+}
+
+// isContinuedLine は行末が継続を示すバックスラッシュ（奇数個）かどうかを判定する
+func isContinuedLine(line string) bool {
+	n := 0
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i-- {
+		n++
+	}
+	return n%2 == 1
+}
+
+// hasClosingQuote は値に閉じ引用符が含まれるかどうかを判定する
+func hasClosingQuote(value string, quote byte) bool {
+	return closingQuoteIndex(value, quote) >= 0
+}
+
+// closingQuoteIndex はエスケープされていない閉じ引用符の位置を返す
+func closingQuoteIndex(value string, quote byte) int {
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' && quote == '"' {
+			i++
+			continue
+		}
+		if value[i] == quote {
+			return i
+		}
+	}
+	return -1
+}
+
+// unescapePropertyValue は \n、\t、\uXXXX などのエスケープを展開する
+func unescapePropertyValue(value string) string {
+	if !strings.Contains(value, `\`) {
+		return value
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' || i+1 >= len(value) {
+			sb.WriteByte(value[i])
+			continue
+		}
+		i++
+		switch value[i] {
+		case 'n':
+			sb.WriteByte('\n')
+		case 't':
+			sb.WriteByte('\t')
+		case 'r':
+			sb.WriteByte('\r')
+		case 'u':
+			if i+4 < len(value) {
+				if r, err := strconv.ParseUint(value[i+1:i+5], 16, 32); err == nil {
+					sb.WriteRune(rune(r))
+					i += 4
+					continue
+				}
+			}
+			sb.WriteByte('u')
+		default:
+			sb.WriteByte(value[i])
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,51 @@
+package documentParser
+
+import "testing"
+
+func TestPropertiesParserNormalize(t *testing.T) {
+	tests := []struct {
+		name   string
+		dotEnv bool
+		data   string
+		want   string
+	}{
+		{
+			name: "properties keeps quotes and hashes",
+			data: "# comment\ncolor = #ff0000\ntitle=\"quoted\" # not a comment\npath='a b'\nlong=one \\\n    two\n",
+			want: "color: #ff0000\ntitle: \"quoted\" # not a comment\npath: 'a b'\nlong: one two\n",
+		},
+		{
+			name:   "env handles quotes and inline comments",
+			dotEnv: true,
+			data:   "# comment\nexport NAME=value # inline\nQUOTED=\"a # b\\tc\"\nSINGLE='x\\ty'\nMULTI=\"line1\nline2\"\n",
+			want:   "NAME: value\nQUOTED: a # b\tc\nSINGLE: x\\ty\nMULTI: line1\\nline2\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := &PropertiesParser{Normalize: true, DotEnv: tt.dotEnv}
+			got, err := parser.ParseFromBytes([]byte(tt.data))
+			if err != nil {
+				t.Fatalf("ParseFromBytes() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseFromBytes() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFactoryPropertiesParsers(t *testing.T) {
+	factory := NewDocumentParserFactory()
+	for ext, dotEnv := range map[string]bool{".properties": false, ".env": true} {
+		parser, err := factory.GetParser(ext)
+		if err != nil {
+			t.Fatalf("GetParser(%s) error = %v", ext, err)
+		}
+		p, ok := parser.(*PropertiesParser)
+		if !ok || p.DotEnv != dotEnv {
+			t.Errorf("GetParser(%s) = %#v, want *PropertiesParser with DotEnv %v", ext, parser, dotEnv)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package documentParser
+
+import (
+	"io"
+	"regexp"
+	"strings"
+)
+
+// RSTParser はreStructuredTextファイルのパーサー
+// ディレクティブ、セクションの装飾線、インラインロールを除去し、本文とコードブロックの内容を残す
+type RSTParser struct {
+	TextParser
+
+	// Raw が true の場合、記法を除去せずに内容をそのまま返す
+	Raw bool
+}
+
+// SupportedExtensions はサポートする拡張子を返す
+func (p *RSTParser) SupportedExtensions() []string {
+	return []string{".rst", ".rest"}
+}
+
+// ParseFromFile はファイルパスからreStructuredTextをパース
+func (p *RSTParser) ParseFromFile(filePath string) (string, error) {
+	return parseFromFileCommon(p, filePath)
+}
+
+// ParseFromBytes はバイト配列からreStructuredTextをパース
+func (p *RSTParser) ParseFromBytes(data []byte) (string, error) {
+	text, err := p.bytesText(data)
+	if err != nil {
+		return "", err
+	}
+	return truncateOutput(p.render(text), p.MaxOutputBytes), nil
+}
+
+// ParseFromReader はio.ReaderAtからreStructuredTextをパース
+func (p *RSTParser) ParseFromReader(reader io.ReaderAt, size int64) (string, error) {
+	text, err := p.readerText(reader, size)
+	if err != nil {
+		return "", err
+	}
+	return truncateOutput(p.render(text), p.MaxOutputBytes), nil
+}
+
+func (p *RSTParser) render(text string) string {
+	if p.Raw {
+		return text
+	}
+	return stripRST(text)
+}
+
+var (
+	rstDirectivePattern = regexp.MustCompile(`^(\s*)\.\.\s+([\w:-]+)::\s*(.*)$`)
+	rstCommentPattern   = regexp.MustCompile(`^(\s*)\.\.(\s|$)`)
+	rstOptionPattern    = regexp.MustCompile(`^\s+:[\w-]+:`)
+	rstRolePattern      = regexp.MustCompile("(?::[\\w:-]+:)?`([^`<]*?)\\s*(?:<[^>]*>)?`(?::[\\w:-]+:)?_{0,2}")
+	rstLiteralPattern   = regexp.MustCompile("``([^`]+)``")
+	rstStrongPattern    = regexp.MustCompile(`\*\*([^*]+)\*\*`)
+	rstEmphasisPattern  = regexp.MustCompile(`(^|[^\w*])\*([^*\s][^*]*)\*`)
+)
+
+// rstDroppedDirectives は内容ごと除去するディレクティブ
+var rstDroppedDirectives = map[string]bool{
+	"image":          true,
+	"toctree":        true,
+	"include":        true,
+	"raw":            true,
+	"meta":           true,
+	"contents":       true,
+	"index":          true,
+	"highlight":      true,
+	"literalinclude": true,
+}
+
+// stripRST はreStructuredTextの記法を除去してプレーンテキストに変換する
+func stripRST(text string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+
+	var result []string
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		if m := rstDirectivePattern.FindStringSubmatch(line); m != nil {
+			indent, name, arg := len(m[1]), strings.ToLower(m[2]), strings.TrimSpace(m[3])
+			block, next := rstIndentedBlock(lines, i+1, indent)
+			i = next - 1
+
+			if rstDroppedDirectives[name] {
+				continue
+			}
+			// code-blockの引数は言語名なので出力しない
+			if arg != "" && !isRSTCodeDirective(name) {
+				result = append(result, stripRSTInline(arg))
+			}
+			for _, blockLine := range block {
+				if isRSTCodeDirective(name) {
+					result = append(result, blockLine)
+				} else {
+					result = append(result, stripRSTInline(blockLine))
+				}
+			}
+			result = append(result, "")
+			continue
+		}
+
+		// コメントとハイパーリンクターゲット（.. _label:）は後続のインデントブロックごと除去
+		if m := rstCommentPattern.FindStringSubmatch(line); m != nil {
+			_, next := rstIndentedBlock(lines, i+1, len(m[1]))
+			i = next - 1
+			continue
+		}
+
+		// セクションの装飾線と区切り線
+		if isRSTAdornment(line) {
+			continue
+		}
+
+		// "::" で終わる段落の後はリテラルブロック
+		if strings.HasSuffix(strings.TrimRight(line, " "), "::") {
+			trimmed := strings.TrimSuffix(strings.TrimRight(line, " "), "::")
+			if strings.TrimSpace(trimmed) != "" {
+				if strings.HasSuffix(trimmed, " ") {
+					result = append(result, stripRSTInline(strings.TrimRight(trimmed, " ")))
+				} else {
+					result = append(result, stripRSTInline(trimmed+":"))
+				}
+			}
+			block, next := rstIndentedBlock(lines, i+1, len(line)-len(strings.TrimLeft(line, " ")))
+			result = append(result, block...)
+			result = append(result, "")
+			i = next - 1
+			continue
+		}
+
+		result = append(result, stripRSTInline(line))
+	}
+
+	// 除去した要素の前後で連続した空行を1行にまとめる
+	var compacted []string
+	for _, line := range result {
+		if line == "" && len(compacted) > 0 && compacted[len(compacted)-1] == "" {
+			continue
+		}
+		compacted = append(compacted, line)
+	}
+
+	return strings.Join(compacted, "\n")
+}
+
+// isRSTAdornment は行が同じ記号の3文字以上の繰り返し（装飾線）かどうかを判定する
+func isRSTAdornment(line string) bool {
+	line = strings.TrimRight(line, " \t")
+	if len(line) < 3 || !strings.ContainsRune("=-`:'\"~^_*+#<>.", rune(line[0])) {
+		return false
+	}
+	return strings.Count(line, line[:1]) == len(line)
+}
+
+// isRSTCodeDirective はコードブロックのディレクティブかどうかを判定する
+func isRSTCodeDirective(name string) bool {
+	return name == "code-block" || name == "code" || name == "sourcecode"
+}
+
+// rstIndentedBlock はstart行から始まるインデントされたブロックを返す
+// ブロック先頭のオプション行（:maxdepth: 2 など）は除外し、インデントを取り除く
+// 戻り値の2つ目はブロックの次の行番号
+func rstIndentedBlock(lines []string, start, parentIndent int) ([]string, int) {
+	end := start
+	for end < len(lines) {
+		line := lines[end]
+		if strings.TrimSpace(line) != "" && len(line)-len(strings.TrimLeft(line, " \t")) <= parentIndent {
+			break
+		}
+		end++
+	}
+
+	block := lines[start:end]
+	for len(block) > 0 && rstOptionPattern.MatchString(block[0]) {
+		block = block[1:]
+	}
+
+	// 共通のインデントを除去
+	indent := -1
+	for _, line := range block {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		n := len(line) - len(strings.TrimLeft(line, " \t"))
+		if indent < 0 || n < indent {
+			indent = n
+		}
+	}
+
+	var result []string
+	for _, line := range block {
+		if len(line) >= indent && indent > 0 {
+			line = line[indent:]
+		}
+		result = append(result, strings.TrimRight(line, " \t"))
+	}
+
+	// 前後の空行を詰める
+	for len(result) > 0 && result[len(result)-1] == "" {
+		result = result[:len(result)-1]
+	}
+	for len(result) > 0 && result[0] == "" {
+		result = result[1:]
+	}
+
+	return result, end
+}
+
+// stripRSTInline は行内のロール、リテラル、強調記法を除去する
+func stripRSTInline(line string) string {
+	line = rstLiteralPattern.ReplaceAllString(line, "$1")
+	line = rstRolePattern.ReplaceAllString(line, "$1")
+	line = rstStrongPattern.ReplaceAllString(line, "$1")
+	line = rstEmphasisPattern.ReplaceAllString(line, "$1$2")
+	return line
+}
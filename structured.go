@@ -0,0 +1,385 @@
+package documentParser
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Document はParseStructuredが返す構造化されたパース結果
+// json.Marshalでそのままシリアライズできる
+type Document struct {
+	// Format は表示用のフォーマット名（例: "PDF Document"）
+	Format string `json:"format"`
+	// Metadata はメタデータ（MetadataParserを実装していないパーサー、またはメタデータを読み取れない場合はnil）
+	Metadata *Metadata `json:"metadata,omitempty"`
+	// Pages はページ/スライド/シートごとの内容（ページを持たない形式では1要素）
+	Pages []Page `json:"pages"`
+	// Truncated はページの内容の合計がMaxOutputBytesを超えたため切り詰められた場合に true
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// Page は1ページ分の内容
+type Page struct {
+	// Number は1始まりのページ番号
+	Number int `json:"number"`
+	// Name はページの名前（"Page 1"、"Slide 1"、シート名など）
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
+
+// orderedPageParser は順序付きのページ一覧を返せるパーサー
+type orderedPageParser interface {
+	parsePages(reader io.ReaderAt, size int64) ([]Page, error)
+}
+
+// ParseStructured はio.ReaderAtからドキュメントをパースし、フォーマット名・メタデータ・ページを返す
+// ページ分割に対応していない形式は全体を1ページ（名前: "Content"）として返す
+func (f *DocumentParserFactory) ParseStructured(ext string, reader io.ReaderAt, size int64) (Document, error) {
+	defer f.acquire()()
+
+	parser, err := f.GetParser(ext)
+	if err != nil {
+		return Document{}, fmt.Errorf("failed to get parser: %w", err)
+	}
+
+	doc := Document{Format: formatName(normalizeExtension(ext))}
+
+	// メタデータは補助的な情報のため、読み取れない場合もページは返す
+	if p, ok := parser.(MetadataParser); ok {
+		if metadata, err := p.ParseMetadata(reader, size); err != nil {
+			log.Printf("Error parsing metadata: %s", err)
+		} else {
+			doc.Metadata = metadata
+		}
+	}
+
+	doc.Pages, doc.Truncated, err = parserPages(parser, reader, size)
+	if err != nil {
+		return Document{}, fmt.Errorf("failed to parse from reader: %w", err)
+	}
+
+	return doc, nil
+}
+
+// parserPages はパーサーでページごとの内容を取得し、MaxOutputBytesに従って切り詰める
+// ページ分割に対応していないパーサーは全体を1ページ（名前: "Content"）とする
+func parserPages(parser DocumentParser, reader io.ReaderAt, size int64) (pages []Page, truncated bool, err error) {
+	switch p := parser.(type) {
+	case orderedPageParser:
+		pages, err = p.parsePages(reader, size)
+	case PageSeparatedParser:
+		var pageMap map[string]string
+		pageMap, err = p.ParseWithPages(reader, size)
+		pages = sortedPages(pageMap)
+	default:
+		var content string
+		content, err = parser.ParseFromReader(reader, size)
+		pages = []Page{{Number: 1, Name: "Content", Content: content}}
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	if b, ok := parser.(baseProvider); ok {
+		pages, truncated = truncatePages(pages, b.base().MaxOutputBytes)
+	}
+	return pages, truncated, nil
+}
+
+// TOCEntry はParseTableOfContentsが返す目次の1項目
+type TOCEntry struct {
+	// Name は見出しのテキスト、またはページ/スライド/シートの名前
+	// DOCXの最初の見出しより前の本文は空文字列
+	Name string `json:"name"`
+	// Level は見出しのレベル（1始まり）。ページ単位の項目は1、DOCXの最初の見出しより前の本文は0
+	Level int `json:"level"`
+	// CharCount は項目の範囲の本文の文字数（runeの数）
+	CharCount int `json:"charCount"`
+}
+
+// tocParser は見出しから目次を作れるパーサー
+// 見出しがない場合はnilを返し、ページごとの目次にする
+type tocParser interface {
+	tableOfContents(reader io.ReaderAt, size int64) ([]TOCEntry, error)
+}
+
+// ParseTableOfContents はio.ReaderAtからドキュメントをパースし、目次の一覧を返す
+// DOCXは見出しの段落ごと（見出しがない場合はセクションごと）、その他の形式はParseStructuredと同じページごとの項目とする
+// 本文全体を返さずに、文書の構成と各部分の大きさを確認する場合に使う
+func (f *DocumentParserFactory) ParseTableOfContents(ext string, reader io.ReaderAt, size int64) ([]TOCEntry, error) {
+	defer f.acquire()()
+
+	parser, err := f.GetParser(ext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get parser: %w", err)
+	}
+
+	if p, ok := parser.(tocParser); ok {
+		entries, err := p.tableOfContents(reader, size)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse from reader: %w", err)
+		}
+		if entries != nil {
+			return entries, nil
+		}
+	}
+
+	pages, _, err := parserPages(parser, reader, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse from reader: %w", err)
+	}
+
+	entries := make([]TOCEntry, 0, len(pages))
+	for _, page := range pages {
+		entries = append(entries, TOCEntry{Name: page.Name, Level: 1, CharCount: utf8.RuneCountInString(page.Content)})
+	}
+	return entries, nil
+}
+
+// TextChunk はParseTokensが返す1文（または1行）分のテキスト
+type TextChunk struct {
+	// Page はテキストを含むページ/スライド/シートの番号（1始まり、ページを持たない形式では1）
+	Page int    `json:"page"`
+	Text string `json:"text"`
+}
+
+// ParseTokens はio.ReaderAtからドキュメントをパースし、ページごとの内容を行と文に分割して返す
+// 行は改行で、行内の文は文末の記号（"." "!" "?" の後に空白が続く箇所と、"。" "！" "？"）で区切る
+// 各要素は前後の空白を除き、空の行は含めない。結合した文字列を再分割せずに、ページ番号付きで行単位の処理に渡す場合に使う
+func (f *DocumentParserFactory) ParseTokens(ext string, reader io.ReaderAt, size int64) ([]TextChunk, error) {
+	defer f.acquire()()
+
+	parser, err := f.GetParser(ext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get parser: %w", err)
+	}
+
+	pages, _, err := parserPages(parser, reader, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse from reader: %w", err)
+	}
+
+	var chunks []TextChunk
+	for _, page := range pages {
+		for _, line := range strings.Split(page.Content, "\n") {
+			for _, sentence := range splitSentences(line) {
+				chunks = append(chunks, TextChunk{Page: page.Number, Text: sentence})
+			}
+		}
+	}
+	return chunks, nil
+}
+
+// splitSentences は1行のテキストを文に分割する
+// 文末の記号の直後の閉じ括弧・引用符（"）」』など）は前の文に含める。"3.14" のような空白の続かない "." では区切らない
+func splitSentences(line string) []string {
+	var sentences []string
+	add := func(s string) {
+		if s = strings.TrimSpace(s); s != "" {
+			sentences = append(sentences, s)
+		}
+	}
+
+	runes := []rune(line)
+	start := 0
+	for i := 0; i < len(runes); i++ {
+		switch runes[i] {
+		case '.', '!', '?', '。', '！', '？':
+		default:
+			continue
+		}
+		end := i + 1
+		for end < len(runes) && strings.ContainsRune(`"')]」』）】`, runes[end]) {
+			end++
+		}
+		if runes[i] == '.' || runes[i] == '!' || runes[i] == '?' {
+			if end < len(runes) && runes[end] != ' ' && runes[end] != '\t' {
+				continue
+			}
+		}
+		add(string(runes[start:end]))
+		start = end
+		i = end - 1
+	}
+	add(string(runes[start:]))
+	return sentences
+}
+
+// truncatePages はページの内容の合計がmaxBytesを超える場合、超えたページを切り詰めて以降のページを除外する
+// 切り詰めたページの末尾にはTruncatedMarkerを付け、TruncatedMarkerを含めた合計がmaxBytes以内になるようにする
+func truncatePages(pages []Page, maxBytes int) ([]Page, bool) {
+	if maxBytes <= 0 {
+		return pages, false
+	}
+	total := 0
+	for _, page := range pages {
+		total += len(page.Content)
+	}
+	if total <= maxBytes {
+		return pages, false
+	}
+
+	remaining, marker := maxBytes-len(TruncatedMarker), TruncatedMarker
+	if remaining < 0 {
+		remaining, marker = maxBytes, ""
+	}
+	for i := range pages {
+		if len(pages[i].Content) > remaining {
+			pages[i].Content = cutText(pages[i].Content, remaining) + marker
+			return pages[:i+1], true
+		}
+		remaining -= len(pages[i].Content)
+	}
+	return pages, false
+}
+
+// sentinelMarkerPattern はMarkerSentinelで出力される境界の行
+var sentinelMarkerPattern = regexp.MustCompile(`(?m)^<<<(PAGE|SLIDE|SHEET):(.*)>>>\n?`)
+
+// SplitSentinelMarkers はMarkerSentinelで出力した結合文字列を境界の行で分割し、ページの一覧を返す
+// Nameは "Page 3"、"Slide 3"、シート名（IncludeSheetIndexの場合は "2:シート名"）で、Contentは前後の空白（シートの場合は末尾の区切り線も）を除いた内容
+// 最初の境界より前の内容は空白のみの場合は除外し、それ以外は名前のないページとする
+func SplitSentinelMarkers(text string) []Page {
+	var pages []Page
+	add := func(name, content string) {
+		pages = append(pages, Page{Number: len(pages) + 1, Name: name, Content: strings.TrimSpace(content)})
+	}
+
+	matches := sentinelMarkerPattern.FindAllStringSubmatchIndex(text, -1)
+	if len(matches) == 0 {
+		if strings.TrimSpace(text) != "" {
+			add("", text)
+		}
+		return pages
+	}
+	if leading := text[:matches[0][0]]; strings.TrimSpace(leading) != "" {
+		add("", leading)
+	}
+
+	for i, m := range matches {
+		kind, label := text[m[2]:m[3]], text[m[4]:m[5]]
+		end := len(text)
+		if i+1 < len(matches) {
+			end = matches[i+1][0]
+		}
+
+		name, content := label, text[m[1]:end]
+		switch kind {
+		case "PAGE":
+			name = "Page " + label
+		case "SLIDE":
+			name = "Slide " + label
+		case "SHEET":
+			// Excelの結合出力で各シートの後に付く区切り線を除く
+			content = strings.TrimSuffix(strings.TrimSpace(content), "---")
+		}
+		add(name, content)
+	}
+	return pages
+}
+
+// CombinePages はParseWithPagesのマップを1つの文字列に結合する
+// "Slide 2" と "Slide 10" のように末尾が数値の名前は数値順に並べ、各パーサーの結合出力と同じ見出しを付ける
+// （"Page N" はPDF、"Slide N" はPPTX、それ以外の名前はExcelのシートと同じ見出しと区切り線）
+// ページ分割に対応していない形式の結果（"Content" のみのマップ）は内容をそのまま返す
+// ファクトリーで設定したページの区切り文字列・見出しの書式・Labelsを使う場合は DocumentParserFactory.CombinePages を使う
+func CombinePages(pages map[string]string) string {
+	return (&BaseParser{}).combinePages(pages)
+}
+
+// CombinePages はParseWithPagesのマップを、SetPageSeparatorの区切り文字列・SetMarkerStyleの見出しの書式・SetLabelsの見出しで1つの文字列に結合する
+func (f *DocumentParserFactory) CombinePages(pages map[string]string) string {
+	b := &BaseParser{PageSeparator: f.pageSeparator, MarkerStyle: f.markerStyle, Labels: f.labels}
+	return b.combinePages(pages)
+}
+
+// combinePages はPageSeparator・MarkerStyle・Labelsに従ってページを結合する
+func (p *BaseParser) combinePages(pages map[string]string) string {
+	if content, ok := pages["Content"]; ok && len(pages) == 1 {
+		return content
+	}
+
+	var sb strings.Builder
+	for i, page := range sortedPages(pages) {
+		p.writePageSeparator(&sb, i)
+		content := strings.TrimRight(page.Content, "\n")
+		switch kind, label := pageMarkerKind(page.Name); kind {
+		case "PAGE":
+			sb.WriteString(p.boundaryMarker(heading("## ", p.labels().PageHeader, label, "\n\n"), kind, label))
+			sb.WriteString(content + "\n\n")
+		case "SLIDE":
+			sb.WriteString(p.boundaryMarker(heading("## ", p.labels().SlideHeader, label, "\n"), kind, label))
+			sb.WriteString(content + "\n\n")
+		default:
+			sheets := &ExcelParser{BaseParser: *p}
+			sb.WriteString(sheets.sheetHeading(0, label))
+			if content != "" {
+				sb.WriteString(content + "\n")
+			}
+			sb.WriteString(sheets.sheetTerminator())
+		}
+	}
+	return sb.String()
+}
+
+// pageMarkerKind はページの名前からMarkerSentinelの境界の種類とラベルを返す（SplitSentinelMarkersの逆の変換）
+// "Page N" と "Slide N" 以外の名前はシート名として扱う
+func pageMarkerKind(name string) (kind, label string) {
+	if number, ok := strings.CutPrefix(name, "Page "); ok {
+		return "PAGE", number
+	}
+	if number, ok := strings.CutPrefix(name, "Slide "); ok {
+		return "SLIDE", number
+	}
+	return "SHEET", name
+}
+
+// sortedPages はParseWithPagesのマップをページの一覧に変換する
+// "Section 2" と "Section 10" のように末尾が数値の名前は数値順に並べる
+func sortedPages(pages map[string]string) []Page {
+	names := make([]string, 0, len(pages))
+	for name := range pages {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return naturalLess(names[i], names[j])
+	})
+
+	result := make([]Page, len(names))
+	for i, name := range names {
+		result[i] = Page{Number: i + 1, Name: name, Content: pages[name]}
+	}
+	return result
+}
+
+// naturalLess は末尾の数値を考慮して名前を比較する
+func naturalLess(a, b string) bool {
+	prefixA, numA, okA := splitTrailingNumber(a)
+	prefixB, numB, okB := splitTrailingNumber(b)
+	if okA && okB && prefixA == prefixB {
+		return numA < numB
+	}
+	return a < b
+}
+
+// splitTrailingNumber は名前を末尾の数値とそれ以前に分割する
+func splitTrailingNumber(name string) (string, int, bool) {
+	i := len(name)
+	for i > 0 && name[i-1] >= '0' && name[i-1] <= '9' {
+		i--
+	}
+	if i == len(name) {
+		return name, 0, false
+	}
+	n, err := strconv.Atoi(name[i:])
+	if err != nil {
+		return name, 0, false
+	}
+	return strings.TrimSpace(name[:i]), n, true
+}
@@ -0,0 +1,149 @@
+package documentParser
+
+import (
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SubtitleParser は字幕ファイル（SubRip / WebVTT）のパーサー
+// 連番とタイムスタンプの行を除去し、発話テキストを段落にまとめて返す
+type SubtitleParser struct {
+	TextParser
+
+	// KeepTimestamps が true の場合、キューごとに "[開始 --> 終了] テキスト" の行として出力する
+	KeepTimestamps bool
+}
+
+// subtitleParagraphGap はこの間隔以上離れたキューを別の段落にする
+const subtitleParagraphGap = 2 * time.Second
+
+// SupportedExtensions はサポートする拡張子を返す
+func (p *SubtitleParser) SupportedExtensions() []string {
+	return []string{".srt", ".vtt"}
+}
+
+// ParseFromFile はファイルパスから字幕をパース
+func (p *SubtitleParser) ParseFromFile(filePath string) (string, error) {
+	return parseFromFileCommon(p, filePath)
+}
+
+// ParseFromBytes はバイト配列から字幕をパース
+func (p *SubtitleParser) ParseFromBytes(data []byte) (string, error) {
+	text, err := p.bytesText(data)
+	if err != nil {
+		return "", err
+	}
+	return truncateOutput(p.render(text), p.MaxOutputBytes), nil
+}
+
+// ParseFromReader はio.ReaderAtから字幕をパース
+func (p *SubtitleParser) ParseFromReader(reader io.ReaderAt, size int64) (string, error) {
+	text, err := p.readerText(reader, size)
+	if err != nil {
+		return "", err
+	}
+	return truncateOutput(p.render(text), p.MaxOutputBytes), nil
+}
+
+// subtitleCue は字幕の1キュー
+type subtitleCue struct {
+	start, end string
+	text       string
+}
+
+var (
+	subtitleTagPattern       = regexp.MustCompile(`<[^>]*>|\{\\[^}]*\}`)
+	subtitleTimestampPattern = regexp.MustCompile(`^\s*((?:\d+:)?\d{2}:\d{2}[.,]\d{3})\s*-->\s*((?:\d+:)?\d{2}:\d{2}[.,]\d{3})`)
+)
+
+func (p *SubtitleParser) render(text string) string {
+	cues := parseSubtitleCues(text)
+
+	var sb strings.Builder
+	if p.KeepTimestamps {
+		for _, cue := range cues {
+			sb.WriteString("[" + cue.start + " --> " + cue.end + "] " + cue.text + "\n")
+			if p.outputLimitReached(sb.Len()) {
+				break
+			}
+		}
+		return sb.String()
+	}
+
+	for i, cue := range cues {
+		if i > 0 {
+			if subtitleTime(cue.start)-subtitleTime(cues[i-1].end) >= subtitleParagraphGap {
+				sb.WriteString("\n\n")
+			} else {
+				sb.WriteString(" ")
+			}
+		}
+		sb.WriteString(cue.text)
+		if p.outputLimitReached(sb.Len()) {
+			break
+		}
+	}
+	if sb.Len() > 0 {
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// parseSubtitleCues はSRT/WebVTTのテキストをキューに分割する
+// WEBVTTヘッダー、NOTE/STYLE/REGIONブロック、キューの識別子と設定は除去する
+func parseSubtitleCues(text string) []subtitleCue {
+	text = strings.TrimPrefix(text, "\ufeff")
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+
+	var cues []subtitleCue
+	for _, block := range strings.Split(text, "\n\n") {
+		lines := strings.Split(strings.Trim(block, "\n"), "\n")
+
+		// タイムスタンプ行を探す（それより前の行は連番またはキューの識別子）
+		timestampLine := -1
+		for i, line := range lines {
+			if strings.Contains(line, "-->") {
+				timestampLine = i
+				break
+			}
+		}
+		if timestampLine < 0 {
+			continue
+		}
+		m := subtitleTimestampPattern.FindStringSubmatch(lines[timestampLine])
+		if m == nil {
+			continue
+		}
+
+		var textLines []string
+		for _, line := range lines[timestampLine+1:] {
+			line = strings.TrimSpace(subtitleTagPattern.ReplaceAllString(line, ""))
+			if line != "" {
+				textLines = append(textLines, line)
+			}
+		}
+		if len(textLines) == 0 {
+			continue
+		}
+
+		cues = append(cues, subtitleCue{start: m[1], end: m[2], text: strings.Join(textLines, " ")})
+	}
+	return cues
+}
+
+// subtitleTime は "hh:mm:ss,mmm" / "mm:ss.mmm" 形式のタイムスタンプを時間に変換する
+func subtitleTime(timestamp string) time.Duration {
+	timestamp = strings.Replace(timestamp, ",", ".", 1)
+	parts := strings.Split(timestamp, ":")
+
+	seconds := 0.0
+	for _, part := range parts {
+		n, _ := strconv.ParseFloat(part, 64)
+		seconds = seconds*60 + n
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
@@ -1,41 +1,48 @@
 package documentParser
 
 import (
+	"bufio"
+	"bytes"
 	"fmt"
 	"io"
+	"strings"
+	"sync"
 )
 
 // TextParser はプレーンテキストファイルのパーサー
 type TextParser struct {
 	BaseParser
+
+	// HeadLines が正の場合、先頭からN行のみを返す
+	HeadLines int
+
+	// TailLines が正の場合、末尾のN行のみを返す
+	// ファイル全体を読み込まず、ReaderAtで末尾から読み進める
+	TailLines int
+
+	// Encoding は入力の文字コード（"cp932"、"shift_jis"、"euc-jp" など）
+	// 空の場合は変換せずにそのまま返す。"cp932" はNEC特殊文字（①、㈱など）・IBM拡張文字を含むWindowsの文字コードとして変換する
+	// HeadLines・TailLinesは改行のバイトで行を数えるため、UTF-16などのASCII互換でない文字コードと組み合わせるとエラーになる
+	Encoding string
+}
+
+// setEncoding はファクトリーのWithEncodingをEncodingに反映する
+func (p *TextParser) setEncoding(encoding string) {
+	p.Encoding = encoding
 }
 
 // SupportedExtensions はサポートする拡張子を返す
 func (p *TextParser) SupportedExtensions() []string {
 	return []string{
 		".txt",          // プレーンテキスト
-		".md",           // Markdown
-		".markdown",     // Markdown (別拡張子)
-		".mdown",        // Markdown (別拡張子)
-		".mkd",          // Markdown (別拡張子)
-		".mdwn",         // Markdown (別拡張子)
-		".mkdn",         // Markdown (別拡張子)
-		".mdtxt",        // Markdown (別拡張子)
-		".mdtext",       // Markdown (別拡張子)
 		".text",         // プレーンテキスト
 		".log",          // ログファイル
-		".csv",          // CSVファイル
-		".tsv",          // TSVファイル
-		".json",         // JSONファイル
-		".xml",          // XMLファイル
 		".yaml",         // YAMLファイル
 		".yml",          // YAMLファイル (別拡張子)
 		".toml",         // TOMLファイル
 		".ini",          // INIファイル
 		".cfg",          // 設定ファイル
 		".conf",         // 設定ファイル
-		".properties",   // Javaプロパティファイル
-		".env",          // 環境変数ファイル
 		".sh",           // シェルスクリプト
 		".bash",         // Bashスクリプト
 		".zsh",          // Zshスクリプト
@@ -57,8 +64,6 @@ func (p *TextParser) SupportedExtensions() []string {
 		".rb",           // Rubyコード
 		".php",          // PHPコード
 		".sql",          // SQLファイル
-		".html",         // HTMLファイル
-		".htm",          // HTMLファイル (別拡張子)
 		".css",          // CSSファイル
 		".scss",         // SCSSファイル
 		".sass",         // SASSファイル
@@ -101,18 +106,98 @@ func (p *TextParser) SupportedExtensions() []string {
 	}
 }
 
+// ParseFromFile はファイルパスからテキストを読み込んでそのまま返す
+func (p *TextParser) ParseFromFile(filePath string) (string, error) {
+	return parseFromFileCommon(p, filePath)
+}
+
 // ParseFromBytes はバイト配列をそのまま文字列として返す
 func (p *TextParser) ParseFromBytes(data []byte) (string, error) {
+	text, err := p.bytesText(data)
+	if err != nil {
+		return "", err
+	}
+	return truncateOutput(text, p.MaxOutputBytes), nil
+}
+
+// ParseFromReader はio.ReaderAtからテキストを読み込んでそのまま返す
+func (p *TextParser) ParseFromReader(reader io.ReaderAt, size int64) (string, error) {
+	// 変換しない場合は出力がそのまま入力のバイト列になるため、上限を少し超える分だけ読めば切り詰められる
+	if p.MaxOutputBytes > 0 && p.Encoding == "" && p.HeadLines <= 0 && p.TailLines <= 0 {
+		size = min(size, int64(p.MaxOutputBytes)+1)
+	}
+	text, err := p.readerText(reader, size)
+	if err != nil {
+		return "", err
+	}
+	return truncateOutput(text, p.MaxOutputBytes), nil
+}
+
+// bytesText はバイト配列を切り詰めずにテキストに変換する（TextParserを埋め込むパーサーが変換前のテキストを得るために使う）
+func (p *TextParser) bytesText(data []byte) (string, error) {
+	if p.HeadLines > 0 || p.TailLines > 0 {
+		return p.readerText(bytes.NewReader(data), int64(len(data)))
+	}
+
 	// サイズ制限を設定（最大100MB）
 	const maxSize = 100 * 1024 * 1024 // 100MB
 	if len(data) > maxSize {
 		return "", fmt.Errorf("file size %d exceeds maximum allowed size of %d bytes", len(data), maxSize)
 	}
-	return string(data), nil
+	return p.decode(string(data))
 }
 
-// ParseFromReader はio.ReaderAtからテキストを読み込んでそのまま返す
-func (p *TextParser) ParseFromReader(reader io.ReaderAt, size int64) (string, error) {
+// readerText はio.ReaderAtからテキストを切り詰めずに読み込む
+func (p *TextParser) readerText(reader io.ReaderAt, size int64) (string, error) {
+	text, err := p.readText(reader, size)
+	if err != nil {
+		return "", err
+	}
+	return p.decode(text)
+}
+
+// decode はEncodingが指定されている場合に読み込んだテキストをUTF-8に変換する
+func (p *TextParser) decode(text string) (string, error) {
+	if p.Encoding == "" {
+		return text, nil
+	}
+	decoded, err := decodeText([]byte(text), p.Encoding)
+	if err != nil {
+		return "", err
+	}
+	return string(decoded), nil
+}
+
+// checkLineOptions はHeadLines・TailLinesを指定した組み合わせで使えるかどうかを確認する
+// 改行のバイトで行を数えるため、EncodingがASCII互換でない文字コード（UTF-16など）の場合はエラーにする
+func (p *TextParser) checkLineOptions() error {
+	if p.HeadLines > 0 && p.TailLines > 0 {
+		return fmt.Errorf("HeadLines and TailLines cannot be used together")
+	}
+	if (p.HeadLines > 0 || p.TailLines > 0) && p.Encoding != "" {
+		enc, err := lookupEncoding(p.Encoding)
+		if err != nil {
+			return err
+		}
+		if !asciiCompatible(enc) {
+			return fmt.Errorf("HeadLines and TailLines cannot be used with encoding %s", p.Encoding)
+		}
+	}
+	return nil
+}
+
+// readText はio.ReaderAtから変換前のテキストを読み込む
+func (p *TextParser) readText(reader io.ReaderAt, size int64) (string, error) {
+	if err := p.checkLineOptions(); err != nil {
+		return "", err
+	}
+	if p.HeadLines > 0 {
+		return readHeadLines(reader, size, p.HeadLines)
+	}
+	if p.TailLines > 0 {
+		return readTailLines(reader, size, p.TailLines)
+	}
+
 	// io.ReaderAt を io.Reader に変換
 	// サイズ制限を設定（最大100MB）
 	const maxSize = 100 * 1024 * 1024 // 100MB
@@ -120,6 +205,19 @@ func (p *TextParser) ParseFromReader(reader io.ReaderAt, size int64) (string, er
 		return "", fmt.Errorf("file size %d exceeds maximum allowed size of %d bytes", size, maxSize)
 	}
 
+	// 小さなファイルはプールしたバッファに読み込み、文字列への変換時のコピーのみでアロケーションを済ませる
+	if size <= smallTextSize {
+		bufferPtr := smallTextPool.Get().(*[]byte)
+		defer smallTextPool.Put(bufferPtr)
+
+		buffer := (*bufferPtr)[:size]
+		n, err := reader.ReadAt(buffer, 0)
+		if err != nil && err != io.EOF {
+			return "", fmt.Errorf("error reading text file: %w", err)
+		}
+		return string(buffer[:n]), nil
+	}
+
 	// バッファを作成してデータを読み込む
 	buffer := make([]byte, size)
 	n, err := reader.ReadAt(buffer, 0)
@@ -131,6 +229,144 @@ func (p *TextParser) ParseFromReader(reader io.ReaderAt, size int64) (string, er
 	return string(buffer[:n]), nil
 }
 
+// parseStream はio.Readerから先頭から順にテキストを読み込む（圧縮されたファイルを展開しながら読み込む場合に使う）
+// TailLinesは末尾の位置がわからないため、全体を読み込んでから末尾の行を取り出す
+func (p *TextParser) parseStream(r io.Reader) (string, error) {
+	if p.MaxOutputBytes > 0 && p.Encoding == "" && p.HeadLines <= 0 && p.TailLines <= 0 {
+		r = io.LimitReader(r, int64(p.MaxOutputBytes)+1)
+	}
+	text, err := p.readStream(r)
+	if err != nil {
+		return "", err
+	}
+	text, err = p.decode(text)
+	if err != nil {
+		return "", err
+	}
+	return truncateOutput(text, p.MaxOutputBytes), nil
+}
+
+// readStream はio.Readerから変換前のテキストを読み込む
+func (p *TextParser) readStream(r io.Reader) (string, error) {
+	if err := p.checkLineOptions(); err != nil {
+		return "", err
+	}
+	if p.HeadLines > 0 {
+		return readHeadLinesFrom(r, p.HeadLines)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r, textMaxSize+1))
+	if err != nil {
+		return "", fmt.Errorf("error reading text file: %w", err)
+	}
+	if len(data) > textMaxSize {
+		return "", fmt.Errorf("file size exceeds maximum allowed size of %d bytes", textMaxSize)
+	}
+	if p.TailLines > 0 {
+		return readTailLines(bytes.NewReader(data), int64(len(data)), p.TailLines)
+	}
+	return string(data), nil
+}
+
+// smallTextSize はプールしたバッファで読み込むファイルサイズの上限（64KB）
+const smallTextSize = 64 * 1024
+
+// smallTextPool は小さなファイルの読み込みに使うバッファのプール
+var smallTextPool = sync.Pool{
+	New: func() any {
+		buffer := make([]byte, smallTextSize)
+		return &buffer
+	},
+}
+
+// textMaxSize は一度に返すテキストの最大サイズ（100MB）
+const textMaxSize = 100 * 1024 * 1024
+
+// tailChunkSize は末尾から読み込む際のチャンクサイズ
+const tailChunkSize = 64 * 1024
+
+// readHeadLines は先頭からn行を読み込む
+func readHeadLines(reader io.ReaderAt, size int64, n int) (string, error) {
+	return readHeadLinesFrom(io.NewSectionReader(reader, 0, size), n)
+}
+
+// readHeadLinesFrom はio.Readerの先頭からn行を読み込む
+func readHeadLinesFrom(reader io.Reader, n int) (string, error) {
+	br := bufio.NewReader(reader)
+
+	var sb strings.Builder
+	for i := 0; i < n; i++ {
+		line, err := br.ReadString('\n')
+		sb.WriteString(line)
+		if sb.Len() > textMaxSize {
+			return "", fmt.Errorf("first %d lines exceed maximum allowed size of %d bytes", n, textMaxSize)
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("error reading text file: %w", err)
+		}
+	}
+
+	return sb.String(), nil
+}
+
+// readTailLines は末尾からn行を読み込む
+// チャンク単位で末尾から改行を数え、n行目の開始位置から最後までを返す
+func readTailLines(reader io.ReaderAt, size int64, n int) (string, error) {
+	if size == 0 {
+		return "", nil
+	}
+
+	buffer := make([]byte, tailChunkSize)
+	start := int64(0)
+	// ファイル末尾の改行は行の区切りとして数えない
+	end := size
+	if _, err := reader.ReadAt(buffer[:1], size-1); err != nil && err != io.EOF {
+		return "", fmt.Errorf("error reading text file: %w", err)
+	}
+	if buffer[0] == '\n' {
+		end--
+	}
+
+	count := 0
+search:
+	for pos := end; pos > 0; {
+		chunkStart := pos - tailChunkSize
+		if chunkStart < 0 {
+			chunkStart = 0
+		}
+		chunk := buffer[:pos-chunkStart]
+		if _, err := reader.ReadAt(chunk, chunkStart); err != nil && err != io.EOF {
+			return "", fmt.Errorf("error reading text file: %w", err)
+		}
+
+		for i := len(chunk) - 1; i >= 0; i-- {
+			if chunk[i] != '\n' {
+				continue
+			}
+			count++
+			if count == n {
+				start = chunkStart + int64(i) + 1
+				break search
+			}
+		}
+		pos = chunkStart
+	}
+
+	if size-start > textMaxSize {
+		return "", fmt.Errorf("last %d lines exceed maximum allowed size of %d bytes", n, textMaxSize)
+	}
+
+	result := make([]byte, size-start)
+	read, err := reader.ReadAt(result, start)
+	if err != nil && err != io.EOF {
+		return "", fmt.Errorf("error reading text file: %w", err)
+	}
+	return string(result[:read]), nil
+}
+
 // ParseTextToString は後方互換性のための既存メソッド
 func ParseTextToString(textFilePath string) (string, error) {
 	parser := &TextParser{}
@@ -0,0 +1,107 @@
+package documentParser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTextParserCP932SpecialChars(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"NEC circled digit", []byte{0x87, 0x40}, "①"},
+		{"NEC parenthesized ideograph", []byte{0x87, 0x8a}, "㈱"},
+		{"IBM extension", []byte{0xfa, 0x40}, "ⅰ"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := &TextParser{Encoding: "cp932"}
+			got, err := parser.ParseFromBytes(tt.data)
+			if err != nil {
+				t.Fatalf("ParseFromBytes() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseFromBytes() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTextParserLinesWithEncoding(t *testing.T) {
+	// "a\nb\nc\n" をUTF-16LEで表したもの
+	utf16 := []byte{'a', 0, '\n', 0, 'b', 0, '\n', 0, 'c', 0, '\n', 0}
+	// "①\n㈱\n" をCP932で表したもの
+	cp932 := []byte{0x87, 0x40, '\n', 0x87, 0x8a, '\n'}
+
+	tests := []struct {
+		name    string
+		parser  *TextParser
+		data    []byte
+		want    string
+		wantErr bool
+	}{
+		{"utf-16 head", &TextParser{Encoding: "utf-16le", HeadLines: 1}, utf16, "", true},
+		{"utf-16 tail", &TextParser{Encoding: "utf-16le", TailLines: 1}, utf16, "", true},
+		{"utf-16 without lines", &TextParser{Encoding: "utf-16le"}, utf16, "a\nb\nc\n", false},
+		{"cp932 head", &TextParser{Encoding: "cp932", HeadLines: 1}, cp932, "①\n", false},
+		{"cp932 tail", &TextParser{Encoding: "cp932", TailLines: 1}, cp932, "㈱\n", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.parser.ParseFromReader(strings.NewReader(string(tt.data)), int64(len(tt.data)))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseFromReader() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseFromReader() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// benchmarkTextParser はsizeバイトのテキストをParseFromReaderで読み込むベンチマーク
+func benchmarkTextParser(b *testing.B, size int) {
+	data := strings.Repeat("key = value\n", size/12+1)[:size]
+	reader := strings.NewReader(data)
+	parser := &TextParser{}
+
+	b.ReportAllocs()
+	b.SetBytes(int64(size))
+	for b.Loop() {
+		if _, err := parser.ParseFromReader(reader, int64(size)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkTextParserSmall はプールしたバッファで読み込む小さなファイル（smallTextSize以下）
+func BenchmarkTextParserSmall(b *testing.B) {
+	benchmarkTextParser(b, 1024)
+}
+
+// BenchmarkTextParserLarge はプールを使わずに読み込むファイル（smallTextSizeより大きい）
+// 小さなファイルの場合と比べ、読み込み用のバッファの分だけアロケーションが多い
+func BenchmarkTextParserLarge(b *testing.B) {
+	benchmarkTextParser(b, smallTextSize+1)
+}
+
+func BenchmarkTextParserParseFromFile(b *testing.B) {
+	path := filepath.Join(b.TempDir(), "config.txt")
+	if err := os.WriteFile(path, []byte(strings.Repeat("key = value\n", 64)), 0o644); err != nil {
+		b.Fatal(err)
+	}
+	parser := &TextParser{}
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := parser.ParseFromFile(path); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
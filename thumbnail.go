@@ -0,0 +1,293 @@
+package documentParser
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"mime"
+	"path"
+	"strings"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// ErrNoThumbnail はドキュメントにサムネイル画像が埋め込まれていない場合に返すエラー
+var ErrNoThumbnail = errors.New("no thumbnail found")
+
+// ThumbnailParser は埋め込まれたサムネイル（プレビュー）画像を取り出せるパーサーのインターフェース
+type ThumbnailParser interface {
+	// ParseThumbnail はio.ReaderAtからサムネイル画像のデータとコンテンツタイプ（"image/jpeg" など）を返す
+	// サムネイルが埋め込まれていない場合はErrNoThumbnailを返す
+	ParseThumbnail(reader io.ReaderAt, size int64) ([]byte, string, error)
+}
+
+// thumbnailContentTypes はサムネイル画像の拡張子ごとのコンテンツタイプ
+// mimeパッケージの既定の対応表にないWindowsのメタファイルを含む
+var thumbnailContentTypes = map[string]string{
+	".jpeg": "image/jpeg",
+	".jpg":  "image/jpeg",
+	".png":  "image/png",
+	".gif":  "image/gif",
+	".wmf":  "image/x-wmf",
+	".emf":  "image/x-emf",
+}
+
+// ooxmlThumbnail はOOXMLのパッケージのサムネイル（通常はdocProps/thumbnail.jpeg）を返す
+// パッケージのリレーションシップ（_rels/.rels）のthumbnailを優先し、なければdocProps/thumbnail.*を探す
+func ooxmlThumbnail(reader io.ReaderAt, size int64) ([]byte, string, error) {
+	r, err := zip.NewReader(reader, size)
+	if err != nil {
+		return nil, "", fmt.Errorf("error reading OOXML package: %w", err)
+	}
+	files := zipFileMap(r)
+
+	var f *zip.File
+	for _, part := range relatedParts(files, "", "/metadata/thumbnail") {
+		if f = files[part]; f != nil {
+			break
+		}
+	}
+	if f == nil {
+		for _, candidate := range r.File {
+			if path.Dir(candidate.Name) == "docProps" && strings.HasPrefix(path.Base(candidate.Name), "thumbnail.") {
+				f = candidate
+				break
+			}
+		}
+	}
+	if f == nil {
+		return nil, "", ErrNoThumbnail
+	}
+
+	data, err := readZipFile(f)
+	if err != nil {
+		return nil, "", err
+	}
+
+	contentType := ""
+	if types, err := readContentTypes(files); err == nil {
+		contentType = types.lookup(f.Name)
+	}
+	if contentType == "" {
+		contentType = thumbnailContentType(f.Name)
+	}
+	return data, contentType, nil
+}
+
+// thumbnailContentType はファイル名の拡張子からコンテンツタイプを返す
+func thumbnailContentType(name string) string {
+	ext := strings.ToLower(path.Ext(name))
+	if contentType, ok := thumbnailContentTypes[ext]; ok {
+		return contentType
+	}
+	if contentType := mime.TypeByExtension(ext); contentType != "" {
+		return contentType
+	}
+	return "application/octet-stream"
+}
+
+// ParseThumbnail は1ページ目に埋め込まれたサムネイル画像（/Thumb）を返す
+// JPEG（DCTDecode）はそのまま、非圧縮・FlateDecodeの画素データはPNGに変換して返す
+func (p *PDFParser) ParseThumbnail(reader io.ReaderAt, size int64) (data []byte, contentType string, err error) {
+	defer recoverPDFPanic(&err)
+
+	pdfReader, err := p.newReader(reader, size)
+	if err != nil {
+		return nil, "", fmt.Errorf("error reading PDF: %w", err)
+	}
+	if pdfReader.NumPage() == 0 {
+		return nil, "", ErrNoThumbnail
+	}
+
+	thumb := pdfReader.Page(1).V.Key("Thumb")
+	if thumb.Kind() != pdf.Stream {
+		return nil, "", ErrNoThumbnail
+	}
+
+	switch filter := pdfStreamFilter(thumb); filter {
+	case "DCTDecode":
+		data, err := pdfRawJPEG(reader, size, thumb)
+		return data, "image/jpeg", err
+	case "", "FlateDecode":
+		data, err := pdfThumbnailPNG(thumb)
+		return data, "image/png", err
+	default:
+		return nil, "", fmt.Errorf("unsupported thumbnail filter %s", filter)
+	}
+}
+
+// pdfStreamFilter はストリームのフィルター名を返す（フィルターがない場合は空文字列）
+// 複数のフィルターが指定されている場合は "ASCII85Decode FlateDecode" のように空白で区切って返す
+func pdfStreamFilter(stream pdf.Value) string {
+	filter := stream.Key("Filter")
+	switch filter.Kind() {
+	case pdf.Name:
+		return filter.Name()
+	case pdf.Array:
+		names := make([]string, filter.Len())
+		for i := range names {
+			names[i] = filter.Index(i).Name()
+		}
+		return strings.Join(names, " ")
+	}
+	return ""
+}
+
+// pdfMaxThumbnailSize はPDFのサムネイルの幅・高さの上限（サムネイルは通常106x106程度の小さな画像）
+const pdfMaxThumbnailSize = 1024
+
+// pdfMaxThumbnailBytes はPDFのサムネイルのストリームのサイズの上限（上限の幅・高さのRGBの画素データの大きさ）
+const pdfMaxThumbnailBytes = pdfMaxThumbnailSize * pdfMaxThumbnailSize * 3
+
+// pdfThumbnailPNG はサムネイルの画素データ（8ビットのDeviceRGB・DeviceGray・RGBのIndexed）をPNGに変換する
+func pdfThumbnailPNG(thumb pdf.Value) ([]byte, error) {
+	width, height := int(thumb.Key("Width").Int64()), int(thumb.Key("Height").Int64())
+	if width <= 0 || height <= 0 || width > pdfMaxThumbnailSize || height > pdfMaxThumbnailSize {
+		return nil, fmt.Errorf("invalid thumbnail size %dx%d", width, height)
+	}
+	if bpc := thumb.Key("BitsPerComponent").Int64(); bpc != 8 {
+		return nil, fmt.Errorf("unsupported thumbnail bits per component %d", bpc)
+	}
+
+	colorSpace := thumb.Key("ColorSpace")
+	var palette []byte
+	components := 0
+	switch {
+	case colorSpace.Name() == "DeviceRGB":
+		components = 3
+	case colorSpace.Name() == "DeviceGray":
+		components = 1
+	case colorSpace.Kind() == pdf.Array && colorSpace.Index(0).Name() == "Indexed" && colorSpace.Index(1).Name() == "DeviceRGB":
+		components = 1
+		lookup := colorSpace.Index(3)
+		if lookup.Kind() == pdf.Stream {
+			rc := lookup.Reader()
+			palette, _ = io.ReadAll(io.LimitReader(rc, 256*3))
+			rc.Close()
+		} else {
+			palette = []byte(lookup.RawString())
+		}
+	default:
+		return nil, fmt.Errorf("unsupported thumbnail color space %v", colorSpace)
+	}
+
+	// 画素データの大きさの分を先に確保せず、ストリームの実際の長さが足りるかを確かめてから使う
+	expected := width * height * components
+	if pdfStreamFilter(thumb) == "" && thumb.Key("Length").Int64() < int64(expected) {
+		return nil, fmt.Errorf("thumbnail stream has %d bytes, want %d", thumb.Key("Length").Int64(), expected)
+	}
+	rc := thumb.Reader()
+	defer rc.Close()
+	samples, err := io.ReadAll(io.LimitReader(rc, int64(expected)))
+	if err != nil {
+		return nil, fmt.Errorf("error reading thumbnail: %w", err)
+	}
+	if len(samples) < expected {
+		return nil, fmt.Errorf("thumbnail stream has %d bytes, want %d", len(samples), expected)
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for i := 0; i < width*height; i++ {
+		var c color.RGBA
+		switch {
+		case palette != nil:
+			index := int(samples[i]) * 3
+			if index+2 < len(palette) {
+				c = color.RGBA{palette[index], palette[index+1], palette[index+2], 0xff}
+			}
+		case components == 3:
+			c = color.RGBA{samples[i*3], samples[i*3+1], samples[i*3+2], 0xff}
+		default:
+			c = color.RGBA{samples[i], samples[i], samples[i], 0xff}
+		}
+		img.SetRGBA(i%width, i/width, c)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("error encoding thumbnail: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// pdfRawJPEG はDCTDecodeのサムネイルのストリームの生のデータ（JPEG）をファイルから探して返す
+// PDFのライブラリはDCTDecodeを展開できず、ストリームの位置も公開していないため、
+// "stream" キーワードの直後から始まるJPEGのうち、長さと画像サイズがサムネイルと一致するものを探す
+// 暗号化されたPDFではストリームが暗号化されているため見つからない
+func pdfRawJPEG(reader io.ReaderAt, size int64, thumb pdf.Value) ([]byte, error) {
+	length := thumb.Key("Length").Int64()
+	width, height := int(thumb.Key("Width").Int64()), int(thumb.Key("Height").Int64())
+	if length <= 0 || length > size || length > pdfMaxThumbnailBytes {
+		return nil, fmt.Errorf("invalid thumbnail length %d", length)
+	}
+	if width <= 0 || height <= 0 || width > pdfMaxThumbnailSize || height > pdfMaxThumbnailSize {
+		return nil, fmt.Errorf("invalid thumbnail size %dx%d", width, height)
+	}
+
+	const chunkSize = 1 << 20
+	soi := []byte{0xff, 0xd8, 0xff}
+	buf := make([]byte, chunkSize+len(soi)-1)
+	for offset := int64(0); offset < size; offset += chunkSize {
+		n, err := reader.ReadAt(buf, offset)
+		if err != nil && err != io.EOF {
+			return nil, fmt.Errorf("error reading PDF: %w", err)
+		}
+		chunk := buf[:n]
+
+		for i := 0; i < min(len(chunk), chunkSize); {
+			j := bytes.Index(chunk[i:], soi)
+			if j < 0 || i+j >= chunkSize {
+				break
+			}
+			start := offset + int64(i+j)
+			i += j + 1
+
+			if !pdfFollowsStreamKeyword(reader, start) || start+length > size {
+				continue
+			}
+			data := make([]byte, length)
+			if _, err := reader.ReadAt(data, start); err != nil {
+				continue
+			}
+			config, err := jpeg.DecodeConfig(bytes.NewReader(data))
+			if err == nil && config.Width == width && config.Height == height {
+				return data, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("thumbnail stream not found in PDF")
+}
+
+// pdfFollowsStreamKeyword はoffsetの直前が "stream" キーワードと改行かどうかを返す
+func pdfFollowsStreamKeyword(reader io.ReaderAt, offset int64) bool {
+	const keyword = "stream"
+	start := max(offset-int64(len(keyword))-2, 0)
+	before := make([]byte, offset-start)
+	if _, err := reader.ReadAt(before, start); err != nil {
+		return false
+	}
+	before = bytes.TrimSuffix(before, []byte("\n"))
+	before = bytes.TrimSuffix(before, []byte("\r"))
+	return bytes.HasSuffix(before, []byte(keyword))
+}
+
+// ParseThumbnail はパッケージに埋め込まれたサムネイル画像（docProps/thumbnail.jpeg など）を返す
+func (p *DOCXParser) ParseThumbnail(reader io.ReaderAt, size int64) ([]byte, string, error) {
+	return ooxmlThumbnail(reader, size)
+}
+
+// ParseThumbnail はパッケージに埋め込まれたサムネイル画像（docProps/thumbnail.jpeg など）を返す
+func (p *PPTXParser) ParseThumbnail(reader io.ReaderAt, size int64) ([]byte, string, error) {
+	return ooxmlThumbnail(reader, size)
+}
+
+// ParseThumbnail はパッケージに埋め込まれたサムネイル画像（docProps/thumbnail.jpeg など）を返す
+func (p *ExcelParser) ParseThumbnail(reader io.ReaderAt, size int64) ([]byte, string, error) {
+	return ooxmlThumbnail(reader, size)
+}
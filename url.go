@@ -0,0 +1,103 @@
+package documentParser
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+)
+
+// defaultHTTPTimeout はParseFromURLのデフォルトのタイムアウト
+const defaultHTTPTimeout = 60 * time.Second
+
+// maxURLBodySize はParseFromURLで読み込むレスポンスボディの上限（512MB）
+const maxURLBodySize = 512 * 1024 * 1024
+
+// contentTypeExtensions はContent-Typeから推定する拡張子
+var contentTypeExtensions = map[string]string{
+	"application/pdf": ".pdf",
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document":   ".docx",
+	"application/vnd.openxmlformats-officedocument.presentationml.presentation": ".pptx",
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":         ".xlsx",
+	"application/vnd.ms-excel.sheet.macroenabled.12":                            ".xlsm",
+	"application/zip":           ".zip",
+	"application/x-tar":         ".tar",
+	"application/gzip":          ".gz",
+	"application/json":          ".json",
+	"application/x-ndjson":      ".ndjson",
+	"application/xhtml+xml":     ".xhtml",
+	"application/xml":           ".xml",
+	"text/html":                 ".html",
+	"text/csv":                  ".csv",
+	"text/tab-separated-values": ".tsv",
+	"text/markdown":             ".md",
+	"text/plain":                ".txt",
+	"text/vtt":                  ".vtt",
+	"text/xml":                  ".xml",
+}
+
+// SetHTTPClient はParseFromURLで使うHTTPクライアントを設定する
+// タイムアウトはクライアントのTimeoutで指定する。nilの場合はデフォルト（タイムアウト60秒）に戻す
+func (f *DocumentParserFactory) SetHTTPClient(client *http.Client) {
+	f.httpClient = client
+}
+
+// ParseFromURL はURLからファイルを取得してパースする
+// 拡張子はURLのパス、Content-Type、内容のマジックナンバーの順に判定する
+// ZIPやPDFはランダムアクセスが必要なため、レスポンスボディは全てメモリに読み込む
+func (f *DocumentParserFactory) ParseFromURL(rawURL string) (string, error) {
+	client := f.httpClient
+	if client == nil {
+		client = &http.Client{Timeout: defaultHTTPTimeout}
+	}
+
+	resp, err := client.Get(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to fetch %s: unexpected status %s", rawURL, resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxURLBodySize+1))
+	if err != nil {
+		return "", fmt.Errorf("failed to read response body: %w", err)
+	}
+	if len(data) > maxURLBodySize {
+		return "", fmt.Errorf("response body exceeds maximum allowed size of %d bytes", maxURLBodySize)
+	}
+
+	ext := f.urlExtension(resp, data)
+	if ext == "" {
+		return "", fmt.Errorf("could not determine file format for %s", rawURL)
+	}
+
+	return f.ParseFromBytes(ext, data)
+}
+
+// urlExtension はレスポンスから対応する拡張子を推定する
+func (f *DocumentParserFactory) urlExtension(resp *http.Response, data []byte) string {
+	// リダイレクト後のURLのパスを使う
+	if ext := normalizeExtension(getFileExtension(resp.Request.URL.Path)); f.parsers[ext] != nil {
+		return ext
+	}
+
+	if mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type")); err == nil {
+		if ext, ok := contentTypeExtensions[mediaType]; ok && f.parsers[ext] != nil {
+			// gzipで圧縮されたtarはContent-Typeでは区別できないため、内容から判定する
+			if ext == ".gz" && sniffExtension(bytes.NewReader(data), int64(len(data))) == ".tar.gz" {
+				return ".tar.gz"
+			}
+			return ext
+		}
+	}
+
+	if ext := sniffExtension(bytes.NewReader(data), int64(len(data))); f.parsers[ext] != nil {
+		return ext
+	}
+	return ""
+}
@@ -0,0 +1,317 @@
+package documentParser
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"mime/quotedprintable"
+	"strings"
+
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+// VCardParser は連絡先ファイル（vCard 2.1 / 3.0 / 4.0）のパーサー
+// 1ファイルに含まれる複数の連絡先を、空行で区切った "Name: 名前\nOrg: 組織" のブロックとして出力する
+// 出力するのは名前（FN、ない場合はN）・組織（ORG）・電話番号（TEL）・メールアドレス（EMAIL）・住所（ADR）
+type VCardParser struct {
+	TextParser
+}
+
+// SupportedExtensions はサポートする拡張子を返す
+func (p *VCardParser) SupportedExtensions() []string {
+	return []string{".vcf", ".vcard"}
+}
+
+// ParseFromFile はファイルパスからvCardをパース
+func (p *VCardParser) ParseFromFile(filePath string) (string, error) {
+	return parseFromFileCommon(p, filePath)
+}
+
+// ParseFromBytes はバイト配列からvCardをパース
+func (p *VCardParser) ParseFromBytes(data []byte) (string, error) {
+	text, err := p.bytesText(data)
+	if err != nil {
+		return "", err
+	}
+	return truncateOutput(renderVCards(text, p.MaxOutputBytes), p.MaxOutputBytes), nil
+}
+
+// ParseFromReader はio.ReaderAtからvCardをパース
+func (p *VCardParser) ParseFromReader(reader io.ReaderAt, size int64) (string, error) {
+	text, err := p.readerText(reader, size)
+	if err != nil {
+		return "", err
+	}
+	return truncateOutput(renderVCards(text, p.MaxOutputBytes), p.MaxOutputBytes), nil
+}
+
+// contentLine はvCard・iCalendarの1つのプロパティの行（"TEL;TYPE=work:+81-3-..." など）
+// vCard（RFC 6350）とiCalendar（RFC 5545）は同じ形式の行を使う
+type contentLine struct {
+	name   string
+	params map[string][]string
+	value  string
+}
+
+// vCardLabels はプロパティ名ごとの出力の見出し
+var vCardLabels = map[string]string{
+	"FN":    "Name",
+	"ORG":   "Org",
+	"TEL":   "Tel",
+	"EMAIL": "Email",
+	"ADR":   "Address",
+}
+
+// renderVCards はvCardのテキストを連絡先ごとのブロックに変換する
+// maxBytesが正の場合は、出力がmaxBytesを超えた時点で以降の連絡先を読まない
+func renderVCards(text string, maxBytes int) string {
+	var cards []string
+	var card []contentLine
+	inCard := false
+	size := 0
+lines:
+	for _, line := range unfoldContentLines(text) {
+		prop, ok := parseContentLine(line)
+		if !ok {
+			continue
+		}
+		switch {
+		case prop.name == "BEGIN" && strings.EqualFold(prop.value, "VCARD"):
+			inCard, card = true, nil
+		case prop.name == "END" && strings.EqualFold(prop.value, "VCARD"):
+			if block := renderVCard(card); block != "" {
+				cards = append(cards, block)
+				size += len(block) + 1
+			}
+			inCard = false
+			if maxBytes > 0 && size > maxBytes {
+				break lines
+			}
+		case inCard:
+			card = append(card, prop)
+		}
+	}
+	if len(cards) == 0 {
+		return ""
+	}
+	return strings.Join(cards, "\n") + "\n"
+}
+
+// renderVCard は1つの連絡先のプロパティを "見出し: 値" の行にする
+// FNがない場合はN（姓;名;ミドルネーム;敬称;接尾辞）から名前を組み立てる
+func renderVCard(props []contentLine) string {
+	var sb strings.Builder
+	hasName := false
+	for _, prop := range props {
+		if prop.name == "FN" && prop.value != "" {
+			hasName = true
+		}
+	}
+	for _, prop := range props {
+		if prop.name == "N" && !hasName {
+			parts := splitVCardValue(prop.value)
+			// 敬称・名・ミドルネーム・姓・接尾辞の順にする
+			var name []string
+			for _, i := range []int{3, 1, 2, 0, 4} {
+				if i < len(parts) && parts[i] != "" {
+					name = append(name, parts[i])
+				}
+			}
+			if len(name) > 0 {
+				sb.WriteString("Name: " + strings.Join(name, " ") + "\n")
+				hasName = true
+			}
+			continue
+		}
+
+		label, ok := vCardLabels[prop.name]
+		if !ok {
+			continue
+		}
+		var value string
+		switch prop.name {
+		case "ORG", "ADR":
+			value = joinNonEmpty(splitVCardValue(prop.value), ", ")
+		default:
+			value = unescapeContentValue(prop.value)
+		}
+		value = strings.Join(strings.Fields(value), " ")
+		if value == "" {
+			continue
+		}
+		if types := prop.types(); len(types) > 0 && prop.name != "FN" && prop.name != "ORG" {
+			value += " (" + strings.Join(types, ", ") + ")"
+		}
+		sb.WriteString(label + ": " + value + "\n")
+	}
+	return sb.String()
+}
+
+// types はTYPEパラメーター（vCard 2.1では値のないパラメーター）の種類を小文字で返す
+// 優先を示す "pref" と電話の既定の種類の "voice" は除く
+func (prop contentLine) types() []string {
+	var types []string
+	for _, t := range append(prop.params["TYPE"], prop.params[""]...) {
+		for _, t := range strings.Split(t, ",") {
+			t = strings.ToLower(strings.Trim(t, `"`))
+			if t != "" && t != "pref" && t != "voice" && t != "internet" {
+				types = append(types, t)
+			}
+		}
+	}
+	return types
+}
+
+// unfoldContentLines はvCard・iCalendarのテキストを論理行に分割する
+// 空白で始まる行は前の行の続き（折り返し）として連結し、quoted-printableの値の末尾の "=" も次の行と連結する
+func unfoldContentLines(text string) []string {
+	text = strings.TrimPrefix(text, "\ufeff")
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+
+	var lines []string
+	softBreak := false
+	for _, line := range strings.Split(text, "\n") {
+		switch {
+		case softBreak && len(lines) > 0:
+			lines[len(lines)-1] += line
+		case (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0:
+			lines[len(lines)-1] += line[1:]
+		case strings.TrimSpace(line) == "":
+			softBreak = false
+			continue
+		default:
+			lines = append(lines, line)
+		}
+		last := lines[len(lines)-1]
+		softBreak = strings.HasSuffix(last, "=") && strings.Contains(strings.ToUpper(contentLineHeader(last)), "QUOTED-PRINTABLE")
+		if softBreak {
+			lines[len(lines)-1] = strings.TrimSuffix(last, "=")
+		}
+	}
+	return lines
+}
+
+// contentLineHeader はプロパティの行のうち、値の前の "名前;パラメーター" の部分を返す
+func contentLineHeader(line string) string {
+	inQuote := false
+	for i, r := range line {
+		switch r {
+		case '"':
+			inQuote = !inQuote
+		case ':':
+			if !inQuote {
+				return line[:i]
+			}
+		}
+	}
+	return line
+}
+
+// parseContentLine は論理行をプロパティ名・パラメーター・値に分け、ENCODINGとCHARSETに従って値をデコードする
+// "item1.EMAIL" のようなグループ名は除く
+func parseContentLine(line string) (contentLine, bool) {
+	header := contentLineHeader(line)
+	if len(header) == len(line) {
+		return contentLine{}, false
+	}
+	value := line[len(header)+1:]
+
+	fields := strings.Split(header, ";")
+	name := strings.ToUpper(strings.TrimSpace(fields[0]))
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+
+	params := make(map[string][]string)
+	for _, field := range fields[1:] {
+		key, v, found := strings.Cut(field, "=")
+		if !found {
+			// vCard 2.1の値のないパラメーター（"WORK"、"QUOTED-PRINTABLE" など）
+			switch upper := strings.ToUpper(key); upper {
+			case "QUOTED-PRINTABLE", "BASE64", "B":
+				params["ENCODING"] = append(params["ENCODING"], upper)
+			default:
+				params[""] = append(params[""], key)
+			}
+			continue
+		}
+		key = strings.ToUpper(key)
+		params[key] = append(params[key], v)
+	}
+
+	prop := contentLine{name: name, params: params, value: value}
+	prop.value = prop.decode(value)
+	return prop, true
+}
+
+// decode はENCODING（quoted-printable / base64）を展開し、CHARSETがUTF-8以外の場合はUTF-8に変換する
+func (prop contentLine) decode(value string) string {
+	var data []byte
+	encoding := ""
+	if len(prop.params["ENCODING"]) > 0 {
+		encoding = strings.ToUpper(prop.params["ENCODING"][0])
+	}
+	switch encoding {
+	case "QUOTED-PRINTABLE":
+		decoded, err := io.ReadAll(quotedprintable.NewReader(strings.NewReader(value)))
+		if err != nil {
+			return value
+		}
+		data = decoded
+	case "BASE64", "B":
+		decoded, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(value), ""))
+		if err != nil {
+			return value
+		}
+		data = decoded
+	default:
+		return value
+	}
+
+	if charset := prop.params["CHARSET"]; len(charset) > 0 && !strings.EqualFold(charset[0], "UTF-8") {
+		if enc, err := htmlindex.Get(charset[0]); err == nil {
+			if converted, err := io.ReadAll(enc.NewDecoder().Reader(bytes.NewReader(data))); err == nil {
+				data = converted
+			}
+		}
+	}
+	return string(data)
+}
+
+// splitVCardValue は構造化された値（N、ORG、ADR）をエスケープされていない ";" で分割し、各要素のエスケープを戻す
+func splitVCardValue(value string) []string {
+	var parts []string
+	var current strings.Builder
+	for i := 0; i < len(value); i++ {
+		switch {
+		case value[i] == '\\' && i+1 < len(value):
+			current.WriteString(value[i : i+2])
+			i++
+		case value[i] == ';':
+			parts = append(parts, unescapeContentValue(current.String()))
+			current.Reset()
+		default:
+			current.WriteByte(value[i])
+		}
+	}
+	return append(parts, unescapeContentValue(current.String()))
+}
+
+// contentValueUnescaper はvCard 3.0 / 4.0・iCalendarの値のエスケープ（\n、\,、\;、\\）を戻す
+var contentValueUnescaper = strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`)
+
+func unescapeContentValue(value string) string {
+	return contentValueUnescaper.Replace(value)
+}
+
+// joinNonEmpty は空でない要素を前後の空白を除いてsepで結合する
+func joinNonEmpty(parts []string, sep string) string {
+	var nonEmpty []string
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			nonEmpty = append(nonEmpty, part)
+		}
+	}
+	return strings.Join(nonEmpty, sep)
+}
@@ -0,0 +1,113 @@
+package documentParser
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+// XMLParser はXMLファイルのパーサー
+// 要素の木をたどり、テキストを持つ要素を "root/item/name: 値" の行に展開する
+// 名前空間の接頭辞は除き、コメント・処理命令・DOCTYPEは出力しない
+type XMLParser struct {
+	BaseParser
+
+	// IncludeAttributes が true の場合、属性を "root/item/@id: 値" の行として出力する（名前空間の宣言は除く）
+	IncludeAttributes bool
+
+	// PathPrefix は各行のパスの先頭に付ける文字列（"catalog.xml:" など、空の場合は付けない）
+	PathPrefix string
+
+	// PathSeparator はパスの要素名の区切り文字列（空の場合は "/"）
+	PathSeparator string
+}
+
+// SupportedExtensions はサポートする拡張子を返す
+func (p *XMLParser) SupportedExtensions() []string {
+	return []string{".xml"}
+}
+
+// ParseFromFile はファイルパスからXMLをパース
+func (p *XMLParser) ParseFromFile(filePath string) (string, error) {
+	return parseFromFileCommon(p, filePath)
+}
+
+// ParseFromBytes はバイト配列からXMLをパース
+func (p *XMLParser) ParseFromBytes(data []byte) (string, error) {
+	return parseFromBytesCommon(p, data)
+}
+
+// ParseFromReader はio.ReaderAtからXMLをパースし、"パス: 値" の行を文書内の順番で返す
+// 値の連続する空白（改行を含む）は1つのスペースにまとめる
+func (p *XMLParser) ParseFromReader(reader io.ReaderAt, size int64) (string, error) {
+	decoder := xml.NewDecoder(io.NewSectionReader(reader, 0, size))
+	// XML宣言のencoding（Shift_JIS など）に対応する
+	decoder.CharsetReader = func(label string, input io.Reader) (io.Reader, error) {
+		enc, err := htmlindex.Get(label)
+		if err != nil {
+			return nil, fmt.Errorf("unsupported encoding %s: %w", label, err)
+		}
+		return enc.NewDecoder().Reader(input), nil
+	}
+
+	separator := p.PathSeparator
+	if separator == "" {
+		separator = "/"
+	}
+
+	var sb strings.Builder
+	var path []string
+	var text strings.Builder
+	// flush は要素の直下のテキストを、子要素やタグの終わりで区切って1行にする
+	flush := func() {
+		if value := strings.Join(strings.Fields(text.String()), " "); value != "" && len(path) > 0 {
+			fmt.Fprintf(&sb, "%s%s: %s\n", p.PathPrefix, strings.Join(path, separator), value)
+		}
+		text.Reset()
+	}
+
+	for {
+		t, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("error parsing XML: %w", err)
+		}
+
+		switch se := t.(type) {
+		case xml.StartElement:
+			flush()
+			path = append(path, se.Name.Local)
+			if p.IncludeAttributes {
+				p.writeAttributes(&sb, se, strings.Join(path, separator), separator)
+			}
+		case xml.EndElement:
+			flush()
+			if len(path) > 0 {
+				path = path[:len(path)-1]
+			}
+		case xml.CharData:
+			text.Write(se)
+		}
+
+		if p.outputLimitReached(sb.Len()) {
+			break
+		}
+	}
+	return truncateOutput(sb.String(), p.MaxOutputBytes), nil
+}
+
+// writeAttributes は要素の属性を "パス/@名前: 値" の行として書き込む
+func (p *XMLParser) writeAttributes(sb *strings.Builder, se xml.StartElement, path, separator string) {
+	for _, attr := range se.Attr {
+		if attr.Name.Space == "xmlns" || attr.Name.Local == "xmlns" {
+			continue
+		}
+		value := strings.Join(strings.Fields(attr.Value), " ")
+		fmt.Fprintf(sb, "%s%s%s@%s: %s\n", p.PathPrefix, path, separator, attr.Name.Local, value)
+	}
+}